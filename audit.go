@@ -0,0 +1,13 @@
+package doorman
+
+import "github.com/equinix/doorman/metrics"
+
+const (
+	auditClientOwnerMismatch = "client_owner_mismatch"
+)
+
+// audit records a security relevant event, logged with an audit field so it can be picked out of the logs.
+func audit(event string, fields ...interface{}) {
+	logger.With(append([]interface{}{"audit", event}, fields...)...).Info("audit event")
+	metrics.AuditEventTotal.WithLabelValues(event).Inc()
+}
@@ -0,0 +1,169 @@
+package doorman
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	authEquinix = "equinix"
+	authLocal   = "local"
+	authLDAP    = "ldap"
+	authOIDC    = "oidc"
+
+	defaultAuthTimeout     = time.Minute
+	defaultOIDCAuthTimeout = 5 * time.Minute // users log in in a browser
+
+	// authDeferred is the exit status of an auth-user-pass-verify script that decides later, via auth_control_file
+	authDeferred = 2
+)
+
+var errClientOwnerMismatch = errors.New("client certificate was issued to another user")
+
+// isAuthBackend reports whether backend is one of the DOORMAN_AUTH backends.
+func isAuthBackend(backend string) bool {
+	switch backend {
+	case authEquinix, authLocal, authLDAP, authOIDC:
+		return true
+	}
+	return false
+}
+
+// Authenticator checks the credentials vpn clients log in with.
+type Authenticator interface {
+	// Authenticate returns who the credentials belong to, otp is the one-time code split off the password.
+	Authenticate(ctx context.Context, login, password, otp string) (*identity, error)
+}
+
+// webAuthenticator is an Authenticator whose users log in out of band, e.g. in a browser, rather than with the
+// credentials they connect with. Their profiles have no static-challenge and their logins last for the whole session.
+type webAuthenticator interface {
+	Authenticator
+	// webLogin marks the authenticator, it does nothing.
+	webLogin()
+}
+
+// identity is an authenticated user.
+type identity struct {
+	user    string   // recorded as the connection's username
+	id      string   // stable id of the user where the backend has one, e.g. the equinix user uuid
+	backend string   // DOORMAN_AUTH backend the user logged in with, empty for service accounts, break glass and sessions
+	token   string   // equinix api token the user's subnets are fetched with, empty for users of other backends
+	routes  []string // networks users without an api token may reach
+	// projects the subnets are fetched from, all projects the token can see if empty
+	projects []string
+}
+
+// qualifiedID is the user's id, or its name if the backend has no ids, prefixed with the backend it logged in with,
+// e.g. "equinix:<uuid>" or "ldap:alice". It is empty for logins that are not users of a backend.
+func (id *identity) qualifiedID() string {
+	if id.backend == "" {
+		return ""
+	}
+	if id.id != "" {
+		return id.backend + ":" + strings.ToLower(id.id)
+	}
+	return id.backend + ":" + strings.ToLower(id.user)
+}
+
+// owns reports whether owner, who a client certificate was issued to, is the user.
+func (id *identity) owns(owner string) bool {
+	return (id.id != "" && owner == id.id) || strings.EqualFold(owner, id.user)
+}
+
+// isCredentialsFailure reports whether err means the credentials were wrong, rather than that a backend failed.
+func isCredentialsFailure(err error) bool {
+	switch errors.Cause(err) {
+	case errInvalidCredentials, errLoginDenied, errInvalidSessionToken, errClientOwnerMismatch:
+		return true
+	}
+	return false
+}
+
+// authStatus maps why a client was not authenticated to a grpc status, so callers can tell wrong credentials from
+// an unavailable backend. Errors of unknown cause are returned as is.
+func authStatus(err error) error {
+	var code codes.Code
+	switch errors.Cause(err) {
+	case errInvalidCredentials, errLoginDenied, errInvalidSessionToken:
+		code = codes.Unauthenticated
+	case errClientOwnerMismatch:
+		code = codes.PermissionDenied
+	case errTwoFactorNotEnabled:
+		code = codes.FailedPrecondition
+	case errLockedOut, errAPIRateLimited:
+		code = codes.ResourceExhausted
+	case errAPIUnavailable:
+		code = codes.Unavailable
+	case errAPIRejected:
+		code = codes.Internal
+	case context.DeadlineExceeded:
+		code = codes.DeadlineExceeded
+	case context.Canceled:
+		code = codes.Canceled
+	default:
+		return err
+	}
+	return status.Error(code, err.Error())
+}
+
+// pendingAuthFunc tells the client where to complete its login out of band, e.g. in a browser, and how long it has.
+type pendingAuthFunc func(url string, timeout time.Duration) error
+
+type pendingAuthKey struct{}
+
+// withPendingAuth returns a context authenticators that log users in out of band can reach the client with.
+func withPendingAuth(ctx context.Context, pending pendingAuthFunc) context.Context {
+	return context.WithValue(ctx, pendingAuthKey{}, pending)
+}
+
+// pendingAuth returns the function to reach the client with, nil if the client can't be reached.
+func pendingAuth(ctx context.Context) pendingAuthFunc {
+	pending, _ := ctx.Value(pendingAuthKey{}).(pendingAuthFunc)
+	return pending
+}
+
+// readGroupRoutes reads a JSON file mapping groups to the CIDRs their members may reach.
+func readGroupRoutes(file string, canonical func(string) (string, error)) (map[string][]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "open group routes")
+	}
+	defer f.Close()
+
+	var groups map[string][]string
+	if err := json.NewDecoder(f).Decode(&groups); err != nil {
+		return nil, errors.Wrapf(err, "parsing %s", file)
+	}
+	return parseGroupRoutes(groups, canonical)
+}
+
+// parseGroupRoutes validates the routes of each group and keys them by the canonical form of the group's name.
+func parseGroupRoutes(groups map[string][]string, canonical func(string) (string, error)) (map[string][]string, error) {
+	if len(groups) == 0 {
+		return nil, errors.New("no groups mapped to routes")
+	}
+	parsed := make(map[string][]string, len(groups))
+	for group, routes := range groups {
+		key, err := canonical(group)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing group %s", group)
+		}
+		for _, route := range routes {
+			_, network, err := net.ParseCIDR(route)
+			if err != nil {
+				return nil, errors.Wrapf(err, "parsing route %s of group %s", route, group)
+			}
+			parsed[key] = append(parsed[key], network.String())
+		}
+	}
+	return parsed, nil
+}
@@ -0,0 +1,231 @@
+package doorman
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/equinix/doorman/metrics"
+	pb "github.com/equinix/doorman/protobuf"
+	"github.com/pkg/errors"
+)
+
+const (
+	breakGlassPrefix       = "dbg1."
+	breakGlassIDLength     = 8
+	breakGlassSecretLength = 32
+	breakGlassMaxActive    = 5 // emergency credentials are meant to be few
+	breakGlassMaxLifetime  = 7 * 24 * time.Hour
+
+	auditBreakGlassCreated = "break_glass_created"
+	auditBreakGlassUsed    = "break_glass_used"
+	auditBreakGlassRefused = "break_glass_refused"
+	auditBreakGlassRevoked = "break_glass_revoked"
+)
+
+// breakGlass checks emergency credentials, which let on-call staff in when the authentication backend is unavailable,
+// e.g. when the Equinix Metal API is down. They are minted by admins, verified by doorman alone and are only valid
+// with one client certificate, for a limited time or number of uses, reaching a fixed set of routes. Every use is
+// an audit event.
+type breakGlass struct {
+	store *stateStore
+	now   func() time.Time
+
+	// mu serializes uses, so a credential can't be used more often than it allows
+	mu sync.Mutex
+}
+
+func newBreakGlass(store *stateStore) *breakGlass {
+	return &breakGlass{store: store, now: time.Now}
+}
+
+// isBreakGlass reports whether the password a client sent is a break glass credential.
+func isBreakGlass(password string) bool {
+	return strings.HasPrefix(password, breakGlassPrefix)
+}
+
+// Authenticate checks the credential and uses it up.
+func (b *breakGlass) Authenticate(client, credential string) (*identity, error) {
+	parts := strings.SplitN(strings.TrimPrefix(credential, breakGlassPrefix), ".", 2)
+	if !isBreakGlass(credential) || len(parts) != 2 {
+		return nil, b.fail(client, "", "malformed credential")
+	}
+	id, secret := parts[0], parts[1]
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bg, err := b.store.breakGlass(id)
+	if err != nil {
+		return nil, err
+	}
+	if bg == nil {
+		return nil, b.fail(client, id, "unknown credential")
+	}
+	hash := sha256.Sum256([]byte(secret))
+	if subtle.ConstantTimeCompare(hash[:], bg.SecretHash) != 1 {
+		return nil, b.fail(client, id, "wrong secret")
+	}
+	if bg.Client != client {
+		return nil, b.fail(client, id, "credential belongs to client "+bg.Client)
+	}
+	if !b.now().Before(time.Unix(bg.Expires, 0)) {
+		return nil, b.fail(client, id, "credential expired")
+	}
+
+	limited := bg.UsesLeft != 0
+	bg.Used++
+	if limited {
+		bg.UsesLeft--
+	}
+	if limited && bg.UsesLeft == 0 {
+		err = b.store.deleteBreakGlass(id) // used up
+	} else {
+		err = b.store.putBreakGlass(bg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	audit(auditBreakGlassUsed, "id", id, "client", client, "routes", bg.Routes, "used", bg.Used, "uses_left", bg.UsesLeft, "reason", bg.Reason)
+	return &identity{user: "break-glass:" + id, routes: bg.Routes}, nil
+}
+
+// fail records the refused credential, the client is only told its credentials are invalid.
+func (b *breakGlass) fail(client, id, reason string) error {
+	audit(auditBreakGlassRefused, "id", id, "client", client, "reason", reason)
+	metrics.AuthenticationFailureTotalCount.Inc()
+	return errInvalidCredentials
+}
+
+// create mints a credential for the client and returns it, it can be used uses times, any number of times if 0,
+// until lifetime passed.
+func (b *breakGlass) create(client string, routes []string, lifetime time.Duration, uses int32, reason string) (*pb.BreakGlass, string, error) {
+	if client == "" {
+		return nil, "", errors.New("no client supplied")
+	}
+	if len(routes) == 0 {
+		return nil, "", errors.New("no routes supplied")
+	}
+	if lifetime <= 0 || lifetime > breakGlassMaxLifetime {
+		return nil, "", errors.Errorf("lifetime needs to be positive and at most %s", breakGlassMaxLifetime)
+	}
+	if uses < 0 {
+		return nil, "", errors.New("uses can't be negative")
+	}
+	if reason == "" {
+		return nil, "", errors.New("no reason supplied")
+	}
+	networks := make([]string, 0, len(routes))
+	for _, route := range routes {
+		_, network, err := net.ParseCIDR(route)
+		if err != nil {
+			return nil, "", errors.Wrapf(err, "parsing route %s", route)
+		}
+		networks = append(networks, network.String())
+	}
+
+	id := make([]byte, breakGlassIDLength)
+	if _, err := rand.Read(id); err != nil {
+		return nil, "", errors.Wrap(err, "generate break glass id")
+	}
+	secret := make([]byte, breakGlassSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", errors.Wrap(err, "generate break glass secret")
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(secret)
+	hash := sha256.Sum256([]byte(encoded))
+
+	now := b.now()
+	bg := &pb.BreakGlass{
+		Id:         hex.EncodeToString(id),
+		Client:     client,
+		Routes:     networks,
+		SecretHash: hash[:],
+		Created:    now.Unix(),
+		Expires:    now.Add(lifetime).Unix(),
+		UsesLeft:   uses,
+		Reason:     reason,
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	active, err := b.active()
+	if err != nil {
+		return nil, "", err
+	}
+	if len(active) >= breakGlassMaxActive {
+		return nil, "", errors.Errorf("there are already %d break glass credentials, revoke one first", len(active))
+	}
+	if err := b.store.putBreakGlass(bg); err != nil {
+		return nil, "", err
+	}
+
+	audit(auditBreakGlassCreated, "id", bg.Id, "client", client, "routes", bg.Routes, "expires", bg.Expires, "uses", uses, "reason", reason)
+	return bg, breakGlassPrefix + bg.Id + "." + encoded, nil
+}
+
+func (b *breakGlass) revoke(id string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bg, err := b.store.breakGlass(id)
+	if err != nil {
+		return err
+	}
+	if bg == nil {
+		return errors.Errorf("break glass credential %s does not exist", id)
+	}
+	if err := b.store.deleteBreakGlass(id); err != nil {
+		return err
+	}
+
+	audit(auditBreakGlassRevoked, "id", id, "client", bg.Client)
+	return nil
+}
+
+// credentials returns the credentials that have not expired, sorted by expiry, without their secret hashes.
+func (b *breakGlass) credentials() ([]*pb.BreakGlass, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	active, err := b.active()
+	if err != nil {
+		return nil, err
+	}
+	for _, bg := range active {
+		bg.SecretHash = nil
+	}
+	sort.Slice(active, func(i, j int) bool {
+		return active[i].Expires < active[j].Expires
+	})
+	return active, nil
+}
+
+// active returns the credentials that have not expired and deletes the others. The caller needs to hold mu.
+func (b *breakGlass) active() ([]*pb.BreakGlass, error) {
+	all, err := b.store.breakGlassCredentials()
+	if err != nil {
+		return nil, err
+	}
+	now := b.now()
+	var active []*pb.BreakGlass
+	for _, bg := range all {
+		if now.Before(time.Unix(bg.Expires, 0)) {
+			active = append(active, bg)
+			continue
+		}
+		if err := b.store.deleteBreakGlass(bg.Id); err != nil {
+			return nil, err
+		}
+	}
+	return active, nil
+}
@@ -0,0 +1,95 @@
+package doorman
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/packethost/pkg/log"
+)
+
+func TestBreakGlass(t *testing.T) {
+	logger = log.Test(t, "doorman")
+
+	store := newTestStateStore(t)
+
+	now := time.Unix(1600000000, 0)
+	b := newBreakGlass(store)
+	b.now = func() time.Time { return now }
+
+	if _, _, err := b.create("oncall", []string{"10.88.111.0/25"}, 8*24*time.Hour, 1, "api outage"); err == nil {
+		t.Fatal("expected a lifetime over the maximum to be refused")
+	}
+	if _, _, err := b.create("oncall", []string{"10.88.111.0/25"}, time.Hour, 1, ""); err == nil {
+		t.Fatal("expected creating a credential without a reason to fail")
+	}
+	once, credential, err := b.create("oncall", []string{"10.88.111.1/25"}, time.Hour, 1, "api outage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isBreakGlass(credential) {
+		t.Fatalf("expected %s to be told apart from passwords", credential)
+	}
+
+	for _, test := range []struct{ client, credential string }{
+		{"mallory", credential},
+		{"oncall", credential + "x"},
+		{"oncall", breakGlassPrefix + once.Id},
+	} {
+		if _, err := b.Authenticate(test.client, test.credential); err != errInvalidCredentials {
+			t.Fatalf("%s %s: expected invalid credentials, got %v", test.client, test.credential, err)
+		}
+	}
+	id, err := b.Authenticate("oncall", credential)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &identity{user: "break-glass:" + once.Id, routes: []string{"10.88.111.0/25"}}
+	if !reflect.DeepEqual(id, want) {
+		t.Fatalf("expected %+v, got %+v", want, id)
+	}
+	if _, err := b.Authenticate("oncall", credential); err != errInvalidCredentials {
+		t.Fatalf("expected a one-time credential to be used up, got %v", err)
+	}
+
+	// credentials without a use limit can be used until they expire
+	_, credential, err = b.create("oncall", []string{"10.88.111.0/25"}, time.Hour, 0, "api outage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := b.Authenticate("oncall", credential); err != nil {
+			t.Fatal(err)
+		}
+	}
+	credentials, err := b.credentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(credentials) != 1 || credentials[0].Used != 3 || credentials[0].SecretHash != nil {
+		t.Fatalf("unexpected credentials %v", credentials)
+	}
+	now = now.Add(time.Hour)
+	if _, err := b.Authenticate("oncall", credential); err != errInvalidCredentials {
+		t.Fatalf("expected an expired credential to be refused, got %v", err)
+	}
+
+	for i := 0; i < breakGlassMaxActive; i++ {
+		if _, _, err := b.create("oncall", []string{"10.88.111.0/25"}, time.Hour, 1, "api outage"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, err := b.create("oncall", []string{"10.88.111.0/25"}, time.Hour, 1, "api outage"); err == nil {
+		t.Fatal("expected creating more than the maximum credentials to fail")
+	}
+	credentials, err = b.credentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.revoke(credentials[0].Id); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.revoke(credentials[0].Id); err == nil {
+		t.Fatal("expected revoking a missing credential to fail")
+	}
+}
@@ -0,0 +1,232 @@
+package doorman
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/equinix/doorman/metrics"
+	retryable "github.com/hashicorp/go-retryablehttp"
+	"github.com/pkg/errors"
+)
+
+const (
+	equinixAPITimeout  = 30 * time.Second // of a single request, retries included
+	equinixAPIRetryMax = 3
+)
+
+var (
+	errTwoFactorNotEnabled = errors.New("2-factor not enabled")
+	errAPIRateLimited      = errors.New("equinix metal api rate limit exceeded, try again later")
+	errAPIUnavailable      = errors.New("equinix metal api unavailable")
+	errAPIRejected         = errors.New("equinix metal api rejected the request")
+)
+
+type AuthToken struct {
+	ID    string `json:"id"`
+	Token string `json:"token"`
+}
+
+// equinixAPI is the part of the Equinix Metal API users are logged in with. Credentials are sent in the request
+// body, requests honor the caller's context and share one pooled http client. Errors are errInvalidCredentials,
+// errTwoFactorNotEnabled, errAPIRateLimited, errAPIUnavailable and errAPIRejected, or the context's error.
+type equinixAPI struct {
+	base          *url.URL
+	consumerToken string
+	client        *retryable.Client
+	// sessions creates sessions, which is not idempotent, so it only retries requests that were never sent
+	sessions *retryable.Client
+}
+
+func newEquinixAPI(base *url.URL, consumerToken string) *equinixAPI {
+	client := newEquinixAPIClient()
+	sessions := newEquinixAPIClient()
+	sessions.HTTPClient = client.HTTPClient
+	sessions.CheckRetry = retryUnsent
+	return &equinixAPI{base: base, consumerToken: consumerToken, client: client, sessions: sessions}
+}
+
+func newEquinixAPIClient() *retryable.Client {
+	client := retryable.NewClient()
+	client.HTTPClient.Timeout = equinixAPITimeout
+	client.RetryMax = equinixAPIRetryMax
+	client.RetryWaitMin = 250 * time.Millisecond
+	client.RetryWaitMax = 2 * time.Second
+	// failed requests are logged by do, retryable logs every request otherwise
+	client.Logger = nil
+	// the last response is mapped to an error below, instead of retryable's "giving up" error
+	client.ErrorHandler = retryable.PassthroughErrorHandler
+	return client
+}
+
+// retryUnsent retries requests that failed to connect, the api can't have seen them.
+func retryUnsent(ctx context.Context, response *http.Response, err error) (bool, error) {
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+	var opErr *net.OpError
+	return err != nil && errors.As(err, &opErr) && opErr.Op == "dial", nil
+}
+
+// checkTwoFactor returns errTwoFactorNotEnabled if the user can log in without a twofactor token.
+// Wrong credentials are only reported by createSession.
+func (a *equinixAPI) checkTwoFactor(ctx context.Context, login, password string) error {
+	response, err := a.postSession(ctx, login, password, "")
+	if err == errInvalidCredentials {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	return errTwoFactorNotEnabled
+}
+
+// createSession logs the user in and returns the token of the new api session.
+func (a *equinixAPI) createSession(ctx context.Context, login, password, otp string) (string, error) {
+	response, err := a.postSession(ctx, login, password, otp)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	authToken := &AuthToken{}
+	err = json.NewDecoder(response.Body).Decode(authToken)
+	if err == nil && authToken.Token == "" {
+		err = errors.New("empty token")
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "fetching API authentication token")
+	}
+	return authToken.Token, nil
+}
+
+func (a *equinixAPI) postSession(ctx context.Context, login, password, otp string) (*http.Response, error) {
+	body, err := json.Marshal(map[string]string{"login": login, "password": password})
+	if err != nil {
+		return nil, errors.Wrap(err, "encode login")
+	}
+	request, err := a.request(ctx, http.MethodPost, "sessions", body)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if otp != "" {
+		request.Header.Set("X-OTP-Token", otp)
+	}
+	return a.do(ctx, a.sessions, request)
+}
+
+// currentUser returns the id of the user the api token belongs to.
+func (a *equinixAPI) currentUser(ctx context.Context, token string) (string, error) {
+	request, err := a.request(ctx, http.MethodGet, "user", nil)
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("X-Auth-Token", token)
+	response, err := a.do(ctx, a.client, request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	user := &struct {
+		ID string `json:"id"`
+	}{}
+	if err := json.NewDecoder(response.Body).Decode(user); err != nil {
+		return "", errors.Wrap(err, "decode API user")
+	}
+	if user.ID == "" {
+		return "", errors.New("API user has no id")
+	}
+	return user.ID, nil
+}
+
+// request returns a request for the path relative to the api's base url, which ends in a slash.
+func (a *equinixAPI) request(ctx context.Context, method, path string, body []byte) (*retryable.Request, error) {
+	endpoint := a.base.ResolveReference(&url.URL{Path: path})
+	request, err := retryable.NewRequest(method, endpoint.String(), body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed setting up http request")
+	}
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("X-Consumer-Token", a.consumerToken)
+	return request.WithContext(ctx), nil
+}
+
+// do sends the request with the client, mapping failures to typed errors. The response is only returned if it is
+// successful. Only 401s and responses asking for an otp mean the credentials are wrong, other client errors are
+// doorman's fault and reported as errAPIRejected.
+func (a *equinixAPI) do(ctx context.Context, client *retryable.Client, request *retryable.Request) (*http.Response, error) {
+	response, err := client.Do(request)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		logger.With("error", err, "url", request.URL.String()).Info("equinix metal api request failed")
+		return nil, errAPIUnavailable
+	}
+	if response.StatusCode/100 == 2 {
+		return response, nil
+	}
+	defer response.Body.Close()
+
+	switch {
+	case response.StatusCode == http.StatusUnauthorized:
+		return nil, errInvalidCredentials
+	case response.StatusCode == http.StatusTooManyRequests:
+		return nil, errAPIRateLimited
+	case response.StatusCode >= 500:
+		logger.With("response", response.StatusCode, "url", request.URL.String()).Info("equinix metal api unavailable")
+		return nil, errAPIUnavailable
+	}
+
+	apiErrors := &struct {
+		Errors []string `json:"errors"`
+	}{}
+	json.NewDecoder(io.LimitReader(response.Body, 1<<16)).Decode(apiErrors)
+	for _, message := range apiErrors.Errors {
+		if strings.Contains(strings.ToLower(message), "otp") {
+			return nil, errInvalidCredentials
+		}
+	}
+	logger.With("response", response.StatusCode, "errors", apiErrors.Errors, "url", request.URL.String()).Info("equinix metal api rejected request")
+	return nil, errors.Wrapf(errAPIRejected, "status %d", response.StatusCode)
+}
+
+// equinixAuthenticator logs users in to the Equinix Metal API, users need to have 2fa enabled.
+type equinixAuthenticator struct {
+	api *equinixAPI
+}
+
+func (a *equinixAuthenticator) Authenticate(ctx context.Context, login, password, otp string) (*identity, error) {
+	logger.Info("attempting to validate user token.")
+	if err := a.api.checkTwoFactor(ctx, login, password); err != nil {
+		return nil, a.fail(err)
+	}
+	token, err := a.api.createSession(ctx, login, password, otp)
+	if err != nil {
+		return nil, a.fail(err)
+	}
+	user, err := a.api.currentUser(ctx, token)
+	if err != nil {
+		err = errors.WithMessage(err, "fetching API user")
+		logger.With("error", err).Info()
+		return nil, err
+	}
+	return &identity{user: login, id: user, backend: authEquinix, token: token}, nil
+}
+
+// fail logs why the user failed to log in, and counts it if it was the user's fault.
+func (a *equinixAuthenticator) fail(err error) error {
+	logger.With("error", err).Info()
+	if err == errInvalidCredentials || err == errTwoFactorNotEnabled {
+		metrics.AuthenticationFailureTotalCount.Inc()
+	}
+	return err
+}
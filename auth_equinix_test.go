@@ -0,0 +1,138 @@
+package doorman
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/packethost/pkg/log"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestEquinixAuthenticator(t *testing.T) {
+	logger = log.Test(t, "doorman")
+
+	var sessionRequests int32
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.RawQuery != "" || r.Header.Get("X-Consumer-Token") != "consumer" {
+			t.Errorf("expected credentials in the body and the consumer token, got %s %v", r.URL, r.Header)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.URL.Path == "/metal/v1/user" {
+			if r.Header.Get("X-Auth-Token") != "alice-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"id":"1a2b3c","email":"alice@example.com"}`))
+			return
+		}
+
+		var login struct{ Login, Password string }
+		atomic.AddInt32(&sessionRequests, 1)
+		if r.URL.Path != "/metal/v1/sessions" || json.NewDecoder(r.Body).Decode(&login) != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch {
+		case login.Login == "busy@example.com":
+			w.WriteHeader(http.StatusTooManyRequests)
+		case login.Login == "down@example.com":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case login.Login == "":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"errors":["Login can't be blank"]}`))
+		case login.Login == "carol@example.com" && r.Header.Get("X-OTP-Token") != "123456":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"errors":["OTP token required"]}`))
+		case login.Password != "correct horse":
+			w.WriteHeader(http.StatusUnauthorized)
+		case login.Login == "bob@example.com":
+			// bob has no twofactor authentication
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"s1","token":"bob-token"}`))
+		case r.Header.Get("X-OTP-Token") != "123456":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"s2","token":"alice-token"}`))
+		}
+	}))
+	defer api.Close()
+
+	base, err := url.Parse(api.URL + "/metal/v1/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &equinixAuthenticator{api: newEquinixAPI(base, "consumer")}
+	a.api.client.RetryMax = 0
+
+	id, err := a.Authenticate(context.Background(), "alice@example.com", "correct horse", "123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &identity{user: "alice@example.com", id: "1a2b3c", backend: authEquinix, token: "alice-token"}
+	if !reflect.DeepEqual(id, want) {
+		t.Fatalf("expected %+v, got %+v", want, id)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, test := range []struct {
+		ctx             context.Context
+		login, password string
+		otp             string
+		err             error
+		code            codes.Code
+	}{
+		{context.Background(), "alice@example.com", "wrong", "123456", errInvalidCredentials, codes.Unauthenticated},
+		{context.Background(), "alice@example.com", "correct horse", "654321", errInvalidCredentials, codes.Unauthenticated},
+		{context.Background(), "bob@example.com", "correct horse", "123456", errTwoFactorNotEnabled, codes.FailedPrecondition},
+		{context.Background(), "busy@example.com", "correct horse", "123456", errAPIRateLimited, codes.ResourceExhausted},
+		{context.Background(), "down@example.com", "correct horse", "123456", errAPIUnavailable, codes.Unavailable},
+		{context.Background(), "", "correct horse", "123456", errAPIRejected, codes.Internal},
+		{context.Background(), "carol@example.com", "correct horse", "654321", errInvalidCredentials, codes.Unauthenticated},
+		{canceled, "alice@example.com", "correct horse", "123456", context.Canceled, codes.Canceled},
+	} {
+		atomic.StoreInt32(&sessionRequests, 0)
+		_, err := a.Authenticate(test.ctx, test.login, test.password, test.otp)
+		if errors.Cause(err) != test.err {
+			t.Fatalf("%s: expected %v, got %v", test.login, test.err, err)
+		}
+		if code := status.Code(authStatus(errors.WithMessage(err, "authenticate"))); code != test.code {
+			t.Fatalf("%s: expected status %s, got %s", test.login, test.code, code)
+		}
+		// creating a session is not retried once the api answered
+		if test.login == "down@example.com" && atomic.LoadInt32(&sessionRequests) != 1 {
+			t.Fatalf("expected one session request, got %d", sessionRequests)
+		}
+	}
+}
+
+func TestRetryUnsent(t *testing.T) {
+	ctx := context.Background()
+	dial := &url.Error{Op: "Post", URL: "http://127.0.0.1:1", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}
+	read := &url.Error{Op: "Post", URL: "http://127.0.0.1:1", Err: &net.OpError{Op: "read", Err: errors.New("connection reset by peer")}}
+	for _, test := range []struct {
+		response *http.Response
+		err      error
+		retry    bool
+	}{
+		{nil, dial, true},
+		{nil, read, false},
+		{&http.Response{StatusCode: http.StatusServiceUnavailable}, nil, false},
+	} {
+		if retry, _ := retryUnsent(ctx, test.response, test.err); retry != test.retry {
+			t.Fatalf("%v %v: expected retry %v, got %v", test.response, test.err, test.retry, retry)
+		}
+	}
+}
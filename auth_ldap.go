@@ -0,0 +1,271 @@
+package doorman
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/equinix/doorman/metrics"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultLDAPUserFilter = "(uid=%s)"
+	defaultLDAPTimeout    = 10 * time.Second
+)
+
+// ldapAuthenticator checks users against an LDAP directory or Active Directory by binding as them, and lets them
+// reach the routes mapped to the groups they are members of.
+//
+// The one-time code is either checked against a base32 TOTP secret kept in an attribute of the user's entry, or,
+// when no attribute is configured, appended to the password on bind for directories that check it themselves,
+// like FreeIPA.
+type ldapAuthenticator struct {
+	url           string
+	tlsConfig     *tls.Config
+	startTLS      bool
+	timeout       time.Duration
+	bindDN        string // searches users anonymously if empty
+	bindPassword  string
+	baseDN        string
+	userFilter    string              // %s is replaced with the escaped login
+	groupFilter   string              // %s is replaced with the escaped user dn, memberOf is used if empty
+	totpAttribute string              // attribute holding the user's totp secret, empty if the directory checks codes
+	groups        map[string][]string // lower cased group dn -> routes its members may reach
+	now           func() time.Time
+
+	// mu guards steps, so each code can only be used once
+	mu    sync.Mutex
+	steps map[string]int64 // user dn -> last accepted totp step
+}
+
+// ldapAuthenticatorFromEnv configures an ldapAuthenticator from the DOORMAN_LDAP_* environment variables.
+func ldapAuthenticatorFromEnv() (*ldapAuthenticator, error) {
+	a := &ldapAuthenticator{
+		url:           os.Getenv(doormanLDAPURL),
+		bindDN:        os.Getenv(doormanLDAPBindDN),
+		bindPassword:  os.Getenv(doormanLDAPBindPassword),
+		baseDN:        os.Getenv(doormanLDAPBaseDN),
+		userFilter:    os.Getenv(doormanLDAPUserFilter),
+		groupFilter:   os.Getenv(doormanLDAPGroupFilter),
+		totpAttribute: os.Getenv(doormanLDAPTOTPAttribute),
+		timeout:       defaultLDAPTimeout,
+		now:           time.Now,
+		steps:         map[string]int64{},
+	}
+	if a.url == "" {
+		return nil, errors.New(doormanLDAPURL + " is empty")
+	}
+	if a.baseDN == "" {
+		return nil, errors.New(doormanLDAPBaseDN + " is empty")
+	}
+	if a.userFilter == "" {
+		a.userFilter = defaultLDAPUserFilter
+	}
+
+	if startTLS := os.Getenv(doormanLDAPStartTLS); startTLS != "" {
+		var err error
+		a.startTLS, err = strconv.ParseBool(startTLS)
+		if err != nil {
+			return nil, errors.Wrap(err, doormanLDAPStartTLS)
+		}
+	}
+
+	if caFile := os.Getenv(doormanLDAPCAFile); caFile != "" {
+		pem, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, errors.Wrap(err, doormanLDAPCAFile)
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("%s: no certificates found in %s", doormanLDAPCAFile, caFile)
+		}
+		a.tlsConfig = &tls.Config{RootCAs: roots}
+	}
+
+	groupsFile := os.Getenv(doormanLDAPGroups)
+	if groupsFile == "" {
+		return nil, errors.New(doormanLDAPGroups + " is empty")
+	}
+	var err error
+	a.groups, err = readGroupRoutes(groupsFile, ldapCanonicalDN)
+	if err != nil {
+		return nil, errors.WithMessage(err, doormanLDAPGroups)
+	}
+	return a, nil
+}
+
+// ldapCanonicalDN returns dn in the form groups are looked up by, dns are case insensitive.
+func ldapCanonicalDN(dn string) (string, error) {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil {
+		return "", err
+	}
+	return strings.ToLower(parsed.String()), nil
+}
+
+func (a *ldapAuthenticator) Authenticate(ctx context.Context, login, password, otp string) (*identity, error) {
+	if password == "" {
+		// an empty password would be an unauthenticated bind, which succeeds without checking anything
+		return nil, a.fail(login, "empty password")
+	}
+
+	conn, err := a.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if a.bindDN != "" {
+		if err := conn.Bind(a.bindDN, a.bindPassword); err != nil {
+			return nil, errors.Wrap(err, "ldap bind as "+a.bindDN)
+		}
+	}
+
+	attributes := []string{"memberOf"}
+	if a.totpAttribute != "" {
+		attributes = append(attributes, a.totpAttribute)
+	}
+	result, err := conn.Search(ldap.NewSearchRequest(
+		a.baseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, 0, false,
+		fmt.Sprintf(a.userFilter, ldap.EscapeFilter(login)), attributes, nil,
+	))
+	if err != nil {
+		return nil, errors.Wrap(err, "ldap search for user")
+	}
+	switch len(result.Entries) {
+	case 0:
+		return nil, a.fail(login, "unknown user")
+	case 1:
+	default:
+		return nil, a.fail(login, "ambiguous user")
+	}
+	entry := result.Entries[0]
+
+	bindPassword := password
+	if a.totpAttribute == "" {
+		bindPassword += otp
+	}
+	if err := conn.Bind(entry.DN, bindPassword); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, a.fail(login, "wrong password")
+		}
+		return nil, errors.Wrap(err, "ldap bind as user")
+	}
+
+	if a.totpAttribute != "" {
+		if err := a.verifyOTP(entry.DN, entry.GetAttributeValue(a.totpAttribute), otp); err != "" {
+			return nil, a.fail(login, err)
+		}
+	}
+
+	groups := entry.GetEqualFoldAttributeValues("memberOf")
+	if a.groupFilter != "" {
+		result, err := conn.Search(ldap.NewSearchRequest(
+			a.baseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+			fmt.Sprintf(a.groupFilter, ldap.EscapeFilter(entry.DN)), []string{"dn"}, nil,
+		))
+		if err != nil {
+			return nil, errors.Wrap(err, "ldap search for groups")
+		}
+		groups = nil
+		for _, group := range result.Entries {
+			groups = append(groups, group.DN)
+		}
+	}
+
+	var routes []string
+	for _, group := range groups {
+		dn, err := ldapCanonicalDN(group)
+		if err != nil {
+			continue
+		}
+		routes = append(routes, a.groups[dn]...)
+	}
+	if len(routes) == 0 {
+		return nil, a.fail(login, "not a member of any mapped group")
+	}
+	return &identity{user: login, backend: authLDAP, routes: routes}, nil
+}
+
+// dial connects to the directory, giving up once ctx is done or the timeout passed.
+func (a *ldapAuthenticator) dial(ctx context.Context) (*ldap.Conn, error) {
+	timeout := a.timeout
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < timeout {
+		timeout = time.Until(deadline)
+	}
+
+	opts := []ldap.DialOpt{ldap.DialWithDialer(&net.Dialer{Timeout: timeout})}
+	if a.tlsConfig != nil {
+		opts = append(opts, ldap.DialWithTLSConfig(a.tlsConfig))
+	}
+	conn, err := ldap.DialURL(a.url, opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "ldap dial")
+	}
+	conn.SetTimeout(timeout)
+
+	if a.startTLS {
+		config := a.tlsConfig
+		if config == nil {
+			config = &tls.Config{}
+		}
+		config = config.Clone()
+		if config.ServerName == "" {
+			config.ServerName = ldapHost(a.url)
+		}
+		if err := conn.StartTLS(config); err != nil {
+			conn.Close()
+			return nil, errors.Wrap(err, "ldap starttls")
+		}
+	}
+	return conn, nil
+}
+
+// ldapHost returns the host name of an ldap:// url, which starttls verifies the certificate against.
+func ldapHost(addr string) string {
+	host := strings.TrimPrefix(addr, "ldap://")
+	if i := strings.IndexAny(host, "/?"); i >= 0 {
+		host = host[:i]
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return host
+}
+
+// verifyOTP checks otp against the user's totp secret, returning why it was rejected if it was.
+func (a *ldapAuthenticator) verifyOTP(dn, secret, otp string) string {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil || len(key) == 0 {
+		return "no usable totp secret"
+	}
+	step, ok := totpVerify(key, otp, a.now())
+	if !ok {
+		return "wrong 2-factor token"
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if step <= a.steps[dn] {
+		return "2-factor token already used"
+	}
+	a.steps[dn] = step
+	return ""
+}
+
+// fail logs why login failed, the client is only told its credentials are invalid.
+func (a *ldapAuthenticator) fail(login, reason string) error {
+	logger.With("user", login, "reason", reason).Info("ldap authentication failed")
+	metrics.AuthenticationFailureTotalCount.Inc()
+	return errInvalidCredentials
+}
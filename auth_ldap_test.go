@@ -0,0 +1,220 @@
+package doorman
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/packethost/pkg/log"
+)
+
+// ldapStandIn is just enough of an LDAP server to log users in: simple binds and searches by exact filter.
+type ldapStandIn struct {
+	listener  net.Listener
+	passwords map[string]string        // dn -> password
+	entries   map[string][]*ldap.Entry // filter -> entries it matches
+
+	mu    sync.Mutex
+	binds []string
+}
+
+func newLDAPStandIn(t *testing.T, passwords map[string]string, entries map[string][]*ldap.Entry) *ldapStandIn {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &ldapStandIn{listener: listener, passwords: passwords, entries: entries}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *ldapStandIn) url() string {
+	return "ldap://" + s.listener.Addr().String()
+}
+
+func (s *ldapStandIn) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		id := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+
+		var responses []*ber.Packet
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			dn := op.Children[1].Value.(string)
+			password := op.Children[2].Data.String()
+			var code uint16 = ldap.LDAPResultInvalidCredentials
+			if want, ok := s.passwords[dn]; ok && want == password {
+				code = ldap.LDAPResultSuccess
+			}
+			s.mu.Lock()
+			s.binds = append(s.binds, dn)
+			s.mu.Unlock()
+			responses = append(responses, ldapResult(ldap.ApplicationBindResponse, code))
+		case ldap.ApplicationSearchRequest:
+			filter, err := ldap.DecompileFilter(op.Children[6])
+			if err != nil {
+				return
+			}
+			for _, entry := range s.entries[filter] {
+				responses = append(responses, ldapEntry(entry))
+			}
+			responses = append(responses, ldapResult(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess))
+		default:
+			return
+		}
+
+		for _, response := range responses {
+			message := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+			message.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, ""))
+			message.AppendChild(response)
+			if _, err := conn.Write(message.Bytes()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func ldapResult(tag ber.Tag, code uint16) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "")
+	op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), ""))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+	return op
+}
+
+func ldapEntry(entry *ldap.Entry) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "")
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.DN, ""))
+	attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+	for _, attribute := range entry.Attributes {
+		a := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+		a.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, attribute.Name, ""))
+		values := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "")
+		for _, value := range attribute.Values {
+			values.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, ""))
+		}
+		a.AppendChild(values)
+		attributes.AppendChild(a)
+	}
+	op.AppendChild(attributes)
+	return op
+}
+
+func TestLDAPAuthenticator(t *testing.T) {
+	logger = log.Test(t, "doorman")
+
+	const (
+		service = "cn=doorman,ou=services,dc=example,dc=com"
+		alice   = "uid=alice,ou=people,dc=example,dc=com"
+		bob     = "uid=bob,ou=people,dc=example,dc=com"
+		admins  = "cn=Net-Admins,ou=groups,dc=example,dc=com"
+		lab     = "cn=lab,ou=groups,dc=example,dc=com"
+	)
+	secret := []byte("12345678901234567890")
+	directory := newLDAPStandIn(t,
+		map[string]string{service: "service", alice: "correct horse", bob: "battery staple123456"},
+		map[string][]*ldap.Entry{
+			"(uid=alice)": {ldap.NewEntry(alice, map[string][]string{
+				"memberOf":          {admins, "cn=unmapped,ou=groups,dc=example,dc=com"},
+				"doormanTotpSecret": {totpEncoding.EncodeToString(secret)},
+			})},
+			"(uid=bob)": {ldap.NewEntry(bob, map[string][]string{"memberOf": {"cn=unmapped,ou=groups,dc=example,dc=com"}})},
+			"(&(objectClass=groupOfNames)(member=uid=bob,ou=people,dc=example,dc=com))": {
+				ldap.NewEntry(lab, nil),
+			},
+		},
+	)
+	defer directory.listener.Close()
+
+	groups, err := parseGroupRoutes(map[string][]string{
+		"cn=net-admins, ou=groups, dc=example, dc=com": {"10.88.111.1/25", "fd00:8a0:1::/56"},
+		lab: {"10.88.112.0/25"},
+	}, ldapCanonicalDN)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Unix(1600000000, 0)
+	a := &ldapAuthenticator{
+		url:           directory.url(),
+		timeout:       time.Second,
+		bindDN:        service,
+		bindPassword:  "service",
+		baseDN:        "dc=example,dc=com",
+		userFilter:    defaultLDAPUserFilter,
+		totpAttribute: "doormanTotpSecret",
+		groups:        groups,
+		now:           func() time.Time { return now },
+		steps:         map[string]int64{},
+	}
+
+	ctx := context.Background()
+	code := totpCode(secret, now.Unix()/totpPeriod)
+	for _, test := range []struct{ login, password, otp string }{
+		{"mallory", "correct horse", code},
+		{"alice", "", code},
+		{"alice", "battery staple", code},
+		{"alice", "correct horse", "000000"},
+		{"alice*", "correct horse", code},
+	} {
+		if _, err := a.Authenticate(ctx, test.login, test.password, test.otp); err != errInvalidCredentials {
+			t.Fatalf("%v: expected invalid credentials, got %v", test, err)
+		}
+	}
+
+	id, err := a.Authenticate(ctx, "alice", "correct horse", code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &identity{user: "alice", backend: authLDAP, routes: []string{"10.88.111.0/25", "fd00:8a0:1::/56"}}
+	if !reflect.DeepEqual(id, want) {
+		t.Fatalf("expected %v, got %v", want, id)
+	}
+	if _, err := a.Authenticate(ctx, "alice", "correct horse", code); err != errInvalidCredentials {
+		t.Fatalf("expected a replayed code to be refused, got %v", err)
+	}
+	directory.mu.Lock()
+	if directory.binds[len(directory.binds)-2] != service || directory.binds[len(directory.binds)-1] != alice {
+		t.Fatalf("expected to search as the service account and bind as the user, got %v", directory.binds)
+	}
+	directory.mu.Unlock()
+
+	// bob's directory checks the code appended to his password itself, and lists members in the groups
+	a.totpAttribute = ""
+	a.groupFilter = "(&(objectClass=groupOfNames)(member=%s))"
+	if _, err := a.Authenticate(ctx, "bob", "battery staple", "654321"); err != errInvalidCredentials {
+		t.Fatalf("expected a wrong code to be refused, got %v", err)
+	}
+	id, err = a.Authenticate(ctx, "bob", "battery staple", "123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = &identity{user: "bob", backend: authLDAP, routes: []string{"10.88.112.0/25"}}
+	if !reflect.DeepEqual(id, want) {
+		t.Fatalf("expected %v, got %v", want, id)
+	}
+
+	// alice is in no group that is mapped to routes when groups are looked up by member
+	a.totpAttribute = "doormanTotpSecret"
+	if _, err := a.Authenticate(ctx, "alice", "correct horse", totpCode(secret, now.Unix()/totpPeriod+1)); err != errInvalidCredentials {
+		t.Fatalf("expected users without routes to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,178 @@
+package doorman
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/equinix/doorman/metrics"
+	pb "github.com/equinix/doorman/protobuf"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	localIssuer            = "doorman" // shown next to the account in authenticator apps
+	localMinPasswordLength = 8
+	localSecretLength      = 20 // RFC 4226 recommends 160 bits
+)
+
+var errInvalidCredentials = errors.New("invalid username, password, or 2-factor token")
+
+// localAuthenticator checks users against the bcrypt password hashes and TOTP secrets kept in the state store,
+// for labs and on-prem environments without an Equinix Metal account. Local users are enrolled with the routes
+// they may reach.
+type localAuthenticator struct {
+	store *stateStore
+	now   func() time.Time
+
+	// mu serializes using up codes, so each can only be used once, and changes to users. It is not held while
+	// comparing bcrypt hashes, which takes long enough to stall all other logins.
+	mu sync.Mutex
+
+	dummyOnce sync.Once
+	dummyHash []byte
+}
+
+func newLocalAuthenticator(store *stateStore) *localAuthenticator {
+	return &localAuthenticator{store: store, now: time.Now}
+}
+
+func (a *localAuthenticator) Authenticate(ctx context.Context, login, password, otp string) (*identity, error) {
+	a.mu.Lock()
+	user, err := a.store.localUser(login)
+	a.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		// take as long as for known users, so usernames can't be probed
+		a.dummyOnce.Do(func() {
+			a.dummyHash, _ = bcrypt.GenerateFromPassword([]byte(localIssuer), bcrypt.DefaultCost)
+		})
+		bcrypt.CompareHashAndPassword(a.dummyHash, []byte(password))
+		return nil, a.fail(login, "unknown user")
+	}
+	if err := bcrypt.CompareHashAndPassword(user.PasswordHash, []byte(password)); err != nil {
+		return nil, a.fail(login, "wrong password")
+	}
+	step, ok := totpVerify(user.TotpSecret, otp, a.now())
+	if !ok {
+		return nil, a.fail(login, "wrong 2-factor token")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// another login may have used up the code meanwhile, or the user been re-enrolled or deleted
+	current, err := a.store.localUser(login)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil || !bytes.Equal(current.PasswordHash, user.PasswordHash) || !bytes.Equal(current.TotpSecret, user.TotpSecret) {
+		return nil, a.fail(login, "user changed while logging in")
+	}
+	if step <= current.LastTotpStep {
+		return nil, a.fail(login, "2-factor token already used")
+	}
+
+	current.LastTotpStep = step
+	if err := a.store.putLocalUser(current); err != nil {
+		return nil, err
+	}
+	return &identity{user: current.User, backend: authLocal, routes: current.Routes}, nil
+}
+
+// fail logs why login failed, the client is only told its credentials are invalid.
+func (a *localAuthenticator) fail(login, reason string) error {
+	logger.With("user", login, "reason", reason).Info("local authentication failed")
+	metrics.AuthenticationFailureTotalCount.Inc()
+	return errInvalidCredentials
+}
+
+// enroll creates the local user, or replaces it if force is set, and returns its new TOTP secret.
+func (a *localAuthenticator) enroll(name, password string, routes []string, force bool) ([]byte, error) {
+	if name == "" {
+		return nil, errors.New("no user supplied")
+	}
+	if len(password) < localMinPasswordLength {
+		return nil, errors.Errorf("password needs to be at least %d characters", localMinPasswordLength)
+	}
+	if len(routes) == 0 {
+		return nil, errors.New("no routes supplied")
+	}
+	networks := make([]string, 0, len(routes))
+	for _, route := range routes {
+		_, network, err := net.ParseCIDR(route)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing route %s", route)
+		}
+		networks = append(networks, network.String())
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, errors.Wrap(err, "hash password")
+	}
+	secret := make([]byte, localSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, errors.Wrap(err, "generate totp secret")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	existing, err := a.store.localUser(name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && !force {
+		return nil, errors.Errorf("user %s already exists", name)
+	}
+
+	user := &pb.LocalUser{
+		User:         name,
+		PasswordHash: hash,
+		TotpSecret:   secret,
+		Routes:       networks,
+		Created:      a.now().Unix(),
+	}
+	if err := a.store.putLocalUser(user); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+func (a *localAuthenticator) delete(name string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	user, err := a.store.localUser(name)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.Errorf("user %s does not exist", name)
+	}
+	return a.store.deleteLocalUser(name)
+}
+
+// users returns the local users sorted by name, without their password hashes and secrets.
+func (a *localAuthenticator) users() ([]*pb.LocalUser, error) {
+	users, err := a.store.localUsers()
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		user.PasswordHash = nil
+		user.TotpSecret = nil
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].User < users[j].User
+	})
+	return users, nil
+}
@@ -0,0 +1,130 @@
+package doorman
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/packethost/pkg/log"
+)
+
+func TestLocalAuthenticator(t *testing.T) {
+	logger = log.Test(t, "doorman")
+
+	store := newTestStateStore(t)
+
+	now := time.Unix(1600000000, 0)
+	a := newLocalAuthenticator(store)
+	a.now = func() time.Time { return now }
+
+	if _, err := a.enroll("alice", "short", []string{"10.88.111.0/25"}, false); err == nil {
+		t.Fatal("expected short passwords to be refused")
+	}
+	if _, err := a.enroll("alice", "correct horse", nil, false); err == nil {
+		t.Fatal("expected enrolling without routes to fail")
+	}
+	secret, err := a.enroll("alice", "correct horse", []string{"10.88.111.1/25", "fd00:8a0:1::/56"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.enroll("alice", "battery staple", []string{"10.88.111.0/25"}, false); err == nil {
+		t.Fatal("expected enrolling an existing user to fail")
+	}
+
+	ctx := context.Background()
+	code := totpCode(secret, now.Unix()/totpPeriod)
+	for _, test := range []struct{ login, password, otp string }{
+		{"bob", "correct horse", code},
+		{"alice", "battery staple", code},
+		{"alice", "correct horse", "000000"},
+	} {
+		if _, err := a.Authenticate(ctx, test.login, test.password, test.otp); err != errInvalidCredentials {
+			t.Fatalf("%v: expected invalid credentials, got %v", test, err)
+		}
+	}
+
+	id, err := a.Authenticate(ctx, "alice", "correct horse", code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &identity{user: "alice", backend: authLocal, routes: []string{"10.88.111.0/25", "fd00:8a0:1::/56"}}
+	if !reflect.DeepEqual(id, want) {
+		t.Fatalf("expected %+v, got %+v", want, id)
+	}
+
+	// codes can't be replayed, not even the one of the previous step
+	if _, err := a.Authenticate(ctx, "alice", "correct horse", code); err != errInvalidCredentials {
+		t.Fatalf("expected a replayed code to be refused, got %v", err)
+	}
+	if _, err := a.Authenticate(ctx, "alice", "correct horse", totpCode(secret, now.Unix()/totpPeriod-1)); err != errInvalidCredentials {
+		t.Fatalf("expected an older code to be refused, got %v", err)
+	}
+	now = now.Add(totpPeriod * time.Second)
+	if _, err := a.Authenticate(ctx, "alice", "correct horse", totpCode(secret, now.Unix()/totpPeriod)); err != nil {
+		t.Fatal(err)
+	}
+
+	// re-enrolling replaces the secret
+	newSecret, err := a.enroll("alice", "battery staple", []string{"10.88.112.0/25"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(totpPeriod * time.Second)
+	if _, err := a.Authenticate(ctx, "alice", "battery staple", totpCode(secret, now.Unix()/totpPeriod)); err != errInvalidCredentials {
+		t.Fatalf("expected the old secret to be refused, got %v", err)
+	}
+	if _, err := a.Authenticate(ctx, "alice", "battery staple", totpCode(newSecret, now.Unix()/totpPeriod)); err != nil {
+		t.Fatal(err)
+	}
+
+	users, err := a.users()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 || users[0].User != "alice" || users[0].PasswordHash != nil || users[0].TotpSecret != nil {
+		t.Fatalf("unexpected users: %v", users)
+	}
+
+	if err := a.delete("alice"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.delete("alice"); err == nil {
+		t.Fatal("expected deleting a missing user to fail")
+	}
+}
+
+func TestLocalAuthenticatorConcurrentLogins(t *testing.T) {
+	logger = log.Test(t, "doorman")
+
+	now := time.Unix(1600000000, 0)
+	a := newLocalAuthenticator(newTestStateStore(t))
+	a.now = func() time.Time { return now }
+	secret, err := a.enroll("alice", "correct horse", []string{"10.88.111.0/25"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the passwords are compared at the same time, but the code can only be used once
+	code := totpCode(secret, now.Unix()/totpPeriod)
+	errs := make(chan error, 4)
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			_, err := a.Authenticate(context.Background(), "alice", "correct horse", code)
+			errs <- err
+		}()
+	}
+	succeeded := 0
+	for i := 0; i < cap(errs); i++ {
+		switch err := <-errs; err {
+		case nil:
+			succeeded++
+		case errInvalidCredentials:
+		default:
+			t.Fatal(err)
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("expected one login to succeed, got %d", succeeded)
+	}
+}
@@ -0,0 +1,307 @@
+package doorman
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/equinix/doorman/metrics"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultOIDCScopes      = "openid profile email"
+	defaultOIDCUserClaim   = "preferred_username"
+	defaultOIDCGroupsClaim = "groups"
+	defaultOIDCInterval    = 5 * time.Second // RFC 8628 section 3.2
+	oidcSlowDown           = 5 * time.Second
+	oidcRequestTimeout     = 10 * time.Second
+
+	deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+)
+
+var errLoginDenied = errors.New("login was denied or timed out")
+
+// oidcAuthenticator logs users in with the OAuth 2.0 device authorization grant (RFC 8628) of an OpenID Connect
+// provider: the client is sent the verification url to open in a browser with openvpn's pending auth, and the login
+// completes once the user approved it with the provider. Users reach the routes mapped to the groups in their claims.
+type oidcAuthenticator struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	scopes       string
+	userClaim    string
+	groupsClaim  string
+	groups       map[string][]string // group -> routes its members may reach
+	client       *http.Client
+
+	mu       sync.Mutex
+	provider *oidcProvider // discovered on first use
+}
+
+// oidcProvider is the part of the provider's discovery document doorman needs.
+type oidcProvider struct {
+	Issuer                      string `json:"issuer"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+	TokenEndpoint               string `json:"token_endpoint"`
+	UserinfoEndpoint            string `json:"userinfo_endpoint"`
+}
+
+type deviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+type oidcToken struct {
+	AccessToken      string `json:"access_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// oidcAuthenticatorFromEnv configures an oidcAuthenticator from the DOORMAN_OIDC_* environment variables.
+func oidcAuthenticatorFromEnv() (*oidcAuthenticator, error) {
+	a := &oidcAuthenticator{
+		issuer:       strings.TrimSuffix(os.Getenv(doormanOIDCIssuer), "/"),
+		clientID:     os.Getenv(doormanOIDCClientID),
+		clientSecret: os.Getenv(doormanOIDCClientSecret),
+		scopes:       os.Getenv(doormanOIDCScopes),
+		userClaim:    os.Getenv(doormanOIDCUserClaim),
+		groupsClaim:  os.Getenv(doormanOIDCGroupsClaim),
+		client:       &http.Client{Timeout: oidcRequestTimeout},
+	}
+	if a.issuer == "" {
+		return nil, errors.New(doormanOIDCIssuer + " is empty")
+	}
+	if a.clientID == "" {
+		return nil, errors.New(doormanOIDCClientID + " is empty")
+	}
+	if a.scopes == "" {
+		a.scopes = defaultOIDCScopes
+	}
+	if a.userClaim == "" {
+		a.userClaim = defaultOIDCUserClaim
+	}
+	if a.groupsClaim == "" {
+		a.groupsClaim = defaultOIDCGroupsClaim
+	}
+
+	groupsFile := os.Getenv(doormanOIDCGroups)
+	if groupsFile == "" {
+		return nil, errors.New(doormanOIDCGroups + " is empty")
+	}
+	var err error
+	a.groups, err = readGroupRoutes(groupsFile, func(group string) (string, error) { return group, nil })
+	if err != nil {
+		return nil, errors.WithMessage(err, doormanOIDCGroups)
+	}
+	return a, nil
+}
+
+func (a *oidcAuthenticator) webLogin() {}
+
+// Authenticate ignores the credentials the client connected with, the user logs in with the provider instead.
+func (a *oidcAuthenticator) Authenticate(ctx context.Context, login, password, otp string) (*identity, error) {
+	pending := pendingAuth(ctx)
+	if pending == nil {
+		return nil, errors.New("oidc logins need clients to connect through the openvpn management interface")
+	}
+
+	provider, err := a.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	authorization := &deviceAuthorization{}
+	form := url.Values{"client_id": {a.clientID}, "scope": {a.scopes}}
+	if err := a.post(ctx, provider.DeviceAuthorizationEndpoint, form, authorization); err != nil {
+		return nil, errors.WithMessage(err, "start device authorization")
+	}
+	if authorization.DeviceCode == "" || authorization.VerificationURI == "" || authorization.ExpiresIn <= 0 {
+		return nil, errors.New("device authorization response is missing the device code, verification uri or expiry")
+	}
+
+	// the client only shows the url, so it needs to carry the user code
+	verification := authorization.VerificationURIComplete
+	if verification == "" {
+		u, err := url.Parse(authorization.VerificationURI)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing verification uri")
+		}
+		query := u.Query()
+		query.Set("user_code", authorization.UserCode)
+		u.RawQuery = query.Encode()
+		verification = u.String()
+	}
+
+	expiresIn := time.Duration(authorization.ExpiresIn) * time.Second
+	ctx, cancel := context.WithTimeout(ctx, expiresIn)
+	defer cancel()
+
+	if err := pending(verification, expiresIn); err != nil {
+		return nil, errors.WithMessage(err, "send verification url to client")
+	}
+
+	token, err := a.poll(ctx, provider, authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := map[string]interface{}{}
+	if err := a.do(ctx, provider.UserinfoEndpoint, nil, token, &claims); err != nil {
+		return nil, errors.WithMessage(err, "fetch userinfo")
+	}
+
+	user, _ := claims[a.userClaim].(string)
+	if user == "" {
+		user, _ = claims["sub"].(string)
+	}
+	if user == "" {
+		return nil, errors.New("userinfo has no subject")
+	}
+
+	var routes []string
+	for _, group := range claimStrings(claims[a.groupsClaim]) {
+		routes = append(routes, a.groups[group]...)
+	}
+	if len(routes) == 0 {
+		return nil, a.fail(user, "not a member of any mapped group")
+	}
+	return &identity{user: user, backend: authOIDC, routes: routes}, nil
+}
+
+// poll asks for the access token until the user approved or denied the login, or the device code expired.
+func (a *oidcAuthenticator) poll(ctx context.Context, provider *oidcProvider, authorization *deviceAuthorization) (string, error) {
+	interval := time.Duration(authorization.Interval) * time.Second
+	if interval <= 0 {
+		interval = defaultOIDCInterval
+	}
+	form := url.Values{
+		"grant_type":  {deviceCodeGrantType},
+		"device_code": {authorization.DeviceCode},
+		"client_id":   {a.clientID},
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return "", a.fail("", "device code expired")
+		case <-time.After(interval):
+		}
+
+		token := &oidcToken{}
+		err := a.post(ctx, provider.TokenEndpoint, form, token)
+		switch {
+		case token.Error == "authorization_pending":
+			continue
+		case token.Error == "slow_down":
+			interval += oidcSlowDown
+			continue
+		case token.Error != "":
+			return "", a.fail("", token.Error+": "+token.ErrorDescription)
+		case ctx.Err() != nil:
+			return "", a.fail("", "device code expired")
+		case err != nil:
+			return "", errors.WithMessage(err, "request access token")
+		case token.AccessToken == "":
+			return "", errors.New("token response has no access token")
+		}
+		return token.AccessToken, nil
+	}
+}
+
+// discover fetches the provider's discovery document, once.
+func (a *oidcAuthenticator) discover(ctx context.Context) (*oidcProvider, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.provider != nil {
+		return a.provider, nil
+	}
+
+	provider := &oidcProvider{}
+	if err := a.do(ctx, a.issuer+"/.well-known/openid-configuration", nil, "", provider); err != nil {
+		return nil, errors.WithMessage(err, "oidc discovery")
+	}
+	if strings.TrimSuffix(provider.Issuer, "/") != a.issuer {
+		return nil, errors.Errorf("oidc discovery: issuer %q does not match %q", provider.Issuer, a.issuer)
+	}
+	if provider.DeviceAuthorizationEndpoint == "" || provider.TokenEndpoint == "" || provider.UserinfoEndpoint == "" {
+		return nil, errors.New("oidc discovery: provider does not support the device authorization grant and userinfo")
+	}
+	a.provider = provider
+	return provider, nil
+}
+
+// post sends form to the endpoint, authenticated with the client secret if there is one.
+func (a *oidcAuthenticator) post(ctx context.Context, endpoint string, form url.Values, v interface{}) error {
+	return a.do(ctx, endpoint, form, "", v)
+}
+
+// do sends a GET, or a POST if form is not nil, and decodes the JSON response into v.
+// Error responses are decoded too, as OAuth 2.0 puts the reason in the body.
+func (a *oidcAuthenticator) do(ctx context.Context, endpoint string, form url.Values, bearer string, v interface{}) error {
+	method, body := http.MethodGet, ""
+	if form != nil {
+		method, body = http.MethodPost, form.Encode()
+	}
+	req, err := http.NewRequest(method, endpoint, strings.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "create request")
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if a.clientSecret != "" {
+			req.SetBasicAuth(url.QueryEscape(a.clientID), url.QueryEscape(a.clientSecret))
+		}
+	}
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, method+" "+endpoint)
+	}
+	defer resp.Body.Close()
+
+	decodeErr := json.NewDecoder(resp.Body).Decode(v)
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("%s %s: %s", method, endpoint, resp.Status)
+	}
+	return errors.Wrap(decodeErr, "decode response")
+}
+
+// claimStrings returns a claim that is either a string or a list of strings as a list.
+func claimStrings(claim interface{}) []string {
+	switch claim := claim.(type) {
+	case string:
+		return []string{claim}
+	case []interface{}:
+		var values []string
+		for _, value := range claim {
+			if s, ok := value.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// fail logs why login failed, the client is only told it was denied.
+func (a *oidcAuthenticator) fail(user, reason string) error {
+	logger.With("user", user, "reason", reason).Info("oidc authentication failed")
+	metrics.AuthenticationFailureTotalCount.Inc()
+	return errLoginDenied
+}
@@ -0,0 +1,154 @@
+package doorman
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/packethost/pkg/log"
+)
+
+// mockIdP is an OpenID Connect provider supporting just the device authorization grant.
+// Logins are approved by opening the verification url, and denied by opening it with deny=1.
+type mockIdP struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	approved map[string]bool // user code -> approved or denied
+}
+
+func newMockIdP() *mockIdP {
+	idp := &mockIdP{approved: map[string]bool{}}
+	codes := 0
+
+	mux := http.NewServeMux()
+	reply := func(w http.ResponseWriter, status int, v interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(v)
+	}
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, http.StatusOK, map[string]string{
+			"issuer":                        idp.URL,
+			"device_authorization_endpoint": idp.URL + "/device",
+			"token_endpoint":                idp.URL + "/token",
+			"userinfo_endpoint":             idp.URL + "/userinfo",
+		})
+	})
+	mux.HandleFunc("/device", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.FormValue("client_id") != "doorman" {
+			reply(w, http.StatusBadRequest, map[string]string{"error": "invalid_client"})
+			return
+		}
+		idp.mu.Lock()
+		codes++
+		code := string(rune('A' + codes))
+		idp.mu.Unlock()
+		reply(w, http.StatusOK, map[string]interface{}{
+			"device_code":      "device-" + code,
+			"user_code":        code,
+			"verification_uri": idp.URL + "/activate",
+			"expires_in":       60,
+			"interval":         1,
+		})
+	})
+	mux.HandleFunc("/activate", func(w http.ResponseWriter, r *http.Request) {
+		idp.mu.Lock()
+		idp.approved[r.FormValue("user_code")] = r.FormValue("deny") == ""
+		idp.mu.Unlock()
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("grant_type") != deviceCodeGrantType {
+			reply(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+			return
+		}
+		code := r.FormValue("device_code")[len("device-"):]
+		idp.mu.Lock()
+		approved, ok := idp.approved[code]
+		idp.mu.Unlock()
+		switch {
+		case !ok:
+			reply(w, http.StatusBadRequest, map[string]string{"error": "authorization_pending"})
+		case !approved:
+			reply(w, http.StatusBadRequest, map[string]string{"error": "access_denied"})
+		default:
+			reply(w, http.StatusOK, map[string]string{"access_token": "token-" + code, "token_type": "Bearer"})
+		}
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token-B" {
+			reply(w, http.StatusUnauthorized, map[string]string{"error": "invalid_token"})
+			return
+		}
+		reply(w, http.StatusOK, map[string]interface{}{
+			"sub":                "248289761001",
+			"preferred_username": "alice",
+			"groups":             []string{"netops", "everyone"},
+		})
+	})
+	idp.Server = httptest.NewServer(mux)
+	return idp
+}
+
+func TestOIDCAuthenticator(t *testing.T) {
+	logger = log.Test(t, "doorman")
+
+	idp := newMockIdP()
+	defer idp.Close()
+
+	groups, err := parseGroupRoutes(map[string][]string{
+		"netops": {"10.88.111.1/25", "fd00:8a0:1::/56"},
+		"lab":    {"10.88.112.0/25"},
+	}, func(group string) (string, error) { return group, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &oidcAuthenticator{
+		issuer:      idp.URL,
+		clientID:    "doorman",
+		scopes:      defaultOIDCScopes,
+		userClaim:   defaultOIDCUserClaim,
+		groupsClaim: defaultOIDCGroupsClaim,
+		groups:      groups,
+		client:      idp.Client(),
+	}
+
+	if _, err := a.Authenticate(context.Background(), "", "", ""); err == nil {
+		t.Fatal("expected logins without a way to reach the client to fail")
+	}
+
+	// the user opens the url the client was sent, approving the login
+	var urls []string
+	ctx := withPendingAuth(context.Background(), func(url string, timeout time.Duration) error {
+		if timeout != time.Minute {
+			t.Errorf("expected the device code's expiry as timeout, got %s", timeout)
+		}
+		urls = append(urls, url)
+		go http.Get(url)
+		return nil
+	})
+	id, err := a.Authenticate(ctx, "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &identity{user: "alice", backend: authOIDC, routes: []string{"10.88.111.0/25", "fd00:8a0:1::/56"}}
+	if !reflect.DeepEqual(id, want) {
+		t.Fatalf("expected %v, got %v", want, id)
+	}
+	if len(urls) != 1 || urls[0] != idp.URL+"/activate?user_code=B" {
+		t.Fatalf("expected the verification url to carry the user code, got %v", urls)
+	}
+
+	ctx = withPendingAuth(context.Background(), func(url string, timeout time.Duration) error {
+		go http.Get(url + "&deny=1")
+		return nil
+	})
+	if _, err := a.Authenticate(ctx, "", "", ""); err != errLoginDenied {
+		t.Fatalf("expected a denied login to fail, got %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+package doorman
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/equinix/doorman/metrics"
+	pb "github.com/equinix/doorman/protobuf"
+	"github.com/packethost/packngo"
+	"github.com/pkg/errors"
+)
+
+const (
+	serviceSecretPrefix = "dsa1."
+	serviceSecretLength = 32
+)
+
+// serviceAccounts log in machines like CI runners, which can't enter a twofactor token. A service account is bound to
+// a single client certificate, logs in with an Equinix Metal API token or a secret issued by doorman instead of a
+// password, and only reaches the private subnets of the projects it was enrolled with. The subnets of accounts logging
+// in with a secret are fetched with doorman's own API token.
+type serviceAccounts struct {
+	store         *stateStore
+	consumerToken string
+	apiToken      string
+	newClient     func(token string) *packngo.Client
+	now           func() time.Time
+
+	// mu serializes enrolling and deleting accounts
+	mu sync.Mutex
+}
+
+func newServiceAccounts(store *stateStore, consumerToken, apiToken string) *serviceAccounts {
+	a := &serviceAccounts{
+		store:         store,
+		consumerToken: consumerToken,
+		apiToken:      apiToken,
+		now:           time.Now,
+	}
+	a.newClient = func(token string) *packngo.Client {
+		return packngo.NewClientWithAuth(a.consumerToken, token, nil)
+	}
+	return a
+}
+
+// account returns the service account bound to the client, nil if the client belongs to a user.
+func (a *serviceAccounts) account(client string) (*pb.ServiceAccount, error) {
+	return a.store.serviceAccount(client)
+}
+
+// Authenticate checks the secret or API token the account logs in with. An API token is valid if it can read all
+// of the account's projects.
+func (a *serviceAccounts) Authenticate(ctx context.Context, account *pb.ServiceAccount, login, password string) (*identity, error) {
+	if login != account.Name {
+		return nil, a.fail(account, login, "wrong service account name")
+	}
+	id := &identity{user: account.Name, projects: account.Projects}
+
+	if len(account.SecretHash) != 0 {
+		hash := sha256.Sum256([]byte(password))
+		if subtle.ConstantTimeCompare(hash[:], account.SecretHash) != 1 {
+			return nil, a.fail(account, login, "wrong secret")
+		}
+		if a.apiToken == "" {
+			err := errors.New(doormanServiceAccountToken + " is empty, service accounts can't log in with a secret")
+			logger.Error(err)
+			metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+			return nil, err
+		}
+		id.token = a.apiToken
+		return id, nil
+	}
+
+	if password == "" || strings.HasPrefix(password, serviceSecretPrefix) {
+		return nil, a.fail(account, login, "expecting an api token")
+	}
+	client := a.newClient(password)
+	for _, project := range account.Projects {
+		if _, _, err := client.Projects.Get(project, nil); err != nil {
+			if e, ok := err.(*packngo.ErrorResponse); ok && e.Response != nil {
+				switch e.Response.StatusCode {
+				case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+					return nil, a.fail(account, login, "api token can't read project "+project)
+				}
+			}
+			err = errors.Wrapf(err, "fetching project=%s", project)
+			logger.With("error", err).Info()
+			return nil, err
+		}
+	}
+	id.token = password
+	return id, nil
+}
+
+// fail logs why the service account failed to log in, the client is only told its credentials are invalid.
+func (a *serviceAccounts) fail(account *pb.ServiceAccount, login, reason string) error {
+	logger.With("client", account.Client, "user", login, "reason", reason).Info("service account authentication failed")
+	metrics.AuthenticationFailureTotalCount.Inc()
+	return errInvalidCredentials
+}
+
+// enroll binds a service account to the client, or replaces the client's account if force is set. If issueSecret is
+// set it returns the secret the account logs in with, otherwise the account logs in with an API token.
+func (a *serviceAccounts) enroll(name, client string, projects []string, issueSecret, force bool) (string, error) {
+	if name == "" {
+		return "", errors.New("no service account name supplied")
+	}
+	if client == "" {
+		return "", errors.New("no client supplied")
+	}
+	if len(projects) == 0 {
+		return "", errors.New("no projects supplied")
+	}
+
+	account := &pb.ServiceAccount{
+		Name:     name,
+		Client:   client,
+		Projects: projects,
+		Created:  a.now().Unix(),
+	}
+	var secret string
+	if issueSecret {
+		b := make([]byte, serviceSecretLength)
+		if _, err := rand.Read(b); err != nil {
+			return "", errors.Wrap(err, "generate service account secret")
+		}
+		secret = serviceSecretPrefix + base64.RawURLEncoding.EncodeToString(b)
+		hash := sha256.Sum256([]byte(secret))
+		account.SecretHash = hash[:]
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	existing, err := a.store.serviceAccount(client)
+	if err != nil {
+		return "", err
+	}
+	if existing != nil && !force {
+		return "", errors.Errorf("client %s already has service account %s", client, existing.Name)
+	}
+	if err := a.store.putServiceAccount(account); err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+func (a *serviceAccounts) delete(client string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	account, err := a.store.serviceAccount(client)
+	if err != nil {
+		return err
+	}
+	if account == nil {
+		return errors.Errorf("client %s has no service account", client)
+	}
+	return a.store.deleteServiceAccount(client)
+}
+
+// accounts returns the service accounts sorted by name, without their secret hashes.
+func (a *serviceAccounts) accounts() ([]*pb.ServiceAccount, error) {
+	accounts, err := a.store.serviceAccounts()
+	if err != nil {
+		return nil, err
+	}
+	for _, account := range accounts {
+		account.SecretHash = nil
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		if accounts[i].Name != accounts[j].Name {
+			return accounts[i].Name < accounts[j].Name
+		}
+		return accounts[i].Client < accounts[j].Client
+	})
+	return accounts, nil
+}
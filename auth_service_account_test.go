@@ -0,0 +1,114 @@
+package doorman
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/packethost/packngo"
+	"github.com/packethost/pkg/log"
+)
+
+func TestServiceAccounts(t *testing.T) {
+	logger = log.Test(t, "doorman")
+
+	store := newTestStateStore(t)
+
+	// the ci token may read project p1 and p2, the other token only p1
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		token, project := r.Header.Get("X-Auth-Token"), strings.TrimPrefix(r.URL.Path, "/projects/")
+		if token != "ci-token" && (token != "other-token" || project != "p1") {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":["Not found"]}`))
+			return
+		}
+		w.Write([]byte(`{"id":"` + project + `"}`))
+	}))
+	defer api.Close()
+
+	a := newServiceAccounts(store, "", "doorman-token")
+	a.newClient = func(token string) *packngo.Client {
+		client, err := packngo.NewClientWithBaseURL("", token, nil, api.URL+"/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client
+	}
+
+	if _, err := a.enroll("ci", "runner1", nil, false, false); err == nil {
+		t.Fatal("expected enrolling without projects to fail")
+	}
+	if secret, err := a.enroll("ci", "runner1", []string{"p1", "p2"}, false, false); err != nil || secret != "" {
+		t.Fatalf("expected an api token account without a secret, got %q %v", secret, err)
+	}
+	if _, err := a.enroll("deploy", "runner1", []string{"p1"}, true, false); err == nil {
+		t.Fatal("expected enrolling a second account for the client to fail")
+	}
+	secret, err := a.enroll("deploy", "runner2", []string{"p1"}, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ci, err := a.account("runner1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	deploy, err := a.account("runner2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := a.Authenticate(context.Background(), ci, "ci", "ci-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &identity{user: "ci", token: "ci-token", projects: []string{"p1", "p2"}}
+	if !reflect.DeepEqual(id, want) {
+		t.Fatalf("expected %+v, got %+v", want, id)
+	}
+	id, err = a.Authenticate(context.Background(), deploy, "deploy", secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = &identity{user: "deploy", token: "doorman-token", projects: []string{"p1"}}
+	if !reflect.DeepEqual(id, want) {
+		t.Fatalf("expected %+v, got %+v", want, id)
+	}
+
+	for _, test := range []struct {
+		account         string
+		login, password string
+	}{
+		{"runner1", "ci", "other-token"}, // can't read p2
+		{"runner1", "deploy", "ci-token"},
+		{"runner1", "ci", secret},
+		{"runner2", "deploy", "ci-token"},
+		{"runner2", "deploy", secret + "x"},
+	} {
+		account := ci
+		if test.account == "runner2" {
+			account = deploy
+		}
+		if _, err := a.Authenticate(context.Background(), account, test.login, test.password); err != errInvalidCredentials {
+			t.Fatalf("%s %s %s: expected invalid credentials, got %v", test.account, test.login, test.password, err)
+		}
+	}
+
+	accounts, err := a.accounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 2 || accounts[0].Name != "ci" || accounts[1].Name != "deploy" || accounts[1].SecretHash != nil {
+		t.Fatalf("unexpected accounts %v", accounts)
+	}
+	if err := a.delete("runner2"); err != nil {
+		t.Fatal(err)
+	}
+	if account, err := a.account("runner2"); err != nil || account != nil {
+		t.Fatalf("expected the account to be deleted, got %v %v", account, err)
+	}
+}
@@ -32,10 +32,16 @@ var authCmd = &cobra.Command{
 			log.Fatal(err)
 		}
 
+		controlFile, err := cmd.Flags().GetString("control-file")
+		if err != nil {
+			log.Fatal(err)
+		}
+
 		resp, err := conn.Authenticate(context.Background(), &doorman.AuthenticateRequest{
 			File:         file,
 			Client:       client,
 			ConnectingIp: ip.String(),
+			ControlFile:  controlFile,
 		})
 		if err != nil {
 			log.Fatal(err)
@@ -48,6 +54,7 @@ func init() {
 	authCmd.Flags().StringP("creds", "c", "", "client credentials file")
 	authCmd.Flags().IPP("ip", "i", net.IPv4zero, "client source ip")
 	authCmd.Flags().StringP("user", "u", "", "client user id")
+	authCmd.Flags().StringP("control-file", "a", "", "openvpn auth_control_file, defers the decision to it")
 	authCmd.MarkFlagRequired("creds")
 	authCmd.MarkFlagRequired("ip")
 	authCmd.MarkFlagRequired("user")
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	doorman "github.com/equinix/doorman/protobuf"
+	"github.com/spf13/cobra"
+)
+
+// clearLockoutCmd represents the clear-lockout command
+var clearLockoutCmd = &cobra.Command{
+	Use:   "clear-lockout",
+	Short: "Forget the failed logins of a user or address, lifting its lockout",
+	Run: func(cmd *cobra.Command, args []string) {
+		user, err := cmd.Flags().GetString("user")
+		if err != nil {
+			log.Fatal(err)
+		}
+		ip, err := cmd.Flags().GetString("ip")
+		if err != nil {
+			log.Fatal(err)
+		}
+		all, err := cmd.Flags().GetBool("all")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		req := &doorman.ClearLockoutRequest{All: all}
+		switch {
+		case all && (user != "" || ip != ""), user != "" && ip != "":
+			log.Fatal("expecting one of --user, --ip or --all")
+		case user != "":
+			req.Kind, req.Key = "user", user
+		case ip != "":
+			req.Kind, req.Key = "ip", ip
+		case !all:
+			log.Fatal("expecting one of --user, --ip or --all")
+		}
+
+		conn := connectGRPC(cmd.Flags().GetString("facility"))
+		resp, err := conn.ClearLockout(context.Background(), req)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("cleared %d\n", resp.Cleared)
+	},
+}
+
+func init() {
+	clearLockoutCmd.Flags().StringP("user", "u", "", "user name")
+	clearLockoutCmd.Flags().StringP("ip", "i", "", "connecting ip address")
+	clearLockoutCmd.Flags().Bool("all", false, "clear all users and addresses")
+	rootCmd.AddCommand(clearLockoutCmd)
+}
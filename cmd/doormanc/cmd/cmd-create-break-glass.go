@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	doorman "github.com/equinix/doorman/protobuf"
+	"github.com/spf13/cobra"
+)
+
+// createBreakGlassCmd represents the create-break-glass command
+var createBreakGlassCmd = &cobra.Command{
+	Use:   "create-break-glass",
+	Short: "Create an emergency credential for when the authentication backend is unavailable",
+	Long: `Create an emergency credential for when the authentication backend is unavailable, e.g. the Equinix Metal API is down.
+
+The credential is entered as password with any username and twofactor token, and is only valid with the client's
+certificate. It is checked by doorman alone and reaches the given routes. Every use is logged as an audit event.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := cmd.Flags().GetString("user")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		routes, err := cmd.Flags().GetStringSlice("route")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		lifetime, err := cmd.Flags().GetDuration("lifetime")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		uses, err := cmd.Flags().GetInt32("uses")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		reason, err := cmd.Flags().GetString("reason")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		conn := connectGRPC(cmd.Flags().GetString("facility"))
+		resp, err := conn.CreateBreakGlass(context.Background(), &doorman.CreateBreakGlassRequest{
+			Client:   client,
+			Routes:   routes,
+			Lifetime: int64(lifetime / time.Second),
+			Uses:     uses,
+			Reason:   reason,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Printf(`{"id":"%s", "credential":"%s", "expires":"%s"}`+"\n", resp.Id, resp.Credential, time.Unix(resp.Expires, 0).UTC().Format(time.RFC3339))
+	},
+}
+
+func init() {
+	createBreakGlassCmd.Flags().StringP("user", "u", "", "client the credential may be used with")
+	createBreakGlassCmd.Flags().StringSliceP("route", "r", nil, "network the credential reaches, may be repeated")
+	createBreakGlassCmd.Flags().Duration("lifetime", 4*time.Hour, "how long the credential is valid")
+	createBreakGlassCmd.Flags().Int32("uses", 1, "how often the credential may be used, 0 for any number of times until it expires")
+	createBreakGlassCmd.Flags().String("reason", "", "why the credential is needed, recorded with every use")
+	createBreakGlassCmd.MarkFlagRequired("user")
+	createBreakGlassCmd.MarkFlagRequired("route")
+	createBreakGlassCmd.MarkFlagRequired("reason")
+	rootCmd.AddCommand(createBreakGlassCmd)
+}
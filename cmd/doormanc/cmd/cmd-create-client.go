@@ -19,9 +19,15 @@ var createClientCmd = &cobra.Command{
 			log.Fatal(err)
 		}
 
+		owner, err := cmd.Flags().GetString("owner")
+		if err != nil {
+			log.Fatal(err)
+		}
+
 		conn := connectGRPC(cmd.Flags().GetString("facility"))
 		resp, err := conn.CreateClient(context.Background(), &doorman.CreateClientRequest{
 			Client: client,
+			Owner:  owner,
 		})
 		if err != nil {
 			log.Fatal(err)
@@ -33,6 +39,7 @@ var createClientCmd = &cobra.Command{
 
 func init() {
 	createClientCmd.Flags().StringP("user", "u", "", "Equinix User UUID")
+	createClientCmd.Flags().StringP("owner", "o", "", "user the certificate is issued to, only they may log in with it (default the client's name)")
 	createClientCmd.MarkFlagRequired("user")
 	rootCmd.AddCommand(createClientCmd)
 }
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+
+	doorman "github.com/equinix/doorman/protobuf"
+	"github.com/spf13/cobra"
+)
+
+// deleteServiceAccountCmd represents the delete-service-account command
+var deleteServiceAccountCmd = &cobra.Command{
+	Use:   "delete-service-account",
+	Short: "Delete the service account bound to a client",
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := cmd.Flags().GetString("user")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		conn := connectGRPC(cmd.Flags().GetString("facility"))
+		resp, err := conn.DeleteServiceAccount(context.Background(), &doorman.DeleteServiceAccountRequest{
+			Client: client,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(int(resp.Status))
+	},
+}
+
+func init() {
+	deleteServiceAccountCmd.Flags().StringP("user", "u", "", "client the account is bound to")
+	deleteServiceAccountCmd.MarkFlagRequired("user")
+	rootCmd.AddCommand(deleteServiceAccountCmd)
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+
+	doorman "github.com/equinix/doorman/protobuf"
+	"github.com/spf13/cobra"
+)
+
+// deleteUserCmd represents the delete-user command
+var deleteUserCmd = &cobra.Command{
+	Use:   "delete-user",
+	Short: "Delete a local user",
+	Run: func(cmd *cobra.Command, args []string) {
+		user, err := cmd.Flags().GetString("user")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		conn := connectGRPC(cmd.Flags().GetString("facility"))
+		resp, err := conn.DeleteLocalUser(context.Background(), &doorman.DeleteLocalUserRequest{
+			User: user,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(int(resp.Status))
+	},
+}
+
+func init() {
+	deleteUserCmd.Flags().StringP("user", "u", "", "local user name")
+	deleteUserCmd.MarkFlagRequired("user")
+	rootCmd.AddCommand(deleteUserCmd)
+}
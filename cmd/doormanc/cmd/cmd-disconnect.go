@@ -19,9 +19,15 @@ var disconnectCmd = &cobra.Command{
 			log.Fatal(err)
 		}
 
+		message, err := cmd.Flags().GetString("message")
+		if err != nil {
+			log.Fatal(err)
+		}
+
 		conn := connectGRPC(cmd.Flags().GetString("facility"))
 		resp, err := conn.Disconnect(context.Background(), &doorman.DisconnectRequest{
-			Client: client,
+			Client:  client,
+			Message: message,
 		})
 		if err != nil {
 			log.Fatal(err)
@@ -32,6 +38,7 @@ var disconnectCmd = &cobra.Command{
 
 func init() {
 	disconnectCmd.Flags().StringP("user", "u", "", "client user id")
+	disconnectCmd.Flags().StringP("message", "m", "", "message sent to the client when its session is killed")
 	disconnectCmd.MarkFlagRequired("user")
 	rootCmd.AddCommand(disconnectCmd)
 }
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	doorman "github.com/equinix/doorman/protobuf"
+	"github.com/spf13/cobra"
+)
+
+// enrollServiceAccountCmd represents the enroll-service-account command
+var enrollServiceAccountCmd = &cobra.Command{
+	Use:   "enroll-service-account",
+	Short: "Enroll a service account for machines like CI runners",
+	Long: `Enroll a service account for machines like CI runners, bound to a single client certificate.
+
+The account logs in with its name as username and an Equinix Metal API token as password, which needs to be able to
+read all of the account's projects. With --secret, doorman prints a secret the account logs in with instead.
+No twofactor token is asked for, and only the private subnets of the account's projects are pushed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		name, err := cmd.Flags().GetString("name")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		client, err := cmd.Flags().GetString("user")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		projects, err := cmd.Flags().GetStringSlice("project")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		secret, err := cmd.Flags().GetBool("secret")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		force, err := cmd.Flags().GetBool("force")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		conn := connectGRPC(cmd.Flags().GetString("facility"))
+		resp, err := conn.EnrollServiceAccount(context.Background(), &doorman.EnrollServiceAccountRequest{
+			Name:        name,
+			Client:      client,
+			Projects:    projects,
+			IssueSecret: secret,
+			Force:       force,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Printf(`{"name":"%s", "client":"%s", "secret":"%s"}`+"\n", name, client, resp.Secret)
+	},
+}
+
+func init() {
+	enrollServiceAccountCmd.Flags().StringP("name", "n", "", "service account name, the username it logs in with")
+	enrollServiceAccountCmd.Flags().StringP("user", "u", "", "client the account is bound to")
+	enrollServiceAccountCmd.Flags().StringSliceP("project", "p", nil, "project whose subnets the account may reach, may be repeated")
+	enrollServiceAccountCmd.Flags().Bool("secret", false, "issue a secret to log in with instead of an api token")
+	enrollServiceAccountCmd.Flags().Bool("force", false, "replace the client's service account if it has one")
+	enrollServiceAccountCmd.MarkFlagRequired("name")
+	enrollServiceAccountCmd.MarkFlagRequired("user")
+	enrollServiceAccountCmd.MarkFlagRequired("project")
+	rootCmd.AddCommand(enrollServiceAccountCmd)
+}
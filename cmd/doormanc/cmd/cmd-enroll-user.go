@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	doorman "github.com/equinix/doorman/protobuf"
+	"github.com/spf13/cobra"
+)
+
+// enrollUserCmd represents the enroll-user command
+var enrollUserCmd = &cobra.Command{
+	Use:   "enroll-user",
+	Short: "Enroll a local user, reading its password from stdin",
+	Long: `Enroll a local user, reading its password from stdin.
+
+Prints the user's TOTP secret and the otpauth url to add it to an authenticator app with.
+Users log in with the code from the app followed by their password, like Equinix Metal users do.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		user, err := cmd.Flags().GetString("user")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		routes, err := cmd.Flags().GetStringSlice("route")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		force, err := cmd.Flags().GetBool("force")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && password == "" {
+			log.Fatal("reading password: ", err)
+		}
+
+		conn := connectGRPC(cmd.Flags().GetString("facility"))
+		resp, err := conn.EnrollLocalUser(context.Background(), &doorman.EnrollLocalUserRequest{
+			User:     user,
+			Password: strings.TrimRight(password, "\r\n"),
+			Routes:   routes,
+			Force:    force,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Printf(`{"user":"%s", "totp_secret":"%s", "totp_url":"%s"}`+"\n", user, resp.TotpSecret, resp.TotpUrl)
+	},
+}
+
+func init() {
+	enrollUserCmd.Flags().StringP("user", "u", "", "local user name")
+	enrollUserCmd.Flags().StringSliceP("route", "r", nil, "network the user may reach, may be repeated")
+	enrollUserCmd.Flags().Bool("force", false, "replace the user's password, secret and routes if it exists")
+	enrollUserCmd.MarkFlagRequired("user")
+	enrollUserCmd.MarkFlagRequired("route")
+	rootCmd.AddCommand(enrollUserCmd)
+}
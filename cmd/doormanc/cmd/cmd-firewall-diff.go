@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	doorman "github.com/equinix/doorman/protobuf"
+	"github.com/spf13/cobra"
+)
+
+// firewallDiffCmd represents the firewall-diff command
+var firewallDiffCmd = &cobra.Command{
+	Use:   "firewall-diff",
+	Short: "Show differences between the firewall and the connected clients (sorted by ip address)",
+	Run: func(cmd *cobra.Command, args []string) {
+		conn := connectGRPC(cmd.Flags().GetString("facility"))
+		resp, err := conn.FirewallDiff(context.Background(), &doorman.FirewallDiffRequest{})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, drift := range resp.Drifts {
+			fmt.Printf(`{"kind":"%s", "ip":"%s", "ipv6":"%s", "client":"%s", "missing_routes":"%s", "extra_routes":"%s"}`+"\n",
+				drift.Kind, drift.Ip, drift.Ipv6, drift.Client, strings.Join(drift.MissingRoutes, ","), strings.Join(drift.ExtraRoutes, ","))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(firewallDiffCmd)
+}
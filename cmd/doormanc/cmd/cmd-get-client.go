@@ -26,11 +26,12 @@ var getClientCmd = &cobra.Command{
 		if err != nil {
 			log.Fatal(err)
 		}
-		fmt.Printf(`{"status":%q, "exipires_date":%d, "revocation_date":%d, "config":%q}`+"\n",
+		fmt.Printf(`{"status":%q, "exipires_date":%d, "revocation_date":%d, "config":%q, "owner":%q}`+"\n",
 			resp.Status.String(),
 			resp.ExpiresDate,
 			resp.RevocationDate,
 			resp.Config,
+			resp.Owner,
 		)
 	},
 }
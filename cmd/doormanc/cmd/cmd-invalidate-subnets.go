@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	doorman "github.com/equinix/doorman/protobuf"
+	"github.com/spf13/cobra"
+)
+
+// invalidateSubnetsCmd represents the invalidate-subnets command
+var invalidateSubnetsCmd = &cobra.Command{
+	Use:   "invalidate-subnets",
+	Short: "Drop the cached subnets of a user, fetching them again on its next login",
+	Run: func(cmd *cobra.Command, args []string) {
+		user, err := cmd.Flags().GetString("user")
+		if err != nil {
+			log.Fatal(err)
+		}
+		all, err := cmd.Flags().GetBool("all")
+		if err != nil {
+			log.Fatal(err)
+		}
+		if (user == "") == !all {
+			log.Fatal("expecting one of --user or --all")
+		}
+
+		conn := connectGRPC(cmd.Flags().GetString("facility"))
+		resp, err := conn.InvalidateSubnets(context.Background(), &doorman.InvalidateSubnetsRequest{User: user, All: all})
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("invalidated %d\n", resp.Invalidated)
+	},
+}
+
+func init() {
+	invalidateSubnetsCmd.Flags().StringP("user", "u", "", "user name or id")
+	invalidateSubnetsCmd.Flags().Bool("all", false, "invalidate the subnets of all users")
+	rootCmd.AddCommand(invalidateSubnetsCmd)
+}
@@ -37,18 +37,33 @@ var listAllocsCmd = &cobra.Command{
 	Short: "List ip pool and possibly allocated clients (sorted by ip address)",
 	Run: func(cmd *cobra.Command, args []string) {
 		conn := connectGRPC(cmd.Flags().GetString("facility"))
-		resp, err := conn.ListAllocations(context.Background(), &doorman.ListAllocationsRequest{})
+		allocated, err := cmd.Flags().GetBool("allocated")
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		sort.Sort(sortableAllocs(resp.Allocations))
-		for _, alloc := range resp.Allocations {
-			fmt.Printf(`{"ip":"%s", "client":"%s"}`+"\n", alloc.Ip, alloc.Client)
+		var allocs []*doorman.Allocation
+		req := &doorman.ListAllocationsRequest{OnlyAllocated: allocated}
+		for {
+			resp, err := conn.ListAllocations(context.Background(), req)
+			if err != nil {
+				log.Fatal(err)
+			}
+			allocs = append(allocs, resp.Allocations...)
+			if resp.NextPageToken == "" {
+				break
+			}
+			req.PageToken = resp.NextPageToken
+		}
+
+		sort.Sort(sortableAllocs(allocs))
+		for _, alloc := range allocs {
+			fmt.Printf(`{"ip":"%s", "ipv6":"%s", "pool":"%s", "client":"%s", "pinned":%t}`+"\n", alloc.Ip, alloc.Ipv6, alloc.Pool, alloc.Client, alloc.Pinned)
 		}
 	},
 }
 
 func init() {
+	listAllocsCmd.Flags().BoolP("allocated", "a", false, "only list allocated ips")
 	rootCmd.AddCommand(listAllocsCmd)
 }
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	doorman "github.com/equinix/doorman/protobuf"
+	"github.com/spf13/cobra"
+)
+
+// listBreakGlassCmd represents the list-break-glass command
+var listBreakGlassCmd = &cobra.Command{
+	Use:   "list-break-glass",
+	Short: "List emergency credentials that have not expired (sorted by expiry)",
+	Run: func(cmd *cobra.Command, args []string) {
+		conn := connectGRPC(cmd.Flags().GetString("facility"))
+		resp, err := conn.ListBreakGlass(context.Background(), &doorman.ListBreakGlassRequest{})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, bg := range resp.Credentials {
+			fmt.Printf(`{"id":"%s", "client":"%s", "routes":"%s", "expires":"%s", "uses_left":%d, "used":%d, "reason":%q}`+"\n",
+				bg.Id, bg.Client, strings.Join(bg.Routes, ","), time.Unix(bg.Expires, 0).UTC().Format(time.RFC3339), bg.UsesLeft, bg.Used, bg.Reason)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(listBreakGlassCmd)
+}
@@ -49,11 +49,16 @@ var listConnectionsCmd = &cobra.Command{
 
 		sort.Sort(sortableConnections(resp.Connections))
 		for _, conn := range resp.Connections {
-			fmt.Printf(`{"id":%q, "allocation":%q, "source":%q, "since":%d}`+"\n",
+			fmt.Printf(`{"id":%q, "allocation":%q, "allocation_ipv6":%q, "source":%q, "since":%d, "real_address":%q, "bytes_received":%d, "bytes_sent":%d, "last_ref":%d}`+"\n",
 				conn.Client,
 				conn.Allocation.Ip,
+				conn.Allocation.Ipv6,
 				conn.ConnectingIp,
 				conn.Since,
+				conn.RealAddress,
+				conn.BytesReceived,
+				conn.BytesSent,
+				conn.LastRef,
 			)
 		}
 	},
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	doorman "github.com/equinix/doorman/protobuf"
+	"github.com/spf13/cobra"
+)
+
+// listLockoutsCmd represents the list-lockouts command
+var listLockoutsCmd = &cobra.Command{
+	Use:   "list-lockouts",
+	Short: "List users and addresses with failed logins (sorted by kind and key)",
+	Run: func(cmd *cobra.Command, args []string) {
+		conn := connectGRPC(cmd.Flags().GetString("facility"))
+		resp, err := conn.ListLockouts(context.Background(), &doorman.ListLockoutsRequest{})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, lockout := range resp.Lockouts {
+			lockedUntil := ""
+			if lockout.LockedUntil != 0 {
+				lockedUntil = time.Unix(lockout.LockedUntil, 0).UTC().Format(time.RFC3339)
+			}
+			fmt.Printf(`{"kind":"%s", "key":"%s", "failures":%d, "last_failure":"%s", "locked_until":"%s"}`+"\n", lockout.Kind, lockout.Key, lockout.Failures, time.Unix(lockout.LastFailure, 0).UTC().Format(time.RFC3339), lockedUntil)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(listLockoutsCmd)
+}
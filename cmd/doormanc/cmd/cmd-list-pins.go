@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	doorman "github.com/equinix/doorman/protobuf"
+	"github.com/spf13/cobra"
+)
+
+// listPinsCmd represents the list-pins command
+var listPinsCmd = &cobra.Command{
+	Use:   "list-pins",
+	Short: "List vpn ip addresses pinned to clients (sorted by ip address)",
+	Run: func(cmd *cobra.Command, args []string) {
+		conn := connectGRPC(cmd.Flags().GetString("facility"))
+		resp, err := conn.ListPins(context.Background(), &doorman.ListPinsRequest{})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, pin := range resp.Pins {
+			fmt.Printf(`{"ip":"%s", "pool":"%s", "client":"%s"}`+"\n", pin.Ip, pin.Pool, pin.Client)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(listPinsCmd)
+}
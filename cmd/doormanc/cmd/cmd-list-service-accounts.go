@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	doorman "github.com/equinix/doorman/protobuf"
+	"github.com/spf13/cobra"
+)
+
+// listServiceAccountsCmd represents the list-service-accounts command
+var listServiceAccountsCmd = &cobra.Command{
+	Use:   "list-service-accounts",
+	Short: "List service accounts (sorted by name)",
+	Run: func(cmd *cobra.Command, args []string) {
+		conn := connectGRPC(cmd.Flags().GetString("facility"))
+		resp, err := conn.ListServiceAccounts(context.Background(), &doorman.ListServiceAccountsRequest{})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, account := range resp.Accounts {
+			fmt.Printf(`{"name":"%s", "client":"%s", "projects":"%s", "created":"%s"}`+"\n", account.Name, account.Client, strings.Join(account.Projects, ","), time.Unix(account.Created, 0).UTC().Format(time.RFC3339))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(listServiceAccountsCmd)
+}
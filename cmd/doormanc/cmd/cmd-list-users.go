@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	doorman "github.com/equinix/doorman/protobuf"
+	"github.com/spf13/cobra"
+)
+
+// listUsersCmd represents the list-users command
+var listUsersCmd = &cobra.Command{
+	Use:   "list-users",
+	Short: "List local users (sorted by name)",
+	Run: func(cmd *cobra.Command, args []string) {
+		conn := connectGRPC(cmd.Flags().GetString("facility"))
+		resp, err := conn.ListLocalUsers(context.Background(), &doorman.ListLocalUsersRequest{})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, user := range resp.Users {
+			fmt.Printf(`{"user":"%s", "routes":"%s", "created":"%s"}`+"\n", user.User, strings.Join(user.Routes, ","), time.Unix(user.Created, 0).UTC().Format(time.RFC3339))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(listUsersCmd)
+}
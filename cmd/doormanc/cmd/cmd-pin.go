@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+
+	doorman "github.com/equinix/doorman/protobuf"
+	"github.com/spf13/cobra"
+)
+
+// pinCmd represents the pin command
+var pinCmd = &cobra.Command{
+	Use:   "pin",
+	Short: "Pin a vpn ip address to a client",
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := cmd.Flags().GetString("user")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		ip, err := cmd.Flags().GetIP("ip")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		conn := connectGRPC(cmd.Flags().GetString("facility"))
+		resp, err := conn.PinAllocation(context.Background(), &doorman.PinAllocationRequest{
+			Client: client,
+			Ip:     ip.String(),
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(int(resp.Status))
+	},
+}
+
+func init() {
+	pinCmd.Flags().StringP("user", "u", "", "client user id")
+	pinCmd.Flags().IPP("ip", "i", nil, "vpn ip address to pin")
+	pinCmd.MarkFlagRequired("user")
+	pinCmd.MarkFlagRequired("ip")
+	rootCmd.AddCommand(pinCmd)
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+
+	doorman "github.com/equinix/doorman/protobuf"
+	"github.com/spf13/cobra"
+)
+
+// revokeBreakGlassCmd represents the revoke-break-glass command
+var revokeBreakGlassCmd = &cobra.Command{
+	Use:   "revoke-break-glass",
+	Short: "Revoke an emergency credential",
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := cmd.Flags().GetString("id")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		conn := connectGRPC(cmd.Flags().GetString("facility"))
+		resp, err := conn.RevokeBreakGlass(context.Background(), &doorman.RevokeBreakGlassRequest{
+			Id: id,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(int(resp.Status))
+	},
+}
+
+func init() {
+	revokeBreakGlassCmd.Flags().String("id", "", "credential id")
+	revokeBreakGlassCmd.MarkFlagRequired("id")
+	rootCmd.AddCommand(revokeBreakGlassCmd)
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+
+	doorman "github.com/equinix/doorman/protobuf"
+	"github.com/spf13/cobra"
+)
+
+// unpinCmd represents the unpin command
+var unpinCmd = &cobra.Command{
+	Use:   "unpin",
+	Short: "Remove a client's pinned vpn ip address",
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := cmd.Flags().GetString("user")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		conn := connectGRPC(cmd.Flags().GetString("facility"))
+		resp, err := conn.UnpinAllocation(context.Background(), &doorman.UnpinAllocationRequest{
+			Client: client,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(int(resp.Status))
+	},
+}
+
+func init() {
+	unpinCmd.Flags().StringP("user", "u", "", "client user id")
+	unpinCmd.MarkFlagRequired("user")
+	rootCmd.AddCommand(unpinCmd)
+}
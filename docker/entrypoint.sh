@@ -49,6 +49,10 @@ if [[ -z ${GRPC_INSECURE:-} ]]; then
 	fi
 fi
 
+if [[ ${EQUINIX_ENV:-} == testing ]]; then
+	export DOORMAN_SUBNETS_FILE=${DOORMAN_SUBNETS_FILE:-/app/subnets-testing.yaml}
+fi
+
 cat >/etc/doorman-env <<EOF
 export CONNECT_PUBLIC_HOSTNAME=${GRPC_CERT:+true}
 export FACILITY=$FACILITY
@@ -6,6 +6,10 @@ do_undo() {
 	iptables-save | grep -vw "$vip" | iptables-restore
 }
 
+do_undo6() {
+	ip6tables-save | grep -vw "$vip" | ip6tables-restore
+}
+
 action=$1
 vip=$2 # vpn ip
 
@@ -39,6 +43,33 @@ enable)
 		iptables -t nat -I DOORMAN_POSTROUTING 1 -m set --match-set "doorman-$vip" src,dst -s "$vip" -j SNAT --to-source "$magic"
 	fi
 	;;
+create6)
+	# ipv6 sets are named after the ipv4 vpn ip too, ipset names are limited to 31 characters
+	if ipset -q list "doorman6-$vip"; then
+		echo "doorman6-$vip already exists!" >&2
+		exit 1
+	fi
+	ipset create "doorman6-$vip" hash:net,net family inet6
+	;;
+add6)
+	vip6=$3 # vpn ipv6
+	cnet=$4 # client private ipv6 subnet
+	ipset add "doorman6-$vip" "$vip6,$cnet"
+	ipset add "doorman6-$vip" "$cnet,$vip6"
+	;;
+enable6)
+	if ip6tables-save | grep -qw "$vip"; then
+		echo "$vip already exists in ip6tables!" >&2
+		ip6tables-save | grep -E '(^\*|\b'"$vip"'\b)' >&2
+		exit 1
+	fi
+
+	trap do_undo6 ERR
+
+	vip6=$3 # vpn ipv6
+	ip6tables -t filter -A DOORMAN_FORWARD -m set --match-set "doorman6-$vip" src,dst -j ACCEPT
+	ip6tables -t nat -I DOORMAN_POSTROUTING 1 -m set --match-set "doorman6-$vip" src,dst -s "$vip6" -j MASQUERADE
+	;;
 *)
 	echo "unknown action: $action" >&2
 	exit 1
@@ -8,9 +8,15 @@ vip=$2 # vpn ip
 case $action in
 disable)
 	iptables-save | grep -vw "$vip" | iptables-restore
+	ip6tables-save | grep -vw "$vip" | ip6tables-restore
 	;;
 delete)
-	ipset destroy "doorman-$vip"
+	if ipset -q list "doorman-$vip" >/dev/null; then
+		ipset destroy "doorman-$vip"
+	fi
+	if ipset -q list "doorman6-$vip" >/dev/null; then
+		ipset destroy "doorman6-$vip"
+	fi
 	;;
 *)
 	echo "unknown action: $action" >&2
@@ -1,4 +1,11 @@
 #!/usr/bin/env bash
+# usage: fw-init.sh [ipv6]
+# ip6tables is only set up when doorman hands out ipv6 addresses, i.e. when called with ipv6.
+
+ipv6=false
+if [ "$1" = "ipv6" ]; then
+	ipv6=true
+fi
 
 set -v
 
@@ -9,6 +16,14 @@ iptables -t filter -X DOORMAN_FORWARD
 iptables -t nat -F DOORMAN_POSTROUTING
 iptables -t nat -D POSTROUTING -j DOORMAN_POSTROUTING
 iptables -t nat -X DOORMAN_POSTROUTING
+if $ipv6; then
+	ip6tables -t filter -F DOORMAN_FORWARD
+	ip6tables -t filter -D FORWARD -j DOORMAN_FORWARD
+	ip6tables -t filter -X DOORMAN_FORWARD
+	ip6tables -t nat -F DOORMAN_POSTROUTING
+	ip6tables -t nat -D POSTROUTING -j DOORMAN_POSTROUTING
+	ip6tables -t nat -X DOORMAN_POSTROUTING
+fi
 
 set -e
 # setup chains and filters
@@ -17,8 +32,15 @@ iptables -t filter -N DOORMAN_FORWARD
 iptables -t filter -I FORWARD -j DOORMAN_FORWARD
 iptables -t nat -N DOORMAN_POSTROUTING
 iptables -t nat -I POSTROUTING -j DOORMAN_POSTROUTING
+if $ipv6; then
+	ip6tables -t filter -P FORWARD DROP
+	ip6tables -t filter -N DOORMAN_FORWARD
+	ip6tables -t filter -I FORWARD -j DOORMAN_FORWARD
+	ip6tables -t nat -N DOORMAN_POSTROUTING
+	ip6tables -t nat -I POSTROUTING -j DOORMAN_POSTROUTING
+fi
 
 # reset all doorman ipsets
-ipset list -n | grep doorman- | while read -r set; do
+ipset list -n | grep -E '^doorman6?-' | while read -r set; do
 	ipset destroy "$set"
 done
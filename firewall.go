@@ -0,0 +1,495 @@
+package doorman
+
+import (
+	"bufio"
+	"fmt"
+	"math/big"
+	"net"
+	"sort"
+	"strings"
+
+	pb "github.com/equinix/doorman/protobuf"
+	"github.com/pkg/errors"
+)
+
+const (
+	firewallNetlink  = "netlink"
+	firewallNftables = "nftables"
+	firewallScript   = "script"
+)
+
+const (
+	doormanForwardChain     = "DOORMAN_FORWARD"
+	doormanPostroutingChain = "DOORMAN_POSTROUTING"
+)
+
+const (
+	commandInit  = "/app/fw-init.sh"
+	commandInit6 = "/app/fw-init.sh ipv6"
+
+	commandCreate = "/app/fw-add.sh create %s"
+	commandAdd    = "/app/fw-add.sh add %s %s"
+	commandEnable = "/app/fw-add.sh enable %s %s"
+
+	commandCreate6 = "/app/fw-add.sh create6 %s"
+	commandAdd6    = "/app/fw-add.sh add6 %s %s %s"
+	commandEnable6 = "/app/fw-add.sh enable6 %s %s"
+
+	commandDisable = "/app/fw-del.sh disable %s %s"
+	commandDelete  = "/app/fw-del.sh delete %s"
+)
+
+// Firewall limits vpn clients to the private networks of their projects.
+// Every client gets a set of (vpn address, network) pairs per address family it is allowed to talk to,
+// the rules matching on the sets are only added once the sets are filled.
+type Firewall interface {
+	// Init removes all of doorman's rules and sets, and sets up the chains doorman's rules go into.
+	Init() error
+	// CreateSet creates the empty sets of the client the address is allocated to.
+	CreateSet(allocation *pb.Allocation) error
+	// AddRoute allows traffic between the client and network.
+	AddRoute(allocation *pb.Allocation, network *net.IPNet) error
+	// Enable adds the rules matching on the client's sets.
+	Enable(allocation *pb.Allocation) error
+	// Disable removes the rules of the client.
+	Disable(allocation *pb.Allocation) error
+	// DeleteSet destroys the client's sets, the rules using them need to be removed with Disable first.
+	DeleteSet(allocation *pb.Allocation) error
+	// State returns what is actually set up, which may have drifted from what doorman set up.
+	State() (*firewallState, error)
+	// Remove removes whatever is left of the client's rules and sets, parts that are already gone are skipped.
+	Remove(allocation *pb.Allocation) error
+}
+
+// firewallState is what a firewall has set up.
+type firewallState struct {
+	ready   bool                       // doorman's chains are in place, clients' rules are useless otherwise
+	clients map[string]*firewallClient // vpn ip -> client
+}
+
+// firewallClient is what a firewall has set up for one client.
+type firewallClient struct {
+	ip      string
+	ipv6    string   // empty if unknown
+	routes  []string // networks the client may reach, normalized with normalizeRoutes
+	enabled bool     // all of the client's rules are in place
+}
+
+// newFirewall returns the firewall backend called kind. pools are the vpn pools, ipv4 and ipv6.
+func newFirewall(kind, magicIP string, pools []*net.IPNet, shell func(cmd string) (string, string, error)) (Firewall, error) {
+	ipv6 := false
+	for _, pool := range pools {
+		ipv6 = ipv6 || pool.IP.To4() == nil
+	}
+
+	switch kind {
+	case "", firewallNetlink:
+		return newNetlinkFirewall(magicIP, ipv6)
+	case firewallNftables:
+		return newNftablesFirewall(magicIP, pools), nil
+	case firewallScript:
+		return &scriptFirewall{magicIP: magicIP, ipv6: ipv6, shell: shell}, nil
+	default:
+		return nil, errors.Errorf("unknown firewall %q, expecting %s, %s or %s", kind, firewallNetlink, firewallNftables, firewallScript)
+	}
+}
+
+// setName returns the name of the client's set, ipv6 sets are named after the ipv4 address too since ipv6 addresses
+// make for names longer than the 31 characters ipset allows.
+func setName(allocation *pb.Allocation, ipv6 bool) string {
+	if ipv6 {
+		return "doorman6-" + allocation.Ip
+	}
+	return "doorman-" + allocation.Ip
+}
+
+// addrRange is an inclusive range of addresses of one address family.
+type addrRange struct {
+	first, last *big.Int
+	bits        int // 32 or 128
+}
+
+func networkRange(network *net.IPNet) addrRange {
+	ip := network.IP.To4()
+	if ip == nil {
+		ip = network.IP.To16()
+	}
+	ones, bits := network.Mask.Size()
+	first := new(big.Int).SetBytes(ip.Mask(network.Mask))
+	size := new(big.Int).Lsh(big.NewInt(1), uint(bits-ones))
+	last := new(big.Int).Sub(new(big.Int).Add(first, size), big.NewInt(1))
+	return addrRange{first: first, last: last, bits: bits}
+}
+
+func ipRange(first, last net.IP) addrRange {
+	bits := 128
+	if first.To4() != nil {
+		first, last, bits = first.To4(), last.To4(), 32
+	}
+	return addrRange{first: new(big.Int).SetBytes(first), last: new(big.Int).SetBytes(last), bits: bits}
+}
+
+// normalizeRoutes merges overlapping and adjacent ranges and returns the fewest networks covering them, sorted,
+// so routes compare equal no matter how a firewall stores them.
+func normalizeRoutes(ranges []addrRange) []string {
+	sort.Slice(ranges, func(i, j int) bool {
+		if ranges[i].bits != ranges[j].bits {
+			return ranges[i].bits < ranges[j].bits
+		}
+		return ranges[i].first.Cmp(ranges[j].first) < 0
+	})
+
+	var merged []addrRange
+	one := big.NewInt(1)
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && merged[n-1].bits == r.bits {
+			prev := &merged[n-1]
+			if new(big.Int).Add(prev.last, one).Cmp(r.first) >= 0 {
+				if r.last.Cmp(prev.last) > 0 {
+					prev.last = r.last
+				}
+				continue
+			}
+		}
+		merged = append(merged, addrRange{first: new(big.Int).Set(r.first), last: r.last, bits: r.bits})
+	}
+
+	routes := []string{}
+	for _, r := range merged {
+		for start := r.first; start.Cmp(r.last) <= 0; {
+			// the largest network starting at start that does not go past the end of the range
+			size := int(start.TrailingZeroBits())
+			if start.Sign() == 0 || size > r.bits {
+				size = r.bits
+			}
+			for ; size > 0; size-- {
+				end := new(big.Int).Add(start, new(big.Int).Lsh(one, uint(size)))
+				if end.Sub(end, one).Cmp(r.last) <= 0 {
+					break
+				}
+			}
+
+			ip := make(net.IP, r.bits/8)
+			start.FillBytes(ip)
+			routes = append(routes, (&net.IPNet{IP: ip, Mask: net.CIDRMask(r.bits-size, r.bits)}).String())
+			start = new(big.Int).Add(start, new(big.Int).Lsh(one, uint(size)))
+		}
+	}
+	return routes
+}
+
+// ipsetEntry is an entry of a hash:net,net ipset.
+type ipsetEntry struct {
+	src, dst *net.IPNet
+}
+
+// iptablesState returns the clients of the netlink and script firewalls, which manage the same ipsets and rules.
+// sets are doorman's ipsets by name, rules are the rules of doorman's chains of both families as iptables -S
+// prints them.
+func iptablesState(sets map[string][]ipsetEntry, rules []string, magicIP string) map[string]*firewallClient {
+	clients := map[string]*firewallClient{}
+	for name, entries := range sets {
+		ipv6 := strings.HasPrefix(name, "doorman6-")
+		ip := strings.TrimPrefix(strings.TrimPrefix(name, "doorman6-"), "doorman-")
+		client, ok := clients[ip]
+		if !ok {
+			client = &firewallClient{ip: ip, enabled: true}
+			clients[ip] = client
+		}
+
+		var forward, postrouting bool
+		vip := ip
+		for _, rule := range rules {
+			fields := strings.Fields(rule)
+			if !matchesSet(fields, name) {
+				continue
+			}
+			switch {
+			case len(fields) > 1 && fields[1] == doormanForwardChain:
+				forward = forward || strings.HasSuffix(rule, " -j ACCEPT")
+			case len(fields) > 1 && fields[1] == doormanPostroutingChain:
+				source := ruleSource(fields)
+				if ipv6 {
+					vip = source
+					postrouting = postrouting || strings.HasSuffix(rule, " -j MASQUERADE")
+				} else if source == ip {
+					if magicIP == "" {
+						postrouting = postrouting || strings.HasSuffix(rule, " -j MASQUERADE")
+					} else {
+						postrouting = postrouting || strings.HasSuffix(rule, " -j SNAT --to-source "+magicIP)
+					}
+				}
+			}
+		}
+		if ipv6 {
+			client.ipv6 = vip
+		}
+		client.enabled = client.enabled && forward && postrouting
+
+		// every route is in the set in both directions
+		from, to := map[string]bool{}, map[string]bool{}
+		var ranges []addrRange
+		for _, entry := range entries {
+			switch {
+			case entry.src.IP.String() == vip && isHost(entry.src):
+				from[entry.dst.String()] = true
+				ranges = append(ranges, networkRange(entry.dst))
+			case entry.dst.IP.String() == vip && isHost(entry.dst):
+				to[entry.src.String()] = true
+				ranges = append(ranges, networkRange(entry.src))
+			default:
+				client.enabled = false
+			}
+		}
+		if len(from) != len(to) {
+			client.enabled = false
+		}
+		for network := range from {
+			client.enabled = client.enabled && to[network]
+		}
+		client.routes = normalizeRoutes(append(ranges, routeRanges(client.routes)...))
+	}
+	for _, client := range clients {
+		// an ipv4 set is always created, so are the ipv4 rules
+		if _, ok := sets[setName(&pb.Allocation{Ip: client.ip}, false)]; !ok {
+			client.enabled = false
+		}
+	}
+	return clients
+}
+
+// matchesSet returns whether the rule's fields match on set.
+func matchesSet(fields []string, set string) bool {
+	for i := 0; i+1 < len(fields); i++ {
+		if fields[i] == "--match-set" && fields[i+1] == set {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleSource returns the address the rule's fields match as source.
+func ruleSource(fields []string) string {
+	for i := 0; i+1 < len(fields); i++ {
+		if fields[i] == "-s" {
+			ip, _, err := net.ParseCIDR(fields[i+1])
+			if err != nil {
+				return fields[i+1]
+			}
+			return ip.String()
+		}
+	}
+	return ""
+}
+
+func isHost(network *net.IPNet) bool {
+	ones, bits := network.Mask.Size()
+	return ones == bits
+}
+
+// routeRanges parses normalized routes back into ranges.
+func routeRanges(routes []string) []addrRange {
+	var ranges []addrRange
+	for _, route := range routes {
+		if _, network, err := net.ParseCIDR(route); err == nil {
+			ranges = append(ranges, networkRange(network))
+		}
+	}
+	return ranges
+}
+
+// iptablesHooked returns whether the FORWARD and POSTROUTING rules of iptables -S jump to doorman's chains.
+func iptablesHooked(forward, postrouting []string) bool {
+	return containsLine(forward, "-A FORWARD -j "+doormanForwardChain) &&
+		containsLine(postrouting, "-A POSTROUTING -j "+doormanPostroutingChain)
+}
+
+func containsLine(lines []string, line string) bool {
+	for _, l := range lines {
+		if strings.TrimSpace(l) == line {
+			return true
+		}
+	}
+	return false
+}
+
+// scriptFirewall is the firewall implemented by the fw-*.sh scripts.
+type scriptFirewall struct {
+	magicIP string
+	ipv6    bool
+	shell   func(cmd string) (string, string, error)
+}
+
+func (f *scriptFirewall) run(cmds ...string) error {
+	for _, cmd := range cmds {
+		if stdout, stderr, err := f.shell(cmd); err != nil {
+			return errors.WithMessagef(err, "%s: %s", cmd, strings.TrimSpace(stdout+"\n"+stderr))
+		}
+	}
+	return nil
+}
+
+// Init only sets up ip6tables when clients get ipv6 addresses, hosts may have no ipv6 netfilter at all.
+func (f *scriptFirewall) Init() error {
+	if f.ipv6 {
+		return f.run(commandInit6)
+	}
+	return f.run(commandInit)
+}
+
+func (f *scriptFirewall) CreateSet(allocation *pb.Allocation) error {
+	if err := f.run(fmt.Sprintf(commandCreate, allocation.Ip)); err != nil {
+		return err
+	}
+	if allocation.Ipv6 == "" {
+		return nil
+	}
+	if err := f.run(fmt.Sprintf(commandCreate6, allocation.Ip)); err != nil {
+		f.run(fmt.Sprintf(commandDelete, allocation.Ip))
+		return err
+	}
+	return nil
+}
+
+func (f *scriptFirewall) AddRoute(allocation *pb.Allocation, network *net.IPNet) error {
+	if network.IP.To4() == nil {
+		return f.run(fmt.Sprintf(commandAdd6, allocation.Ip, allocation.Ipv6, network))
+	}
+	return f.run(fmt.Sprintf(commandAdd, allocation.Ip, network))
+}
+
+func (f *scriptFirewall) Enable(allocation *pb.Allocation) error {
+	if err := f.run(fmt.Sprintf(commandEnable, allocation.Ip, f.magicIP)); err != nil {
+		return err
+	}
+	if allocation.Ipv6 == "" {
+		return nil
+	}
+	if err := f.run(fmt.Sprintf(commandEnable6, allocation.Ip, allocation.Ipv6)); err != nil {
+		f.Disable(allocation)
+		return err
+	}
+	return nil
+}
+
+func (f *scriptFirewall) Disable(allocation *pb.Allocation) error {
+	return f.run(fmt.Sprintf(commandDisable, allocation.Ip, f.magicIP))
+}
+
+func (f *scriptFirewall) DeleteSet(allocation *pb.Allocation) error {
+	return f.run(fmt.Sprintf(commandDelete, allocation.Ip))
+}
+
+// Remove relies on fw-del.sh skipping rules and sets that do not exist.
+func (f *scriptFirewall) Remove(allocation *pb.Allocation) error {
+	return f.run(fmt.Sprintf(commandDisable, allocation.Ip, f.magicIP), fmt.Sprintf(commandDelete, allocation.Ip))
+}
+
+func (f *scriptFirewall) lines(cmd string) ([]string, error) {
+	stdout, stderr, err := f.shell(cmd)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "%s: %s", cmd, strings.TrimSpace(stdout+"\n"+stderr))
+	}
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(stdout))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, nil
+}
+
+func (f *scriptFirewall) State() (*firewallState, error) {
+	state := &firewallState{ready: true, clients: map[string]*firewallClient{}}
+
+	commands := []string{"iptables"}
+	if f.ipv6 {
+		commands = append(commands, "ip6tables")
+	}
+	var rules []string
+	for _, command := range commands {
+		forward, err := f.lines(command + " -t filter -S FORWARD")
+		if err != nil {
+			return nil, err
+		}
+		postrouting, err := f.lines(command + " -t nat -S POSTROUTING")
+		if err != nil {
+			return nil, err
+		}
+		if !iptablesHooked(forward, postrouting) {
+			state.ready = false
+			return state, nil
+		}
+
+		// listing fails if the chains are gone
+		for _, cmd := range []string{command + " -t filter -S " + doormanForwardChain, command + " -t nat -S " + doormanPostroutingChain} {
+			lines, err := f.lines(cmd)
+			if err != nil {
+				state.ready = false
+				return state, nil
+			}
+			rules = append(rules, lines...)
+		}
+	}
+
+	save, err := f.lines("ipset save")
+	if err != nil {
+		return nil, err
+	}
+	sets, err := parseIPSetSave(save)
+	if err != nil {
+		return nil, err
+	}
+	state.clients = iptablesState(sets, rules, f.magicIP)
+	return state, nil
+}
+
+// parseIPSetSave returns doorman's ipsets from the output of ipset save.
+func parseIPSetSave(lines []string) (map[string][]ipsetEntry, error) {
+	sets := map[string][]ipsetEntry{}
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.HasPrefix(fields[1], "doorman-") && !strings.HasPrefix(fields[1], "doorman6-") {
+			continue
+		}
+		switch fields[0] {
+		case "create":
+			if _, ok := sets[fields[1]]; !ok {
+				sets[fields[1]] = nil
+			}
+		case "add":
+			if len(fields) < 3 {
+				return nil, errors.Errorf("parse ipset entry %q", line)
+			}
+			nets := strings.SplitN(fields[2], ",", 2)
+			if len(nets) != 2 {
+				return nil, errors.Errorf("parse ipset entry %q", line)
+			}
+			src, err := parseHostOrCIDR(nets[0])
+			if err != nil {
+				return nil, errors.WithMessagef(err, "parse ipset entry %q", line)
+			}
+			dst, err := parseHostOrCIDR(nets[1])
+			if err != nil {
+				return nil, errors.WithMessagef(err, "parse ipset entry %q", line)
+			}
+			sets[fields[1]] = append(sets[fields[1]], ipsetEntry{src: src, dst: dst})
+		}
+	}
+	return sets, nil
+}
+
+// parseHostOrCIDR parses a network, addresses without a prefix length are host networks.
+func parseHostOrCIDR(s string) (*net.IPNet, error) {
+	if !strings.Contains(s, "/") {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, errors.Errorf("invalid address %q", s)
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			ip = ip4
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)}, nil
+	}
+	_, network, err := net.ParseCIDR(s)
+	return network, errors.Wrapf(err, "invalid network %q", s)
+}
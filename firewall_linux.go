@@ -0,0 +1,303 @@
+package doorman
+
+import (
+	"net"
+	"strings"
+
+	"github.com/coreos/go-iptables/iptables"
+	pb "github.com/equinix/doorman/protobuf"
+	"github.com/pkg/errors"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// netlinkFirewall manages the ipsets over netlink and the iptables rules with iptables' own commands,
+// without going through a shell.
+type netlinkFirewall struct {
+	magicIP string
+	ipt     *iptables.IPTables
+	ip6t    *iptables.IPTables // nil unless ipv6 is enabled
+}
+
+func newNetlinkFirewall(magicIP string, ipv6 bool) (Firewall, error) {
+	ipt, err := iptables.NewWithProtocol(iptables.ProtocolIPv4)
+	if err != nil {
+		return nil, errors.Wrap(err, "setup iptables")
+	}
+	f := &netlinkFirewall{magicIP: magicIP, ipt: ipt}
+	if ipv6 {
+		f.ip6t, err = iptables.NewWithProtocol(iptables.ProtocolIPv6)
+		if err != nil {
+			return nil, errors.Wrap(err, "setup ip6tables")
+		}
+	}
+	return f, nil
+}
+
+func (f *netlinkFirewall) tables() []*iptables.IPTables {
+	if f.ip6t == nil {
+		return []*iptables.IPTables{f.ipt}
+	}
+	return []*iptables.IPTables{f.ipt, f.ip6t}
+}
+
+func (f *netlinkFirewall) Init() error {
+	for _, ipt := range f.tables() {
+		// ensure clean slate
+		if err := ipt.DeleteIfExists("filter", "FORWARD", "-j", doormanForwardChain); err != nil {
+			return errors.Wrap(err, "remove forward jump")
+		}
+		if err := ipt.ClearAndDeleteChain("filter", doormanForwardChain); err != nil {
+			return errors.Wrap(err, "remove forward chain")
+		}
+		if err := ipt.DeleteIfExists("nat", "POSTROUTING", "-j", doormanPostroutingChain); err != nil {
+			return errors.Wrap(err, "remove postrouting jump")
+		}
+		if err := ipt.ClearAndDeleteChain("nat", doormanPostroutingChain); err != nil {
+			return errors.Wrap(err, "remove postrouting chain")
+		}
+
+		// setup chains and filters
+		if err := ipt.ChangePolicy("filter", "FORWARD", "DROP"); err != nil {
+			return errors.Wrap(err, "set forward policy")
+		}
+		if err := ipt.NewChain("filter", doormanForwardChain); err != nil {
+			return errors.Wrap(err, "create forward chain")
+		}
+		if err := ipt.Insert("filter", "FORWARD", 1, "-j", doormanForwardChain); err != nil {
+			return errors.Wrap(err, "add forward jump")
+		}
+		if err := ipt.NewChain("nat", doormanPostroutingChain); err != nil {
+			return errors.Wrap(err, "create postrouting chain")
+		}
+		if err := ipt.Insert("nat", "POSTROUTING", 1, "-j", doormanPostroutingChain); err != nil {
+			return errors.Wrap(err, "add postrouting jump")
+		}
+	}
+
+	// reset all doorman ipsets
+	sets, err := netlink.IpsetListAll()
+	if err != nil {
+		return errors.Wrap(err, "list ipsets")
+	}
+	for _, set := range sets {
+		if !strings.HasPrefix(set.SetName, "doorman-") && !strings.HasPrefix(set.SetName, "doorman6-") {
+			continue
+		}
+		if err := netlink.IpsetDestroy(set.SetName); err != nil {
+			return errors.Wrapf(err, "destroy ipset %s", set.SetName)
+		}
+	}
+	return nil
+}
+
+func (f *netlinkFirewall) CreateSet(allocation *pb.Allocation) error {
+	name := setName(allocation, false)
+	if err := netlink.IpsetCreate(name, "hash:net,net", netlink.IpsetCreateOptions{Family: unix.AF_INET}); err != nil {
+		return errors.Wrapf(err, "create ipset %s", name)
+	}
+	if allocation.Ipv6 == "" || f.ip6t == nil {
+		return nil
+	}
+
+	name6 := setName(allocation, true)
+	if err := netlink.IpsetCreate(name6, "hash:net,net", netlink.IpsetCreateOptions{Family: unix.AF_INET6}); err != nil {
+		netlink.IpsetDestroy(name)
+		return errors.Wrapf(err, "create ipset %s", name6)
+	}
+	return nil
+}
+
+func (f *netlinkFirewall) AddRoute(allocation *pb.Allocation, network *net.IPNet) error {
+	vip := net.ParseIP(allocation.Ip).To4()
+	name := setName(allocation, false)
+	if network.IP.To4() == nil {
+		vip = net.ParseIP(allocation.Ipv6)
+		name = setName(allocation, true)
+	}
+	if vip == nil {
+		return errors.Errorf("no vpn address to route %s to", network)
+	}
+
+	hostBits := uint8(len(vip) * 8)
+	ones, _ := network.Mask.Size()
+	entries := []*netlink.IPSetEntry{
+		{IP: vip, CIDR: hostBits, IP2: network.IP, CIDR2: uint8(ones)},
+		{IP: network.IP, CIDR: uint8(ones), IP2: vip, CIDR2: hostBits},
+	}
+	for _, entry := range entries {
+		if err := netlink.IpsetAdd(name, entry); err != nil {
+			return errors.Wrapf(err, "add %s,%s/%d to ipset %s", entry.IP, entry.IP2, entry.CIDR2, name)
+		}
+	}
+	return nil
+}
+
+// forwardRule accepts traffic matching the set.
+func forwardRule(set string) []string {
+	return []string{"-m", "set", "--match-set", set, "src,dst", "-j", "ACCEPT"}
+}
+
+// postroutingRule source nats traffic from vip matching the set to the magic ip, or the outgoing interface's
+// address if there is none.
+func postroutingRule(set, vip, magicIP string) []string {
+	rule := []string{"-m", "set", "--match-set", set, "src,dst", "-s", vip}
+	if magicIP == "" {
+		return append(rule, "-j", "MASQUERADE")
+	}
+	return append(rule, "-j", "SNAT", "--to-source", magicIP)
+}
+
+func (f *netlinkFirewall) Enable(allocation *pb.Allocation) error {
+	set := setName(allocation, false)
+	if err := f.enable(f.ipt, set, postroutingRule(set, allocation.Ip, f.magicIP)); err != nil {
+		return err
+	}
+	if allocation.Ipv6 == "" || f.ip6t == nil {
+		return nil
+	}
+
+	set6 := setName(allocation, true)
+	if err := f.enable(f.ip6t, set6, postroutingRule(set6, allocation.Ipv6, "")); err != nil {
+		f.Disable(allocation)
+		return err
+	}
+	return nil
+}
+
+func (f *netlinkFirewall) enable(ipt *iptables.IPTables, set string, postrouting []string) error {
+	if err := ipt.Append("filter", doormanForwardChain, forwardRule(set)...); err != nil {
+		return errors.Wrapf(err, "add forward rule for %s", set)
+	}
+	if err := ipt.Insert("nat", doormanPostroutingChain, 1, postrouting...); err != nil {
+		ipt.DeleteIfExists("filter", doormanForwardChain, forwardRule(set)...)
+		return errors.Wrapf(err, "add postrouting rule for %s", set)
+	}
+	return nil
+}
+
+func (f *netlinkFirewall) Disable(allocation *pb.Allocation) error {
+	set := setName(allocation, false)
+	err := f.disable(f.ipt, set, postroutingRule(set, allocation.Ip, f.magicIP))
+	if allocation.Ipv6 != "" && f.ip6t != nil {
+		set6 := setName(allocation, true)
+		if err6 := f.disable(f.ip6t, set6, postroutingRule(set6, allocation.Ipv6, "")); err == nil {
+			err = err6
+		}
+	}
+	return err
+}
+
+func (f *netlinkFirewall) disable(ipt *iptables.IPTables, set string, postrouting []string) error {
+	if err := ipt.DeleteIfExists("filter", doormanForwardChain, forwardRule(set)...); err != nil {
+		return errors.Wrapf(err, "remove forward rule for %s", set)
+	}
+	if err := ipt.DeleteIfExists("nat", doormanPostroutingChain, postrouting...); err != nil {
+		return errors.Wrapf(err, "remove postrouting rule for %s", set)
+	}
+	return nil
+}
+
+func (f *netlinkFirewall) DeleteSet(allocation *pb.Allocation) error {
+	name := setName(allocation, false)
+	if err := netlink.IpsetDestroy(name); err != nil {
+		return errors.Wrapf(err, "destroy ipset %s", name)
+	}
+	if allocation.Ipv6 == "" || f.ip6t == nil {
+		return nil
+	}
+
+	name6 := setName(allocation, true)
+	if err := netlink.IpsetDestroy(name6); err != nil {
+		return errors.Wrapf(err, "destroy ipset %s", name6)
+	}
+	return nil
+}
+
+func (f *netlinkFirewall) State() (*firewallState, error) {
+	state := &firewallState{ready: true, clients: map[string]*firewallClient{}}
+
+	var rules []string
+	for _, ipt := range f.tables() {
+		forward, err := ipt.List("filter", "FORWARD")
+		if err != nil {
+			return nil, errors.Wrap(err, "list forward rules")
+		}
+		postrouting, err := ipt.List("nat", "POSTROUTING")
+		if err != nil {
+			return nil, errors.Wrap(err, "list postrouting rules")
+		}
+		if !iptablesHooked(forward, postrouting) {
+			state.ready = false
+			return state, nil
+		}
+
+		for _, chain := range []struct{ table, chain string }{{"filter", doormanForwardChain}, {"nat", doormanPostroutingChain}} {
+			exists, err := ipt.ChainExists(chain.table, chain.chain)
+			if err != nil {
+				return nil, errors.Wrapf(err, "check chain %s", chain.chain)
+			}
+			if !exists {
+				state.ready = false
+				return state, nil
+			}
+			lines, err := ipt.List(chain.table, chain.chain)
+			if err != nil {
+				return nil, errors.Wrapf(err, "list %s rules", chain.chain)
+			}
+			rules = append(rules, lines...)
+		}
+	}
+
+	all, err := netlink.IpsetListAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "list ipsets")
+	}
+	sets := map[string][]ipsetEntry{}
+	for _, set := range all {
+		if !strings.HasPrefix(set.SetName, "doorman-") && !strings.HasPrefix(set.SetName, "doorman6-") {
+			continue
+		}
+		result, err := netlink.IpsetList(set.SetName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "list ipset %s", set.SetName)
+		}
+		entries := []ipsetEntry{}
+		for _, entry := range result.Entries {
+			entries = append(entries, ipsetEntry{src: entryNetwork(entry.IP, entry.CIDR), dst: entryNetwork(entry.IP2, entry.CIDR2)})
+		}
+		sets[set.SetName] = entries
+	}
+	state.clients = iptablesState(sets, rules, f.magicIP)
+	return state, nil
+}
+
+func entryNetwork(ip net.IP, cidr uint8) *net.IPNet {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(int(cidr), len(ip)*8)}
+}
+
+func (f *netlinkFirewall) Remove(allocation *pb.Allocation) error {
+	if err := f.Disable(allocation); err != nil {
+		return err
+	}
+
+	names := []string{setName(allocation, false), setName(allocation, true)}
+	sets, err := netlink.IpsetListAll()
+	if err != nil {
+		return errors.Wrap(err, "list ipsets")
+	}
+	for _, set := range sets {
+		for _, name := range names {
+			if set.SetName != name {
+				continue
+			}
+			if err := netlink.IpsetDestroy(name); err != nil {
+				return errors.Wrapf(err, "destroy ipset %s", name)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,500 @@
+package doorman
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+	"sync"
+
+	pb "github.com/equinix/doorman/protobuf"
+	"github.com/pkg/errors"
+)
+
+const nftablesTable = "inet doorman"
+
+// nftablesInit replaces doorman's table, the dummy table declaration makes the delete work on a fresh host.
+// Clients are looked up by vpn address in the verdict maps, which jump to the client's own chains. The forward
+// chain accepts by default so the host's other traffic is left to the rest of the ruleset, only traffic from or
+// to the vpn pools that no client's chain accepted is dropped.
+const nftablesInit = `table inet doorman
+delete table inet doorman
+table inet doorman {
+	map clients4 { type ipv4_addr : verdict; }
+	map clients6 { type ipv6_addr : verdict; }
+	map nat4 { type ipv4_addr : verdict; }
+	map nat6 { type ipv6_addr : verdict; }
+	set pools4 { type ipv4_addr; flags interval; }
+	set pools6 { type ipv6_addr; flags interval; }
+
+	chain forward {
+		type filter hook forward priority 0; policy accept;
+		ip saddr vmap @clients4
+		ip daddr vmap @clients4
+		ip6 saddr vmap @clients6
+		ip6 daddr vmap @clients6
+		ip saddr @pools4 drop
+		ip daddr @pools4 drop
+		ip6 saddr @pools6 drop
+		ip6 daddr @pools6 drop
+	}
+
+	chain postrouting {
+		type nat hook postrouting priority 100;
+		ip saddr vmap @nat4
+		ip6 saddr vmap @nat6
+	}
+}
+`
+
+// nftablesFirewall keeps all of doorman's rules in its own table, the rest of the ruleset is never touched.
+// Every change is applied as a single nft transaction: the sets and routes of a client are only collected
+// until Enable adds the client, and Disable removes all of it.
+type nftablesFirewall struct {
+	magicIP string
+	pools   []*net.IPNet // vpn pools, ipv4 and ipv6
+	nft     func(script string) error
+	list    func() ([]byte, error)
+
+	mu      sync.Mutex
+	clients map[string]*nftablesClient // vpn ip -> client
+}
+
+type nftablesClient struct {
+	allocation *pb.Allocation
+	routes     []*net.IPNet
+	enabled    bool
+}
+
+func newNftablesFirewall(magicIP string, pools []*net.IPNet) *nftablesFirewall {
+	return &nftablesFirewall{
+		magicIP: magicIP,
+		pools:   pools,
+		nft:     runNft,
+		list:    listNft,
+		clients: map[string]*nftablesClient{},
+	}
+}
+
+// runNft applies script as one transaction.
+func runNft(script string) error {
+	cmd := exec.Command("nft", "-f", "-")
+	cmd.Stdin = strings.NewReader(script)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return errors.Wrapf(err, "nft: %s", strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// listNft returns doorman's table in nft's json format, or nil if there is no such table.
+func listNft() ([]byte, error) {
+	cmd := exec.Command("nft", "-j", "list", "table", "inet", "doorman")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if strings.Contains(stderr.String(), "No such file or directory") {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "nft: %s", strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// nftablesFamily is what differs between a client's ipv4 and ipv6 rules.
+type nftablesFamily struct {
+	version  string // suffix of the maps and sets
+	vip      string
+	addrType string
+	ip       string // address payload expression
+}
+
+// nftablesFamilies returns the address families the client has a vpn address of.
+func nftablesFamilies(allocation *pb.Allocation) []nftablesFamily {
+	families := []nftablesFamily{{version: "4", vip: allocation.Ip, addrType: "ipv4_addr", ip: "ip"}}
+	if allocation.Ipv6 != "" {
+		families = append(families, nftablesFamily{version: "6", vip: allocation.Ipv6, addrType: "ipv6_addr", ip: "ip6"})
+	}
+	return families
+}
+
+// nftablesName returns the name of one of the client's sets or chains, named after the ipv4 vpn address.
+func nftablesName(kind string, allocation *pb.Allocation) string {
+	return kind + "_" + strings.Replace(allocation.Ip, ".", "_", -1)
+}
+
+func (f *nftablesFirewall) Init() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString(nftablesInit)
+	for _, pool := range f.pools {
+		version := "6"
+		if pool.IP.To4() != nil {
+			version = "4"
+		}
+		fmt.Fprintf(&b, "add element %s pools%s { %s }\n", nftablesTable, version, pool)
+	}
+	if err := f.nft(b.String()); err != nil {
+		return errors.WithMessage(err, "create nftables table")
+	}
+	f.clients = map[string]*nftablesClient{}
+	return nil
+}
+
+func (f *nftablesFirewall) CreateSet(allocation *pb.Allocation) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.clients[allocation.Ip]; ok {
+		return errors.Errorf("nftables sets for %s already exist", allocation.Ip)
+	}
+	f.clients[allocation.Ip] = &nftablesClient{allocation: allocation}
+	return nil
+}
+
+func (f *nftablesFirewall) AddRoute(allocation *pb.Allocation, network *net.IPNet) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	client, ok := f.clients[allocation.Ip]
+	if !ok {
+		return errors.Errorf("nftables sets for %s do not exist", allocation.Ip)
+	}
+	if client.enabled {
+		return errors.Errorf("nftables rules for %s are already enabled", allocation.Ip)
+	}
+	if network.IP.To4() == nil && allocation.Ipv6 == "" {
+		return errors.Errorf("no vpn ipv6 address to route %s to", network)
+	}
+	client.routes = append(client.routes, network)
+	return nil
+}
+
+func (f *nftablesFirewall) Enable(allocation *pb.Allocation) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	client, ok := f.clients[allocation.Ip]
+	if !ok {
+		return errors.Errorf("nftables sets for %s do not exist", allocation.Ip)
+	}
+	if client.enabled {
+		return nil
+	}
+	if err := f.nft(f.enableScript(client)); err != nil {
+		return errors.WithMessagef(err, "add nftables rules for %s", allocation.Ip)
+	}
+	client.enabled = true
+	return nil
+}
+
+func (f *nftablesFirewall) enableScript(client *nftablesClient) string {
+	var routes4, routes6 []string
+	for _, route := range client.routes {
+		if route.IP.To4() == nil {
+			routes6 = append(routes6, route.String())
+		} else {
+			routes4 = append(routes4, route.String())
+		}
+	}
+
+	allocation := client.allocation
+	chain := nftablesName("client", allocation)
+	natChain := nftablesName("nat", allocation)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "add chain %s %s\n", nftablesTable, chain)
+	fmt.Fprintf(&b, "add chain %s %s\n", nftablesTable, natChain)
+
+	for _, family := range nftablesFamilies(allocation) {
+		routes, magic := routes4, f.magicIP
+		if family.version == "6" {
+			// the magic ip is ipv4 only
+			routes, magic = routes6, ""
+		}
+
+		set := nftablesName("nets"+family.version, allocation)
+		fmt.Fprintf(&b, "add set %s %s { type %s; flags interval; auto-merge; }\n", nftablesTable, set, family.addrType)
+		if len(routes) > 0 {
+			fmt.Fprintf(&b, "add element %s %s { %s }\n", nftablesTable, set, strings.Join(routes, ", "))
+		}
+
+		fmt.Fprintf(&b, "add rule %s %s %s saddr %s %s daddr @%s accept\n", nftablesTable, chain, family.ip, family.vip, family.ip, set)
+		fmt.Fprintf(&b, "add rule %s %s %s daddr %s %s saddr @%s accept\n", nftablesTable, chain, family.ip, family.vip, family.ip, set)
+		if magic == "" {
+			fmt.Fprintf(&b, "add rule %s %s %s daddr @%s masquerade\n", nftablesTable, natChain, family.ip, set)
+		} else {
+			fmt.Fprintf(&b, "add rule %s %s %s daddr @%s snat %s to %s\n", nftablesTable, natChain, family.ip, set, family.ip, magic)
+		}
+
+		fmt.Fprintf(&b, "add element %s clients%s { %s : jump %s }\n", nftablesTable, family.version, family.vip, chain)
+		fmt.Fprintf(&b, "add element %s nat%s { %s : jump %s }\n", nftablesTable, family.version, family.vip, natChain)
+	}
+	return b.String()
+}
+
+// Disable removes all of the client's rules, chains and sets. Nothing is left for DeleteSet to do.
+func (f *nftablesFirewall) Disable(allocation *pb.Allocation) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	client, ok := f.clients[allocation.Ip]
+	if !ok || !client.enabled {
+		return nil
+	}
+	if err := f.nft(f.disableScript(client)); err != nil {
+		return errors.WithMessagef(err, "remove nftables rules for %s", allocation.Ip)
+	}
+	client.enabled = false
+	return nil
+}
+
+func (f *nftablesFirewall) disableScript(client *nftablesClient) string {
+	allocation := client.allocation
+	chain := nftablesName("client", allocation)
+	natChain := nftablesName("nat", allocation)
+
+	families := nftablesFamilies(allocation)
+
+	var b strings.Builder
+	for _, family := range families {
+		fmt.Fprintf(&b, "delete element %s clients%s { %s }\n", nftablesTable, family.version, family.vip)
+		fmt.Fprintf(&b, "delete element %s nat%s { %s }\n", nftablesTable, family.version, family.vip)
+	}
+	// the chains need to be empty before they can be deleted, and the sets unreferenced
+	for _, c := range []string{chain, natChain} {
+		fmt.Fprintf(&b, "flush chain %s %s\n", nftablesTable, c)
+		fmt.Fprintf(&b, "delete chain %s %s\n", nftablesTable, c)
+	}
+	for _, family := range families {
+		fmt.Fprintf(&b, "delete set %s %s\n", nftablesTable, nftablesName("nets"+family.version, allocation))
+	}
+	return b.String()
+}
+
+func (f *nftablesFirewall) DeleteSet(allocation *pb.Allocation) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	client, ok := f.clients[allocation.Ip]
+	if !ok {
+		return errors.Errorf("nftables sets for %s do not exist", allocation.Ip)
+	}
+	if client.enabled {
+		return errors.Errorf("nftables rules for %s are still enabled", allocation.Ip)
+	}
+	delete(f.clients, allocation.Ip)
+	return nil
+}
+
+// Remove works whatever is left of the client: adding what exists already is a no-op for nft, and makes sure the
+// deletes that follow have something to delete.
+func (f *nftablesFirewall) Remove(allocation *pb.Allocation) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	chain := nftablesName("client", allocation)
+	natChain := nftablesName("nat", allocation)
+	families := []nftablesFamily{
+		{version: "4", vip: allocation.Ip, addrType: "ipv4_addr"},
+		{version: "6", vip: allocation.Ipv6, addrType: "ipv6_addr"},
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "add chain %s %s\n", nftablesTable, chain)
+	fmt.Fprintf(&b, "add chain %s %s\n", nftablesTable, natChain)
+	for _, family := range families {
+		if family.vip == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "add element %s clients%s { %s : jump %s }\n", nftablesTable, family.version, family.vip, chain)
+		fmt.Fprintf(&b, "delete element %s clients%s { %s }\n", nftablesTable, family.version, family.vip)
+		fmt.Fprintf(&b, "add element %s nat%s { %s : jump %s }\n", nftablesTable, family.version, family.vip, natChain)
+		fmt.Fprintf(&b, "delete element %s nat%s { %s }\n", nftablesTable, family.version, family.vip)
+	}
+	for _, c := range []string{chain, natChain} {
+		fmt.Fprintf(&b, "flush chain %s %s\n", nftablesTable, c)
+		fmt.Fprintf(&b, "delete chain %s %s\n", nftablesTable, c)
+	}
+	for _, family := range families {
+		set := nftablesName("nets"+family.version, allocation)
+		fmt.Fprintf(&b, "add set %s %s { type %s; flags interval; auto-merge; }\n", nftablesTable, set, family.addrType)
+		fmt.Fprintf(&b, "delete set %s %s\n", nftablesTable, set)
+	}
+
+	if err := f.nft(b.String()); err != nil {
+		return errors.WithMessagef(err, "remove nftables rules for %s", allocation.Ip)
+	}
+	delete(f.clients, allocation.Ip)
+	return nil
+}
+
+// nftablesObject is an object of nft's json output, only the fields State looks at are decoded.
+type nftablesObject struct {
+	Chain *struct {
+		Name string `json:"name"`
+	} `json:"chain"`
+	Rule *struct {
+		Chain string `json:"chain"`
+	} `json:"rule"`
+	Set *nftablesSet `json:"set"`
+	Map *nftablesSet `json:"map"`
+}
+
+type nftablesSet struct {
+	Name string            `json:"name"`
+	Elem []json.RawMessage `json:"elem"`
+}
+
+func (f *nftablesFirewall) State() (*firewallState, error) {
+	out, err := f.list()
+	if err != nil {
+		return nil, errors.WithMessage(err, "list nftables table")
+	}
+	state := &firewallState{clients: map[string]*firewallClient{}}
+	if out == nil {
+		return state, nil
+	}
+
+	var table struct {
+		Nftables []nftablesObject `json:"nftables"`
+	}
+	if err := json.Unmarshal(out, &table); err != nil {
+		return nil, errors.Wrap(err, "parse nftables table")
+	}
+
+	chains := map[string]int{} // chain -> number of rules
+	sets := map[string][]addrRange{}
+	maps := map[string]map[string]string{} // map -> address -> chain it jumps to
+	for _, object := range table.Nftables {
+		switch {
+		case object.Chain != nil:
+			if _, ok := chains[object.Chain.Name]; !ok {
+				chains[object.Chain.Name] = 0
+			}
+		case object.Rule != nil:
+			chains[object.Rule.Chain]++
+		case object.Set != nil:
+			ranges := []addrRange{}
+			for _, elem := range object.Set.Elem {
+				r, err := parseNftablesElem(elem)
+				if err != nil {
+					return nil, errors.WithMessagef(err, "parse element of set %s", object.Set.Name)
+				}
+				ranges = append(ranges, r)
+			}
+			sets[object.Set.Name] = ranges
+		case object.Map != nil:
+			elements := map[string]string{}
+			for _, elem := range object.Map.Elem {
+				var pair []json.RawMessage
+				var key string
+				var verdict struct {
+					Jump struct {
+						Target string `json:"target"`
+					} `json:"jump"`
+				}
+				if json.Unmarshal(elem, &pair) != nil || len(pair) != 2 || json.Unmarshal(pair[0], &key) != nil || json.Unmarshal(pair[1], &verdict) != nil {
+					return nil, errors.Errorf("parse element %s of map %s", elem, object.Map.Name)
+				}
+				elements[key] = verdict.Jump.Target
+			}
+			maps[object.Map.Name] = elements
+		}
+	}
+
+	state.ready = chains["forward"] == 8 && chains["postrouting"] == 2
+	for _, m := range []string{"clients4", "clients6", "nat4", "nat6"} {
+		state.ready = state.ready && maps[m] != nil
+	}
+
+	client := func(name string) *firewallClient {
+		ip := strings.Replace(name[strings.Index(name, "_")+1:], "_", ".", -1)
+		c, ok := state.clients[ip]
+		if !ok {
+			c = &firewallClient{ip: ip}
+			state.clients[ip] = c
+		}
+		return c
+	}
+	for chain := range chains {
+		if strings.HasPrefix(chain, "client_") || strings.HasPrefix(chain, "nat_") {
+			client(chain)
+		}
+	}
+	for set := range sets {
+		if strings.HasPrefix(set, "nets4_") || strings.HasPrefix(set, "nets6_") {
+			client(set)
+		}
+	}
+	for _, m := range []string{"clients4", "clients6", "nat4", "nat6"} {
+		for _, target := range maps[m] {
+			client(target)
+		}
+	}
+
+	for ip, c := range state.clients {
+		allocation := &pb.Allocation{Ip: ip}
+		chain := nftablesName("client", allocation)
+		natChain := nftablesName("nat", allocation)
+		for vip6, target := range maps["clients6"] {
+			if target == chain {
+				c.ipv6 = vip6
+			}
+		}
+
+		routes4, ok4 := sets[nftablesName("nets4", allocation)]
+		routes6, ok6 := sets[nftablesName("nets6", allocation)]
+		c.routes = normalizeRoutes(append(append([]addrRange{}, routes4...), routes6...))
+
+		families := 1
+		c.enabled = ok4 && maps["clients4"][ip] == chain && maps["nat4"][ip] == natChain
+		if ok6 || c.ipv6 != "" {
+			families = 2
+			c.enabled = c.enabled && ok6 && c.ipv6 != "" && maps["nat6"][c.ipv6] == natChain
+		}
+		clientRules, okChain := chains[chain]
+		natRules, okNat := chains[natChain]
+		c.enabled = c.enabled && okChain && okNat && clientRules == 2*families && natRules == families
+	}
+	return state, nil
+}
+
+// parseNftablesElem parses a set element, which nft prints as an address, a prefix or a range.
+func parseNftablesElem(elem json.RawMessage) (addrRange, error) {
+	var value struct {
+		Prefix *struct {
+			Addr string `json:"addr"`
+			Len  int    `json:"len"`
+		} `json:"prefix"`
+		Range []string `json:"range"`
+	}
+	var addr string
+	switch {
+	case json.Unmarshal(elem, &addr) == nil:
+		ip := net.ParseIP(addr)
+		if ip == nil {
+			break
+		}
+		return ipRange(ip, ip), nil
+	case json.Unmarshal(elem, &value) == nil && value.Prefix != nil:
+		_, network, err := net.ParseCIDR(fmt.Sprintf("%s/%d", value.Prefix.Addr, value.Prefix.Len))
+		if err != nil {
+			return addrRange{}, errors.Wrapf(err, "parse prefix %s", elem)
+		}
+		return networkRange(network), nil
+	case len(value.Range) == 2:
+		first, last := net.ParseIP(value.Range[0]), net.ParseIP(value.Range[1])
+		if first == nil || last == nil {
+			break
+		}
+		return ipRange(first, last), nil
+	}
+	return addrRange{}, errors.Errorf("unexpected element %s", elem)
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+// +build !linux
+
+package doorman
+
+import (
+	"github.com/pkg/errors"
+)
+
+func newNetlinkFirewall(magicIP string, ipv6 bool) (Firewall, error) {
+	return nil, errors.New("the netlink firewall is only supported on linux")
+}
@@ -0,0 +1,576 @@
+package doorman
+
+import (
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	pb "github.com/equinix/doorman/protobuf"
+	"github.com/packethost/pkg/log"
+	"github.com/pkg/errors"
+)
+
+// fakeFirewall is an in-memory Firewall that checks its operations are called in a sensible order.
+type fakeFirewall struct {
+	sets    map[string][]string // set -> routes
+	ipv6    map[string]string
+	enabled map[string]bool
+	broken  bool             // chains are gone
+	fail    map[string]error // operation -> error to return
+}
+
+func newFakeFirewall() *fakeFirewall {
+	return &fakeFirewall{
+		sets:    map[string][]string{},
+		ipv6:    map[string]string{},
+		enabled: map[string]bool{},
+		fail:    map[string]error{},
+	}
+}
+
+func (f *fakeFirewall) Init() error {
+	f.sets = map[string][]string{}
+	f.ipv6 = map[string]string{}
+	f.enabled = map[string]bool{}
+	f.broken = false
+	return f.fail["Init"]
+}
+
+func (f *fakeFirewall) CreateSet(allocation *pb.Allocation) error {
+	if err := f.fail["CreateSet"]; err != nil {
+		return err
+	}
+	if _, ok := f.sets[allocation.Ip]; ok {
+		return errors.Errorf("set for %s already exists", allocation.Ip)
+	}
+	f.sets[allocation.Ip] = []string{}
+	if allocation.Ipv6 != "" {
+		f.ipv6[allocation.Ip] = allocation.Ipv6
+	}
+	return nil
+}
+
+func (f *fakeFirewall) AddRoute(allocation *pb.Allocation, network *net.IPNet) error {
+	if err := f.fail["AddRoute"]; err != nil {
+		return err
+	}
+	routes, ok := f.sets[allocation.Ip]
+	if !ok {
+		return errors.Errorf("set for %s does not exist", allocation.Ip)
+	}
+	if network.IP.To4() == nil && allocation.Ipv6 == "" {
+		return errors.Errorf("ipv6 route %s for %s without an ipv6 address", network, allocation.Ip)
+	}
+	f.sets[allocation.Ip] = append(routes, network.String())
+	return nil
+}
+
+func (f *fakeFirewall) Enable(allocation *pb.Allocation) error {
+	if err := f.fail["Enable"]; err != nil {
+		return err
+	}
+	if _, ok := f.sets[allocation.Ip]; !ok {
+		return errors.Errorf("set for %s does not exist", allocation.Ip)
+	}
+	f.enabled[allocation.Ip] = true
+	return nil
+}
+
+func (f *fakeFirewall) Disable(allocation *pb.Allocation) error {
+	delete(f.enabled, allocation.Ip)
+	return f.fail["Disable"]
+}
+
+func (f *fakeFirewall) DeleteSet(allocation *pb.Allocation) error {
+	if f.enabled[allocation.Ip] {
+		return errors.Errorf("set for %s is still in use", allocation.Ip)
+	}
+	if _, ok := f.sets[allocation.Ip]; !ok {
+		return errors.Errorf("set for %s does not exist", allocation.Ip)
+	}
+	delete(f.sets, allocation.Ip)
+	delete(f.ipv6, allocation.Ip)
+	return f.fail["DeleteSet"]
+}
+
+func (f *fakeFirewall) State() (*firewallState, error) {
+	if err := f.fail["State"]; err != nil {
+		return nil, err
+	}
+	state := &firewallState{ready: !f.broken, clients: map[string]*firewallClient{}}
+	for ip, routes := range f.sets {
+		state.clients[ip] = &firewallClient{ip: ip, ipv6: f.ipv6[ip], routes: normalizeRoutes(routeRanges(routes)), enabled: f.enabled[ip]}
+	}
+	return state, nil
+}
+
+func (f *fakeFirewall) Remove(allocation *pb.Allocation) error {
+	if err := f.fail["Remove"]; err != nil {
+		return err
+	}
+	delete(f.enabled, allocation.Ip)
+	delete(f.sets, allocation.Ip)
+	delete(f.ipv6, allocation.Ip)
+	return nil
+}
+
+func TestConfigureClient(t *testing.T) {
+	logger = log.Test(t, "doorman")
+
+	store := newTestStateStore(t)
+
+	pools, err := parseIPPools(defaultVPNPools)
+	if err != nil {
+		t.Fatal(err)
+	}
+	firewall := newFakeFirewall()
+	s := &VPNServer{
+		store:       store,
+		firewall:    firewall,
+		pools:       pools,
+		allocations: map[string]*pb.Allocation{},
+		pins:        map[string]*pb.Allocation{},
+		sticky:      map[string]string{},
+		connections: map[string]*pb.Connection{},
+	}
+
+	subnets, err := parseRoutes([]string{"10.88.111.0/25", "fd00:8a0:1::/56"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ccd strings.Builder
+	allocation, routes, err := s.configureClient(logger, &ccd, "client1", subnets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ipv6 is not enabled, so the ipv6 reservation is not routed
+	if len(routes) != 1 || routes[0].Cidr != "10.88.111.0/25" {
+		t.Fatalf("unexpected routes: %v", routes)
+	}
+	want := `push "route 10.88.111.0 255.255.255.128"` + "\nifconfig-push 192.168.127.2 255.255.255.0\n"
+	if ccd.String() != want {
+		t.Fatalf("expected:\n%s\ngot:\n%s", want, ccd.String())
+	}
+	if !reflect.DeepEqual(firewall.sets[allocation.Ip], []string{"10.88.111.0/25"}) || !firewall.enabled[allocation.Ip] {
+		t.Fatalf("unexpected firewall state: %v, %v", firewall.sets, firewall.enabled)
+	}
+
+	s.pool6, err = parseIPv6Pool("fd00:d00a::/64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ccd.Reset()
+	allocation, routes, err = s.configureClient(logger, &ccd, "client2", subnets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 2 || routes[1].Cidr != "fd00:8a0:1::/56" {
+		t.Fatalf("unexpected routes: %v", routes)
+	}
+	if !strings.Contains(ccd.String(), `push "route-ipv6 fd00:8a0:1::/56"`) || !strings.Contains(ccd.String(), "ifconfig-ipv6-push fd00:d00a::c0a8:7f03/64 fd00:d00a::1\n") {
+		t.Fatalf("unexpected client config:\n%s", ccd.String())
+	}
+
+	// a failure tears down whatever was set up and frees the allocation
+	firewall.fail["Enable"] = errors.New("enable failed")
+	if _, _, err := s.configureClient(logger, &ccd, "client3", subnets); err == nil {
+		t.Fatal("expected an error")
+	}
+	if _, ok := firewall.sets["192.168.127.4"]; ok {
+		t.Fatalf("expected the set to be deleted, got: %v", firewall.sets)
+	}
+	if _, ok := s.allocations["192.168.127.4"]; ok {
+		t.Fatal("expected the allocation to be freed")
+	}
+}
+
+func TestScriptFirewall(t *testing.T) {
+	var cmds []string
+	f := &scriptFirewall{
+		magicIP: "10.0.0.1",
+		shell: func(cmd string) (string, string, error) {
+			cmds = append(cmds, cmd)
+			if strings.HasPrefix(cmd, "/app/fw-add.sh enable6") {
+				return "", "ip6tables: No chain/target/match by that name.", errors.New("exit status 1")
+			}
+			return "", "", nil
+		},
+	}
+
+	allocation := &pb.Allocation{Ip: "192.168.127.2", Ipv6: "fd00:d00a::c0a8:7f02"}
+	if err := f.CreateSet(allocation); err != nil {
+		t.Fatal(err)
+	}
+	for _, cidr := range []string{"10.88.111.0/25", "fd00:8a0:1::/56"} {
+		_, network, _ := net.ParseCIDR(cidr)
+		if err := f.AddRoute(allocation, network); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := f.Enable(allocation)
+	if err == nil || !strings.Contains(err.Error(), "No chain/target/match") {
+		t.Fatalf("expected the script's error output in the error, got: %v", err)
+	}
+
+	want := []string{
+		"/app/fw-add.sh create 192.168.127.2",
+		"/app/fw-add.sh create6 192.168.127.2",
+		"/app/fw-add.sh add 192.168.127.2 10.88.111.0/25",
+		"/app/fw-add.sh add6 192.168.127.2 fd00:d00a::c0a8:7f02 fd00:8a0:1::/56",
+		"/app/fw-add.sh enable 192.168.127.2 10.0.0.1",
+		"/app/fw-add.sh enable6 192.168.127.2 fd00:d00a::c0a8:7f02",
+		// ipv4 was already enabled, so a failure enabling ipv6 disables it again
+		"/app/fw-del.sh disable 192.168.127.2 10.0.0.1",
+	}
+	if !reflect.DeepEqual(cmds, want) {
+		t.Fatalf("expected:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(cmds, "\n"))
+	}
+}
+
+func TestNftablesFirewall(t *testing.T) {
+	var scripts []string
+	_, pool, _ := net.ParseCIDR("192.168.127.0/24")
+	_, pool6, _ := net.ParseCIDR("fd00:d00a::/64")
+	f := newNftablesFirewall("10.0.0.1", []*net.IPNet{pool, pool6})
+	f.nft = func(script string) error {
+		scripts = append(scripts, script)
+		return nil
+	}
+
+	if err := f.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if len(scripts) != 1 || !strings.HasPrefix(scripts[0], "table inet doorman\ndelete table inet doorman\n") {
+		t.Fatalf("unexpected init script: %v", scripts)
+	}
+	// only traffic of the vpn pools is dropped
+	if !strings.Contains(scripts[0], "policy accept;") ||
+		!strings.HasSuffix(scripts[0], "add element inet doorman pools4 { 192.168.127.0/24 }\nadd element inet doorman pools6 { fd00:d00a::/64 }\n") {
+		t.Fatalf("unexpected init script: %s", scripts[0])
+	}
+
+	allocation := &pb.Allocation{Ip: "192.168.127.2", Ipv6: "fd00:d00a::c0a8:7f02"}
+	if err := f.CreateSet(allocation); err != nil {
+		t.Fatal(err)
+	}
+	for _, cidr := range []string{"10.88.111.0/25", "10.88.112.0/25", "fd00:8a0:1::/56"} {
+		_, network, _ := net.ParseCIDR(cidr)
+		if err := f.AddRoute(allocation, network); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// nothing is applied until the client is enabled
+	if len(scripts) != 1 {
+		t.Fatalf("expected no transaction before enable, got: %v", scripts[1:])
+	}
+	if err := f.Enable(allocation); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `add chain inet doorman client_192_168_127_2
+add chain inet doorman nat_192_168_127_2
+add set inet doorman nets4_192_168_127_2 { type ipv4_addr; flags interval; auto-merge; }
+add element inet doorman nets4_192_168_127_2 { 10.88.111.0/25, 10.88.112.0/25 }
+add rule inet doorman client_192_168_127_2 ip saddr 192.168.127.2 ip daddr @nets4_192_168_127_2 accept
+add rule inet doorman client_192_168_127_2 ip daddr 192.168.127.2 ip saddr @nets4_192_168_127_2 accept
+add rule inet doorman nat_192_168_127_2 ip daddr @nets4_192_168_127_2 snat ip to 10.0.0.1
+add element inet doorman clients4 { 192.168.127.2 : jump client_192_168_127_2 }
+add element inet doorman nat4 { 192.168.127.2 : jump nat_192_168_127_2 }
+add set inet doorman nets6_192_168_127_2 { type ipv6_addr; flags interval; auto-merge; }
+add element inet doorman nets6_192_168_127_2 { fd00:8a0:1::/56 }
+add rule inet doorman client_192_168_127_2 ip6 saddr fd00:d00a::c0a8:7f02 ip6 daddr @nets6_192_168_127_2 accept
+add rule inet doorman client_192_168_127_2 ip6 daddr fd00:d00a::c0a8:7f02 ip6 saddr @nets6_192_168_127_2 accept
+add rule inet doorman nat_192_168_127_2 ip6 daddr @nets6_192_168_127_2 masquerade
+add element inet doorman clients6 { fd00:d00a::c0a8:7f02 : jump client_192_168_127_2 }
+add element inet doorman nat6 { fd00:d00a::c0a8:7f02 : jump nat_192_168_127_2 }
+`
+	if len(scripts) != 2 || scripts[1] != want {
+		t.Fatalf("expected:\n%s\ngot:\n%s", want, scripts[len(scripts)-1])
+	}
+
+	if err := f.DeleteSet(allocation); err == nil {
+		t.Fatal("expected deleting the sets of an enabled client to fail")
+	}
+	if err := f.Disable(allocation); err != nil {
+		t.Fatal(err)
+	}
+	want = `delete element inet doorman clients4 { 192.168.127.2 }
+delete element inet doorman nat4 { 192.168.127.2 }
+delete element inet doorman clients6 { fd00:d00a::c0a8:7f02 }
+delete element inet doorman nat6 { fd00:d00a::c0a8:7f02 }
+flush chain inet doorman client_192_168_127_2
+delete chain inet doorman client_192_168_127_2
+flush chain inet doorman nat_192_168_127_2
+delete chain inet doorman nat_192_168_127_2
+delete set inet doorman nets4_192_168_127_2
+delete set inet doorman nets6_192_168_127_2
+`
+	if len(scripts) != 3 || scripts[2] != want {
+		t.Fatalf("expected:\n%s\ngot:\n%s", want, scripts[len(scripts)-1])
+	}
+	if err := f.DeleteSet(allocation); err != nil {
+		t.Fatal(err)
+	}
+
+	// tearing down a client that was never enabled does not touch the ruleset
+	if err := f.CreateSet(allocation); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Disable(allocation); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.DeleteSet(allocation); err != nil {
+		t.Fatal(err)
+	}
+	if len(scripts) != 3 {
+		t.Fatalf("expected no transaction, got: %v", scripts[3:])
+	}
+
+	// a failed transaction leaves the client disabled
+	f.nft = func(script string) error { return errors.New("nft: Error: Could not process rule") }
+	if err := f.CreateSet(allocation); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Enable(allocation); err == nil {
+		t.Fatal("expected an error")
+	}
+	if err := f.DeleteSet(allocation); err != nil {
+		t.Fatal(err)
+	}
+
+	// Remove does not rely on what is left, it adds every object before deleting it
+	scripts = nil
+	f.nft = func(script string) error {
+		scripts = append(scripts, script)
+		return nil
+	}
+	if err := f.CreateSet(allocation); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Remove(&pb.Allocation{Ip: "192.168.127.2"}); err != nil {
+		t.Fatal(err)
+	}
+	want = `add chain inet doorman client_192_168_127_2
+add chain inet doorman nat_192_168_127_2
+add element inet doorman clients4 { 192.168.127.2 : jump client_192_168_127_2 }
+delete element inet doorman clients4 { 192.168.127.2 }
+add element inet doorman nat4 { 192.168.127.2 : jump nat_192_168_127_2 }
+delete element inet doorman nat4 { 192.168.127.2 }
+flush chain inet doorman client_192_168_127_2
+delete chain inet doorman client_192_168_127_2
+flush chain inet doorman nat_192_168_127_2
+delete chain inet doorman nat_192_168_127_2
+add set inet doorman nets4_192_168_127_2 { type ipv4_addr; flags interval; auto-merge; }
+delete set inet doorman nets4_192_168_127_2
+add set inet doorman nets6_192_168_127_2 { type ipv6_addr; flags interval; auto-merge; }
+delete set inet doorman nets6_192_168_127_2
+`
+	if len(scripts) != 1 || scripts[0] != want {
+		t.Fatalf("expected:\n%s\ngot:\n%v", want, scripts)
+	}
+	if err := f.CreateSet(allocation); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNormalizeRoutes(t *testing.T) {
+	parse := func(cidrs ...string) []addrRange {
+		var ranges []addrRange
+		for _, cidr := range cidrs {
+			_, network, err := net.ParseCIDR(cidr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ranges = append(ranges, networkRange(network))
+		}
+		return ranges
+	}
+
+	tests := []struct {
+		name   string
+		ranges []addrRange
+		want   []string
+	}{
+		{"empty", nil, []string{}},
+		{"adjacent", parse("10.0.0.128/25", "10.0.0.0/25"), []string{"10.0.0.0/24"}},
+		{"overlapping", parse("10.0.0.0/24", "10.0.0.64/26", "10.0.1.0/32"), []string{"10.0.0.0/24", "10.0.1.0/32"}},
+		{"unaligned", parse("10.0.0.128/25", "10.0.1.0/25"), []string{"10.0.0.128/25", "10.0.1.0/25"}},
+		{"range", []addrRange{ipRange(net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.6"))}, []string{"10.0.0.1/32", "10.0.0.2/31", "10.0.0.4/31", "10.0.0.6/32"}},
+		{"families", parse("fd00:8a0:1::/56", "10.0.0.0/8", "fd00:8a0:1:100::/56"), []string{"10.0.0.0/8", "fd00:8a0:1::/55"}},
+		{"everything", parse("0.0.0.0/1", "128.0.0.0/1"), []string{"0.0.0.0/0"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := normalizeRoutes(test.ranges)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestScriptFirewallInit(t *testing.T) {
+	for _, ipv6 := range []bool{false, true} {
+		var cmds []string
+		f := &scriptFirewall{ipv6: ipv6, shell: func(cmd string) (string, string, error) {
+			cmds = append(cmds, cmd)
+			return "", "", nil
+		}}
+		if err := f.Init(); err != nil {
+			t.Fatal(err)
+		}
+		// ip6tables is left alone unless clients get ipv6 addresses
+		want := []string{commandInit}
+		if ipv6 {
+			want = []string{commandInit6}
+		}
+		if !reflect.DeepEqual(cmds, want) {
+			t.Fatalf("ipv6 %v: expected %v, got %v", ipv6, want, cmds)
+		}
+	}
+}
+
+func TestScriptFirewallState(t *testing.T) {
+	output := map[string]string{
+		"iptables -t filter -S FORWARD":   "-P FORWARD DROP\n-A FORWARD -j DOORMAN_FORWARD\n",
+		"iptables -t nat -S POSTROUTING":  "-P POSTROUTING ACCEPT\n-A POSTROUTING -j DOORMAN_POSTROUTING\n",
+		"ip6tables -t filter -S FORWARD":  "-P FORWARD DROP\n-A FORWARD -j DOORMAN_FORWARD\n",
+		"ip6tables -t nat -S POSTROUTING": "-P POSTROUTING ACCEPT\n-A POSTROUTING -j DOORMAN_POSTROUTING\n",
+		"ip6tables -t nat -S DOORMAN_POSTROUTING": `-N DOORMAN_POSTROUTING
+-A DOORMAN_POSTROUTING -s fd00:d00a::c0a8:7f02/128 -m set --match-set doorman6-192.168.127.2 src,dst -j MASQUERADE
+`,
+		"ip6tables -t filter -S DOORMAN_FORWARD": `-N DOORMAN_FORWARD
+-A DOORMAN_FORWARD -m set --match-set doorman6-192.168.127.2 src,dst -j ACCEPT
+`,
+		"iptables -t filter -S DOORMAN_FORWARD": `-N DOORMAN_FORWARD
+-A DOORMAN_FORWARD -m set --match-set doorman-192.168.127.2 src,dst -j ACCEPT
+-A DOORMAN_FORWARD -m set --match-set doorman-192.168.127.3 src,dst -j ACCEPT
+-A DOORMAN_FORWARD -m set --match-set doorman-192.168.127.4 src,dst -j ACCEPT
+`,
+		// 192.168.127.3 is still snat'ed to a previous magic ip
+		"iptables -t nat -S DOORMAN_POSTROUTING": `-N DOORMAN_POSTROUTING
+-A DOORMAN_POSTROUTING -s 192.168.127.4/32 -m set --match-set doorman-192.168.127.4 src,dst -j SNAT --to-source 10.0.0.1
+-A DOORMAN_POSTROUTING -s 192.168.127.3/32 -m set --match-set doorman-192.168.127.3 src,dst -j SNAT --to-source 10.0.0.9
+-A DOORMAN_POSTROUTING -s 192.168.127.2/32 -m set --match-set doorman-192.168.127.2 src,dst -j SNAT --to-source 10.0.0.1
+`,
+		// 192.168.127.4 lost the reverse entry of 10.88.113.0/25
+		"ipset save": `create doorman-192.168.127.2 hash:net,net family inet hashsize 1024 maxelem 65536
+add doorman-192.168.127.2 10.88.111.0/25,192.168.127.2
+add doorman-192.168.127.2 192.168.127.2,10.88.111.0/25
+add doorman-192.168.127.2 10.88.111.128/25,192.168.127.2
+add doorman-192.168.127.2 192.168.127.2,10.88.111.128/25
+create doorman6-192.168.127.2 hash:net,net family inet6 hashsize 1024 maxelem 65536
+add doorman6-192.168.127.2 fd00:d00a::c0a8:7f02,fd00:8a0:1::/56
+add doorman6-192.168.127.2 fd00:8a0:1::/56,fd00:d00a::c0a8:7f02
+create doorman-192.168.127.3 hash:net,net family inet hashsize 1024 maxelem 65536
+create doorman-192.168.127.4 hash:net,net family inet hashsize 1024 maxelem 65536
+add doorman-192.168.127.4 192.168.127.4,10.88.113.0/25
+create other hash:ip family inet hashsize 1024 maxelem 65536
+add other 10.0.0.1
+`,
+	}
+	f := &scriptFirewall{magicIP: "10.0.0.1", ipv6: true, shell: func(cmd string) (string, string, error) {
+		out, ok := output[cmd]
+		if !ok {
+			return "", "", errors.Errorf("unexpected command %s", cmd)
+		}
+		return out, "", nil
+	}}
+
+	state, err := f.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]*firewallClient{
+		"192.168.127.2": {ip: "192.168.127.2", ipv6: "fd00:d00a::c0a8:7f02", routes: []string{"10.88.111.0/24", "fd00:8a0:1::/56"}, enabled: true},
+		"192.168.127.3": {ip: "192.168.127.3", routes: []string{}},
+		"192.168.127.4": {ip: "192.168.127.4", routes: []string{"10.88.113.0/25"}},
+	}
+	if !state.ready || !reflect.DeepEqual(state.clients, want) {
+		t.Fatalf("unexpected state %v: %+v", state.ready, state.clients)
+	}
+
+	// iptables -F removes the jumps to doorman's chains
+	output["iptables -t filter -S FORWARD"] = "-P FORWARD DROP\n"
+	state, err = f.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.ready {
+		t.Fatal("expected the firewall not to be ready without the forward jump")
+	}
+}
+
+func TestNftablesFirewallState(t *testing.T) {
+	f := newNftablesFirewall("", nil)
+	f.list = func() ([]byte, error) {
+		return []byte(`{"nftables": [
+{"metainfo": {"version": "1.0.9", "json_schema_version": 1}},
+{"table": {"family": "inet", "name": "doorman", "handle": 1}},
+{"map": {"family": "inet", "name": "clients4", "table": "doorman", "type": "ipv4_addr", "handle": 1, "map": "verdict",
+  "elem": [["192.168.127.2", {"jump": {"target": "client_192_168_127_2"}}], ["192.168.127.3", {"jump": {"target": "client_192_168_127_3"}}]]}},
+{"map": {"family": "inet", "name": "clients6", "table": "doorman", "type": "ipv6_addr", "handle": 2, "map": "verdict",
+  "elem": [["fd00:d00a::c0a8:7f02", {"jump": {"target": "client_192_168_127_2"}}]]}},
+{"map": {"family": "inet", "name": "nat4", "table": "doorman", "type": "ipv4_addr", "handle": 3, "map": "verdict",
+  "elem": [["192.168.127.2", {"jump": {"target": "nat_192_168_127_2"}}], ["192.168.127.3", {"jump": {"target": "nat_192_168_127_3"}}]]}},
+{"map": {"family": "inet", "name": "nat6", "table": "doorman", "type": "ipv6_addr", "handle": 4, "map": "verdict",
+  "elem": [["fd00:d00a::c0a8:7f02", {"jump": {"target": "nat_192_168_127_2"}}]]}},
+{"chain": {"family": "inet", "table": "doorman", "name": "forward", "handle": 5, "type": "filter", "hook": "forward", "prio": 0, "policy": "accept"}},
+{"chain": {"family": "inet", "table": "doorman", "name": "postrouting", "handle": 6, "type": "nat", "hook": "postrouting", "prio": 100, "policy": "accept"}},
+{"chain": {"family": "inet", "table": "doorman", "name": "client_192_168_127_2", "handle": 7}},
+{"chain": {"family": "inet", "table": "doorman", "name": "nat_192_168_127_2", "handle": 8}},
+{"chain": {"family": "inet", "table": "doorman", "name": "client_192_168_127_3", "handle": 9}},
+{"chain": {"family": "inet", "table": "doorman", "name": "nat_192_168_127_3", "handle": 10}},
+{"set": {"family": "inet", "name": "nets4_192_168_127_2", "table": "doorman", "type": "ipv4_addr", "handle": 11, "flags": ["interval"], "auto-merge": true,
+  "elem": [{"prefix": {"addr": "10.88.111.0", "len": 24}}, {"range": ["10.88.112.1", "10.88.112.2"]}, "10.88.112.9"]}},
+{"set": {"family": "inet", "name": "nets6_192_168_127_2", "table": "doorman", "type": "ipv6_addr", "handle": 12, "flags": ["interval"], "auto-merge": true,
+  "elem": [{"prefix": {"addr": "fd00:8a0:1::", "len": 56}}]}},
+{"set": {"family": "inet", "name": "nets4_192_168_127_3", "table": "doorman", "type": "ipv4_addr", "handle": 13, "flags": ["interval"], "auto-merge": true}},
+{"set": {"family": "inet", "name": "nets4_192_168_127_4", "table": "doorman", "type": "ipv4_addr", "handle": 14, "flags": ["interval"], "auto-merge": true}},
+{"rule": {"family": "inet", "table": "doorman", "chain": "forward", "handle": 15, "expr": []}},
+{"rule": {"family": "inet", "table": "doorman", "chain": "forward", "handle": 16, "expr": []}},
+{"rule": {"family": "inet", "table": "doorman", "chain": "forward", "handle": 17, "expr": []}},
+{"rule": {"family": "inet", "table": "doorman", "chain": "forward", "handle": 18, "expr": []}},
+{"rule": {"family": "inet", "table": "doorman", "chain": "forward", "handle": 29, "expr": []}},
+{"rule": {"family": "inet", "table": "doorman", "chain": "forward", "handle": 30, "expr": []}},
+{"rule": {"family": "inet", "table": "doorman", "chain": "forward", "handle": 31, "expr": []}},
+{"rule": {"family": "inet", "table": "doorman", "chain": "forward", "handle": 32, "expr": []}},
+{"rule": {"family": "inet", "table": "doorman", "chain": "postrouting", "handle": 19, "expr": []}},
+{"rule": {"family": "inet", "table": "doorman", "chain": "postrouting", "handle": 20, "expr": []}},
+{"rule": {"family": "inet", "table": "doorman", "chain": "client_192_168_127_2", "handle": 21, "expr": []}},
+{"rule": {"family": "inet", "table": "doorman", "chain": "client_192_168_127_2", "handle": 22, "expr": []}},
+{"rule": {"family": "inet", "table": "doorman", "chain": "client_192_168_127_2", "handle": 23, "expr": []}},
+{"rule": {"family": "inet", "table": "doorman", "chain": "client_192_168_127_2", "handle": 24, "expr": []}},
+{"rule": {"family": "inet", "table": "doorman", "chain": "nat_192_168_127_2", "handle": 25, "expr": []}},
+{"rule": {"family": "inet", "table": "doorman", "chain": "nat_192_168_127_2", "handle": 26, "expr": []}},
+{"rule": {"family": "inet", "table": "doorman", "chain": "client_192_168_127_3", "handle": 27, "expr": []}},
+{"rule": {"family": "inet", "table": "doorman", "chain": "nat_192_168_127_3", "handle": 28, "expr": []}}
+]}`), nil
+	}
+
+	state, err := f.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]*firewallClient{
+		"192.168.127.2": {ip: "192.168.127.2", ipv6: "fd00:d00a::c0a8:7f02", routes: []string{"10.88.111.0/24", "10.88.112.1/32", "10.88.112.2/32", "10.88.112.9/32", "fd00:8a0:1::/56"}, enabled: true},
+		// one of the forward rules is gone
+		"192.168.127.3": {ip: "192.168.127.3", routes: []string{}},
+		// a leftover set
+		"192.168.127.4": {ip: "192.168.127.4", routes: []string{}},
+	}
+	if !state.ready || !reflect.DeepEqual(state.clients, want) {
+		t.Fatalf("unexpected state %v: %+v", state.ready, state.clients)
+	}
+
+	f.list = func() ([]byte, error) { return nil, nil }
+	state, err = f.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.ready || len(state.clients) != 0 {
+		t.Fatalf("expected no table, got %v: %+v", state.ready, state.clients)
+	}
+}
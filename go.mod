@@ -3,6 +3,9 @@ module github.com/equinix/doorman
 go 1.15
 
 require (
+	github.com/coreos/go-iptables v0.8.0
+	github.com/go-asn1-ber/asn1-ber v1.5.5
+	github.com/go-ldap/ldap/v3 v3.4.6
 	github.com/golang/protobuf v1.3.2
 	github.com/hashicorp/go-hclog v0.10.0 // indirect
 	github.com/hashicorp/go-retryablehttp v0.6.6
@@ -17,6 +20,12 @@ require (
 	github.com/spf13/cobra v0.0.5
 	github.com/spf13/jwalterweatherman v1.1.0 // indirect
 	github.com/spf13/viper v1.4.0
-	golang.org/x/text v0.3.2 // indirect
+	github.com/vishvananda/netlink v1.3.1
+	go.etcd.io/bbolt v1.3.7
+	golang.org/x/crypto v0.14.0
+	golang.org/x/net v0.17.0 // indirect
+	golang.org/x/sys v0.13.0
+	golang.org/x/text v0.13.0 // indirect
 	google.golang.org/grpc v1.22.0
+	gopkg.in/yaml.v2 v2.2.2
 )
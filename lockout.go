@@ -0,0 +1,227 @@
+package doorman
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/equinix/doorman/protobuf"
+	"github.com/pkg/errors"
+)
+
+const (
+	lockoutUser = "user"
+	lockoutIP   = "ip"
+
+	defaultLockoutUserThreshold = 5
+	defaultLockoutIPThreshold   = 20 // many users may share an address behind nat
+	defaultLockoutDuration      = time.Minute
+	defaultLockoutMax           = time.Hour
+
+	// lockoutMaxEntries bounds the tracked logins and addresses per kind, so a spray over random logins or
+	// addresses can't grow them without bound
+	lockoutMaxEntries = 10000
+)
+
+var errLockedOut = errors.New("too many failed logins, try again later")
+
+// lockout tracks the failed logins of a login or address.
+type lockout struct {
+	failures int
+	last     time.Time // of the last failure
+	until    time.Time // locked out until, zero if not locked out yet
+}
+
+// evictsBefore reports whether the lockout is dropped before the other when there are too many.
+func (l *lockout) evictsBefore(other *lockout, now time.Time) bool {
+	if locked, otherLocked := now.Before(l.until), now.Before(other.until); locked != otherLocked {
+		return otherLocked
+	}
+	return l.last.Before(other.last)
+}
+
+// lockouts protects the authentication backends from password guessing. Once a login or the address logins come
+// from failed threshold times, they are locked out for duration, doubling with every further failure up to max.
+// Failures are forgotten max after the last one, or for a login when it logs in successfully.
+type lockouts struct {
+	thresholds map[string]int // kind -> failures before a key is locked out, never if 0
+	duration   time.Duration
+	max        time.Duration
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]map[string]*lockout // kind -> key -> lockout
+}
+
+func newLockouts(userThreshold, ipThreshold int, duration, max time.Duration) *lockouts {
+	return &lockouts{
+		thresholds: map[string]int{lockoutUser: userThreshold, lockoutIP: ipThreshold},
+		duration:   duration,
+		max:        max,
+		now:        time.Now,
+		entries:    map[string]map[string]*lockout{lockoutUser: {}, lockoutIP: {}},
+	}
+}
+
+// lockoutKeys returns the keys a login attempt is tracked by, per kind.
+func lockoutKeys(login, ip string) map[string]string {
+	keys := map[string]string{}
+	if login != "" {
+		keys[lockoutUser] = strings.ToLower(login)
+	}
+	if ip != "" {
+		keys[lockoutIP] = ip
+	}
+	return keys
+}
+
+// check returns errLockedOut if the login or the address is locked out.
+func (l *lockouts) check(login, ip string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	for kind, key := range lockoutKeys(login, ip) {
+		if entry := l.entry(kind, key, now); entry != nil && now.Before(entry.until) {
+			return errLockedOut
+		}
+	}
+	return nil
+}
+
+// failed records a failed login, and returns whether the login or the address is locked out now.
+func (l *lockouts) failed(login, ip string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	locked := false
+	for kind, key := range lockoutKeys(login, ip) {
+		threshold := l.thresholds[kind]
+		if threshold <= 0 {
+			continue
+		}
+
+		entry := l.entry(kind, key, now)
+		if entry == nil {
+			entry = l.add(kind, key, now)
+		}
+		entry.failures++
+		entry.last = now
+		if entry.failures < threshold {
+			continue
+		}
+
+		duration := l.max
+		if shift := uint(entry.failures - threshold); shift < 32 && l.duration<<shift < l.max {
+			duration = l.duration << shift
+		}
+		entry.until = now.Add(duration)
+		locked = true
+	}
+	return locked
+}
+
+// succeeded forgets the failures of the login. The address' failures are kept, so they can't be reset with a
+// single known password.
+func (l *lockouts) succeeded(login string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.entries[lockoutUser], strings.ToLower(login))
+}
+
+// add starts tracking the key's failures. If there are too many keys of its kind, the forgotten ones are swept and
+// if that's not enough the one with the oldest failure is dropped, locked out ones last. The caller needs to hold mu.
+func (l *lockouts) add(kind, key string, now time.Time) *lockout {
+	entries := l.entries[kind]
+	if len(entries) >= lockoutMaxEntries {
+		for key := range entries {
+			l.entry(kind, key, now)
+		}
+	}
+	if len(entries) >= lockoutMaxEntries {
+		var oldest string
+		for key, entry := range entries {
+			if oldest == "" || entry.evictsBefore(entries[oldest], now) {
+				oldest = key
+			}
+		}
+		delete(entries, oldest)
+	}
+
+	entry := &lockout{}
+	entries[key] = entry
+	return entry
+}
+
+// entry returns the key's lockout, nil if it has none or its failures are forgotten. The caller needs to hold mu.
+func (l *lockouts) entry(kind, key string, now time.Time) *lockout {
+	entry, ok := l.entries[kind][key]
+	if !ok {
+		return nil
+	}
+	if now.Sub(entry.last) >= l.max && !now.Before(entry.until) {
+		delete(l.entries[kind], key)
+		return nil
+	}
+	return entry
+}
+
+// list returns the logins and addresses with failures that are not forgotten yet, sorted by kind and key.
+func (l *lockouts) list() []*pb.Lockout {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	var lockouts []*pb.Lockout
+	for kind, entries := range l.entries {
+		for key := range entries {
+			entry := l.entry(kind, key, now)
+			if entry == nil {
+				continue
+			}
+			lockout := &pb.Lockout{Kind: kind, Key: key, Failures: int32(entry.failures), LastFailure: entry.last.Unix()}
+			if now.Before(entry.until) {
+				lockout.LockedUntil = entry.until.Unix()
+			}
+			lockouts = append(lockouts, lockout)
+		}
+	}
+	sort.Slice(lockouts, func(i, j int) bool {
+		if lockouts[i].Kind != lockouts[j].Kind {
+			return lockouts[i].Kind < lockouts[j].Kind
+		}
+		return lockouts[i].Key < lockouts[j].Key
+	})
+	return lockouts
+}
+
+// clear forgets the failures of a login or address, or of all of them if kind and key are empty,
+// and returns how many were forgotten.
+func (l *lockouts) clear(kind, key string) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if kind == "" && key == "" {
+		cleared := 0
+		for kind := range l.entries {
+			cleared += len(l.entries[kind])
+			l.entries[kind] = map[string]*lockout{}
+		}
+		return cleared, nil
+	}
+
+	entries, ok := l.entries[kind]
+	if !ok {
+		return 0, errors.Errorf("unknown lockout kind %q, expecting %s or %s", kind, lockoutUser, lockoutIP)
+	}
+	if kind == lockoutUser {
+		key = strings.ToLower(key)
+	}
+	if _, ok := entries[key]; !ok {
+		return 0, errors.Errorf("%s %s is not locked out", kind, key)
+	}
+	delete(entries, key)
+	return 1, nil
+}
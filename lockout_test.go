@@ -0,0 +1,110 @@
+package doorman
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestLockouts(t *testing.T) {
+	l := newLockouts(3, 5, time.Minute, 10*time.Minute)
+	now := time.Unix(1600000000, 0)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if l.failed("alice", "192.0.2.1") {
+			t.Fatalf("failure %d: expected no lockout before the threshold", i+1)
+		}
+	}
+	if err := l.check("alice", "192.0.2.1"); err != nil {
+		t.Fatal(err)
+	}
+	if !l.failed("alice", "192.0.2.1") {
+		t.Fatal("expected the user to be locked out at the threshold")
+	}
+	if err := l.check("alice", "192.0.2.2"); err != errLockedOut {
+		t.Fatalf("expected the user to be locked out from any address, got %v", err)
+	}
+	if err := l.check("bob", "192.0.2.1"); err != nil {
+		t.Fatalf("expected the address to be below its threshold, got %v", err)
+	}
+
+	// every further failure doubles the lockout, up to max
+	now = now.Add(time.Minute)
+	if err := l.check("alice", ""); err != nil {
+		t.Fatal(err)
+	}
+	l.failed("alice", "")
+	now = now.Add(time.Minute)
+	if err := l.check("alice", ""); err != errLockedOut {
+		t.Fatalf("expected the lockout to double, got %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		l.failed("alice", "")
+	}
+	lockouts := l.list()
+	if len(lockouts) != 2 || lockouts[1].Kind != lockoutUser || lockouts[1].Failures != 9 || lockouts[1].LockedUntil != now.Add(10*time.Minute).Unix() {
+		t.Fatalf("expected the lockout to be capped at max, got %v", lockouts)
+	}
+
+	// succeeding forgets the user's failures but not the address'
+	l.succeeded("alice")
+	l.failed("bob", "192.0.2.1")
+	l.failed("carol", "192.0.2.1")
+	if err := l.check("dave", "192.0.2.1"); err != errLockedOut {
+		t.Fatalf("expected the address to be locked out, got %v", err)
+	}
+	if cleared, err := l.clear(lockoutIP, "192.0.2.1"); err != nil || cleared != 1 {
+		t.Fatalf("expected the address to be cleared, got %d %v", cleared, err)
+	}
+	if err := l.check("dave", "192.0.2.1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.clear("host", "192.0.2.1"); err == nil {
+		t.Fatal("expected an unknown kind to fail")
+	}
+
+	// failures are forgotten max after the last one
+	now = now.Add(10 * time.Minute)
+	if lockouts := l.list(); len(lockouts) != 0 {
+		t.Fatalf("expected failures to be forgotten, got %v", lockouts)
+	}
+	if cleared, _ := l.clear("", ""); cleared != 0 {
+		t.Fatalf("expected nothing left to clear, got %d", cleared)
+	}
+}
+
+func TestLockoutKeys(t *testing.T) {
+	l := newLockouts(2, 0, time.Minute, 10*time.Minute)
+	now := time.Unix(1600000000, 0)
+	l.now = func() time.Time { return now }
+
+	// logins differing in case share their failures
+	l.failed("Alice", "")
+	if !l.failed("alice", "") {
+		t.Fatal("expected the user to be locked out regardless of case")
+	}
+	if err := l.check("ALICE", ""); err != errLockedOut {
+		t.Fatalf("expected the user to be locked out, got %v", err)
+	}
+
+	// a spray over random logins drops the oldest unlocked ones instead of growing without bound
+	for i := 0; i < lockoutMaxEntries; i++ {
+		now = now.Add(time.Millisecond)
+		l.failed(fmt.Sprintf("user%d", i), "")
+	}
+	if n := len(l.entries[lockoutUser]); n != lockoutMaxEntries {
+		t.Fatalf("expected %d tracked logins, got %d", lockoutMaxEntries, n)
+	}
+	if err := l.check("alice", ""); err != errLockedOut {
+		t.Fatalf("expected the locked out user to be kept, got %v", err)
+	}
+	if _, ok := l.entries[lockoutUser]["user0"]; ok {
+		t.Fatal("expected the oldest login to be dropped")
+	}
+
+	l.succeeded("ALICE")
+	if err := l.check("alice", ""); err != nil {
+		t.Fatal(err)
+	}
+}
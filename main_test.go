@@ -0,0 +1,13 @@
+package doorman
+
+import (
+	"os"
+	"testing"
+
+	"github.com/equinix/doorman/metrics"
+)
+
+func TestMain(m *testing.M) {
+	metrics.Init()
+	os.Exit(m.Run())
+}
@@ -0,0 +1,440 @@
+package doorman
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/equinix/doorman/metrics"
+	"github.com/packethost/pkg/log"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	managementDialInterval = 500 * time.Millisecond
+	managementReplyTimeout = 10 * time.Second
+	clientKillTimeout      = 15 * time.Second
+)
+
+// clientEvent is a >CLIENT notification from the openvpn management interface along with its >CLIENT:ENV block.
+type clientEvent struct {
+	kind string // CONNECT, REAUTH, ESTABLISHED, DISCONNECT or ADDRESS
+	cid  string
+	kid  string
+	env  map[string]string
+}
+
+// managementClient speaks openvpn's management interface protocol over a unix socket.
+// Real-time notifications (lines starting with '>') are collected into events and handed to the handler,
+// everything else is treated as the reply to the last command.
+// Events of different clients are handled concurrently, those of one client id one at a time in the order openvpn
+// sent them, so e.g. a DISCONNECT is only handled once the CONNECT before it was answered.
+type managementClient struct {
+	conn    net.Conn
+	handler func(*clientEvent)
+
+	mu      sync.Mutex // serializes commands
+	replies chan string
+
+	queuesMu sync.Mutex
+	queues   map[string][]*clientEvent // cid -> events waiting to be handled, present while a goroutine handles them
+}
+
+func dialManagement(ctx context.Context, path string, handler func(*clientEvent)) (*managementClient, error) {
+	var d net.Dialer
+	for {
+		conn, err := d.DialContext(ctx, "unix", path)
+		if err == nil {
+			return &managementClient{
+				conn:    conn,
+				handler: handler,
+				replies: make(chan string, 1),
+			}, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrap(err, "dial openvpn management interface")
+		case <-time.After(managementDialInterval):
+		}
+	}
+}
+
+func (m *managementClient) Close() error {
+	return m.conn.Close()
+}
+
+// run reads from the management interface until the connection is closed.
+func (m *managementClient) run() error {
+	var event *clientEvent
+
+	scanner := bufio.NewScanner(m.conn)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if !strings.HasPrefix(line, ">") {
+			select {
+			case m.replies <- line:
+			default:
+				logger.With("reply", line).Info("dropping unexpected openvpn management reply")
+			}
+			continue
+		}
+
+		kind, args := splitNotification(line)
+		switch kind {
+		case "CLIENT":
+			switch {
+			case args == "ENV,END":
+				if event != nil {
+					m.dispatch(event)
+				}
+				event = nil
+			case strings.HasPrefix(args, "ENV,"):
+				if event == nil {
+					continue
+				}
+				kv := strings.SplitN(strings.TrimPrefix(args, "ENV,"), "=", 2)
+				if len(kv) == 2 {
+					event.env[kv[0]] = kv[1]
+				}
+			default:
+				fields := strings.Split(args, ",")
+				event = &clientEvent{kind: fields[0], env: map[string]string{}}
+				if len(fields) > 1 {
+					event.cid = fields[1]
+				}
+				if len(fields) > 2 {
+					event.kid = fields[2]
+				}
+				// ADDRESS notifications are not followed by an ENV block
+				if event.kind == "ADDRESS" {
+					event = nil
+				}
+			}
+		case "INFO", "HOLD":
+			logger.With("notification", args).Info("openvpn management " + strings.ToLower(kind))
+		default:
+			logger.With("notification", line).Debug("ignoring openvpn management notification")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.Wrap(err, "read openvpn management interface")
+	}
+	return errors.New("openvpn management interface closed")
+}
+
+// dispatch queues the event behind those of the same client id, starting a goroutine handling them if there is
+// none yet.
+func (m *managementClient) dispatch(event *clientEvent) {
+	m.queuesMu.Lock()
+	if m.queues == nil {
+		m.queues = map[string][]*clientEvent{}
+	}
+	queue, busy := m.queues[event.cid]
+	m.queues[event.cid] = append(queue, event)
+	m.queuesMu.Unlock()
+
+	if !busy {
+		go m.drain(event.cid)
+	}
+}
+
+// drain handles the client id's events until its queue is empty.
+func (m *managementClient) drain(cid string) {
+	for {
+		m.queuesMu.Lock()
+		queue := m.queues[cid]
+		if len(queue) == 0 {
+			delete(m.queues, cid)
+			m.queuesMu.Unlock()
+			return
+		}
+		m.queues[cid] = queue[1:]
+		m.queuesMu.Unlock()
+
+		m.handler(queue[0])
+	}
+}
+
+// command sends cmd and waits for its single line SUCCESS/ERROR reply.
+func (m *managementClient) command(cmd string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// drop a late reply to a command that already timed out
+	select {
+	case <-m.replies:
+	default:
+	}
+
+	if _, err := fmt.Fprintf(m.conn, "%s\n", cmd); err != nil {
+		return "", errors.Wrap(err, "write openvpn management command")
+	}
+
+	select {
+	case reply := <-m.replies:
+		if strings.HasPrefix(reply, "ERROR:") {
+			return "", errors.New(strings.TrimSpace(strings.TrimPrefix(reply, "ERROR:")))
+		}
+		return strings.TrimSpace(strings.TrimPrefix(reply, "SUCCESS:")), nil
+	case <-time.After(managementReplyTimeout):
+		return "", errors.New("timed out waiting for openvpn management reply")
+	}
+}
+
+func splitNotification(line string) (string, string) {
+	parts := strings.SplitN(strings.TrimPrefix(line, ">"), ":", 2)
+	if len(parts) != 2 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+// quoteManagementArg quotes s so it is passed as a single argument to a management command.
+func quoteManagementArg(s string) string {
+	// a line break would end the command and start another one
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, s)
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	return `"` + s + `"`
+}
+
+// manageOpenVPN connects to openvpn's management interface and handles client events until ctx is done.
+// openvpn is started with management-hold so no clients are accepted before doorman is connected.
+func (s *VPNServer) manageOpenVPN(ctx context.Context, path string) {
+	for ctx.Err() == nil {
+		m, err := dialManagement(ctx, path, s.handleClientEvent)
+		if err != nil {
+			if ctx.Err() == nil {
+				metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+				logger.Error(err)
+			}
+			return
+		}
+		logger.With("path", path).Info("connected to openvpn management interface")
+
+		done := make(chan error, 1)
+		go func() {
+			done <- m.run()
+		}()
+
+		s.mu.Lock()
+		s.management = m
+		s.mu.Unlock()
+
+		if _, err := m.command("hold release"); err != nil {
+			metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+			logger.Error(errors.WithMessage(err, "release openvpn management hold"))
+		}
+
+		select {
+		case err = <-done:
+			if ctx.Err() == nil {
+				metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+				logger.Error(errors.WithMessage(err, "lost openvpn management interface, reconnecting"))
+			}
+		case <-ctx.Done():
+		}
+
+		s.mu.Lock()
+		s.management = nil
+		s.mu.Unlock()
+		m.Close()
+	}
+}
+
+func (s *VPNServer) handleClientEvent(event *clientEvent) {
+	client := event.env["common_name"]
+	log := logger.With("client", client, "cid", event.cid, "event", event.kind)
+
+	switch event.kind {
+	case "CONNECT", "REAUTH":
+		s.authenticateClientEvent(log, event)
+	case "ESTABLISHED":
+		s.mu.Lock()
+		s.clientIDs[client] = event.cid
+		s.mu.Unlock()
+		log.With("address", event.env["ifconfig_pool_remote_ip"]).Info("client connection established")
+	case "DISCONNECT":
+		s.mu.Lock()
+		cid, ok := s.clientIDs[client]
+		if ok && cid == event.cid {
+			delete(s.clientIDs, client)
+		}
+		s.mu.Unlock()
+
+		// openvpn drops the old session of a client that reconnects, which must not tear down the new one
+		if ok && cid != event.cid {
+			log.With("current", cid).Info("ignoring disconnect of a superseded client session")
+		} else {
+			log.Info("client disconnected")
+			if err := s.disconnect(client); err != nil {
+				log.With("error", err).Info("failed to disconnect client")
+			}
+		}
+
+		s.mu.Lock()
+		if done, ok := s.killed[event.cid]; ok {
+			close(done)
+			delete(s.killed, event.cid)
+		}
+		s.mu.Unlock()
+	}
+}
+
+// killClient terminates the client's openvpn session and waits for openvpn to report the disconnect.
+// action is the control message sent to the client, RESTART lets it reconnect while HALT tells it to give up.
+// Sessions doorman doesn't know the client id of, e.g. from before it connected to openvpn, are killed by
+// common name, a client openvpn has no session for either fails with codes.NotFound.
+func (s *VPNServer) killClient(ctx context.Context, client, action, message string) error {
+	s.mu.Lock()
+	cid, ok := s.clientIDs[client]
+	if !ok {
+		s.mu.Unlock()
+		return s.killCommonName(client)
+	}
+	done := make(chan struct{})
+	s.killed[cid] = done
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.killed, cid)
+		s.mu.Unlock()
+	}()
+
+	if message != "" {
+		action += "," + message
+	}
+	cmd := fmt.Sprintf("client-kill %s %s", cid, quoteManagementArg(action))
+	if _, err := s.managementCommand(cmd); err != nil {
+		err = errors.WithMessage(err, "kill openvpn session")
+		logger.With("client", client, "cid", cid).Error(err)
+		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+		return err
+	}
+
+	select {
+	case <-done:
+		logger.With("client", client, "cid", cid).Info("killed openvpn session")
+		return nil
+	case <-time.After(clientKillTimeout):
+		err := errors.New("timed out waiting for openvpn to disconnect client")
+		logger.With("client", client, "cid", cid).Error(err)
+		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// killCommonName kills all openvpn sessions of the common name. openvpn doesn't tell which client ids it
+// killed, so the disconnects are handled when openvpn reports them.
+func (s *VPNServer) killCommonName(client string) error {
+	reply, err := s.managementCommand("kill " + quoteManagementArg(client))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			logger.With("client", client).Info("client has no openvpn session, nothing to kill")
+			return status.Errorf(codes.NotFound, "client %s has no openvpn session", client)
+		}
+		err = errors.WithMessage(err, "kill openvpn sessions")
+		logger.With("client", client).Error(err)
+		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+		return err
+	}
+	logger.With("client", client, "reply", reply).Info("killed openvpn sessions by common name")
+	return nil
+}
+
+func (s *VPNServer) authenticateClientEvent(log log.Logger, event *clientEvent) {
+	client := event.env["common_name"]
+	connectingIP := event.env["untrusted_ip"]
+	if connectingIP == "" {
+		connectingIP = event.env["untrusted_ip6"]
+	}
+	log = log.With("address", connectingIP)
+	log.Info("received authenticate request")
+
+	// logins completed out of band, e.g. in a browser, last for the whole session instead of every renegotiation
+	s.mu.RLock()
+	_, connected := s.connections[client]
+	s.mu.RUnlock()
+	_, web := s.authenticator.(webAuthenticator)
+
+	ctx := withPendingAuth(context.Background(), func(url string, timeout time.Duration) error {
+		if !strings.Contains(event.env["IV_SSO"], "webauth") {
+			return errors.New("client does not support web authentication")
+		}
+		log.With("url", url).Info("waiting for client to log in")
+		extra := quoteManagementArg("WEB_AUTH::" + url)
+		_, err := s.managementCommand(fmt.Sprintf("client-pending-auth %s %s %s %d", event.cid, event.kid, extra, int(timeout.Seconds())))
+		return err
+	})
+
+	cmd := fmt.Sprintf("client-auth-nt %s %s", event.cid, event.kid)
+	authenticated := false
+	if client == "" {
+		cmd = fmt.Sprintf("client-deny %s %s %s", event.cid, event.kid, quoteManagementArg("no OpenVPN client supplied"))
+	} else if event.kind == "REAUTH" && web && connected {
+		log.Info("client renegotiated, keeping its login")
+	} else if err := s.authenticateWithin(ctx, log, client, connectingIP, event.env["username"], event.env["password"]); err != nil {
+		cmd = fmt.Sprintf("client-deny %s %s %s", event.cid, event.kid, quoteManagementArg(err.Error()))
+	} else {
+		s.mu.Lock()
+		s.clientIDs[client] = event.cid
+		s.mu.Unlock()
+		authenticated = true
+	}
+
+	if _, err := s.managementCommand(cmd); err != nil {
+		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+		log.Error(errors.WithMessage(err, "answer openvpn authentication"))
+		if authenticated {
+			s.abandonSession(log, client, event.cid)
+		}
+	}
+}
+
+// abandonSession releases what authenticating the session set up, when openvpn could not be told about it, e.g. as
+// the client is gone already. A newer session of the client is left alone.
+func (s *VPNServer) abandonSession(log log.Logger, client, cid string) {
+	s.mu.Lock()
+	current, ok := s.clientIDs[client]
+	if ok && current == cid {
+		delete(s.clientIDs, client)
+	}
+	s.mu.Unlock()
+	if !ok || current != cid {
+		return
+	}
+
+	log.Info("abandoning client session openvpn was not told about")
+	if err := s.disconnect(client); err != nil {
+		log.With("error", err).Info("failed to disconnect client")
+	}
+}
+
+// managementCommand runs cmd on the openvpn management interface, if doorman is connected to it.
+func (s *VPNServer) managementCommand(cmd string) (string, error) {
+	s.mu.RLock()
+	m := s.management
+	s.mu.RUnlock()
+
+	if m == nil {
+		return "", errors.New("not connected to openvpn management interface")
+	}
+	return m.command(cmd)
+}
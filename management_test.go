@@ -0,0 +1,242 @@
+package doorman
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	pb "github.com/equinix/doorman/protobuf"
+	"github.com/packethost/pkg/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestManagementClient(t *testing.T) {
+	logger = log.Test(t, "doorman")
+
+	server, conn := net.Pipe()
+	defer server.Close()
+
+	events := make(chan *clientEvent, 1)
+	m := &managementClient{
+		conn:    conn,
+		handler: func(event *clientEvent) { events <- event },
+		replies: make(chan string, 1),
+	}
+	defer m.Close()
+	go m.run()
+
+	go func() {
+		lines := []string{
+			">INFO:OpenVPN Management Interface Version 1 -- type 'help' for more info",
+			">CLIENT:CONNECT,7,1",
+			">CLIENT:ENV,common_name=client1",
+			">CLIENT:ENV,untrusted_ip=24.255.233.90",
+			">CLIENT:ENV,password=123456pass=word",
+			">CLIENT:ENV,END",
+		}
+		for _, line := range lines {
+			server.Write([]byte(line + "\n"))
+		}
+	}()
+
+	select {
+	case event := <-events:
+		if event.kind != "CONNECT" || event.cid != "7" || event.kid != "1" {
+			t.Fatalf("unexpected event: %+v", event)
+		}
+		if event.env["common_name"] != "client1" || event.env["untrusted_ip"] != "24.255.233.90" {
+			t.Fatalf("unexpected event env: %v", event.env)
+		}
+		if event.env["password"] != "123456pass=word" {
+			t.Fatalf("expecting env values to keep their '=', got: %s", event.env["password"])
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for client event")
+	}
+
+	go func() {
+		scanner := bufio.NewScanner(server)
+		for scanner.Scan() {
+			switch scanner.Text() {
+			case "client-auth-nt 7 1":
+				// a notification in between the command and its reply must not be taken as the reply
+				server.Write([]byte(">CLIENT:ESTABLISHED,7\n>CLIENT:ENV,END\n"))
+				server.Write([]byte("SUCCESS: client-auth command succeeded\n"))
+			default:
+				server.Write([]byte("ERROR: unknown command, enter 'help' for more options\n"))
+			}
+		}
+	}()
+
+	reply, err := m.command("client-auth-nt 7 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply != "client-auth command succeeded" {
+		t.Fatalf("unexpected reply: %s", reply)
+	}
+
+	if _, err := m.command("bogus"); err == nil {
+		t.Fatal("expecting an error reply")
+	}
+}
+
+func TestQuoteManagementArg(t *testing.T) {
+	got := quoteManagementArg(`invalid "password" \ token`)
+	want := `"invalid \"password\" \\ token"`
+	if got != want {
+		t.Fatalf("expected: %s, got: %s", want, got)
+	}
+}
+
+func TestQuoteManagementArgLineBreaks(t *testing.T) {
+	got := quoteManagementArg("invalid twofactor token: x\r\nkill other\n")
+	want := `"invalid twofactor token: x  kill other "`
+	if got != want {
+		t.Fatalf("expected: %s, got: %s", want, got)
+	}
+}
+
+func TestManagementClientOrdersEventsOfOneClient(t *testing.T) {
+	handled := make(chan string, 4)
+	release := make(chan struct{})
+	m := &managementClient{handler: func(event *clientEvent) {
+		// the first session takes a while to authenticate
+		if event.cid == "1" && event.kind == "CONNECT" {
+			<-release
+		}
+		handled <- event.cid + " " + event.kind
+	}}
+
+	m.dispatch(&clientEvent{kind: "CONNECT", cid: "1"})
+	m.dispatch(&clientEvent{kind: "DISCONNECT", cid: "1"})
+	m.dispatch(&clientEvent{kind: "CONNECT", cid: "2"})
+	// other clients are not held up
+	if got := <-handled; got != "2 CONNECT" {
+		t.Fatalf("expected the other client to be handled first, got %s", got)
+	}
+	close(release)
+	for _, want := range []string{"1 CONNECT", "1 DISCONNECT"} {
+		if got := <-handled; got != want {
+			t.Fatalf("expected %s, got %s", want, got)
+		}
+	}
+}
+
+func TestAuthenticateClientEventUnanswered(t *testing.T) {
+	logger = log.Test(t, "doorman")
+
+	s := newTestServer(t)
+	s.authenticator = &fixedAuthenticator{password: "secret", id: &identity{user: "laptop", backend: authLocal, routes: []string{"10.88.111.0/25"}}}
+	s.clientIDs = map[string]string{}
+	s.killed = map[string]chan struct{}{}
+
+	// without a management interface openvpn can't be told, the session is abandoned
+	event := &clientEvent{kind: "CONNECT", cid: "7", kid: "0", env: map[string]string{
+		"common_name": "laptop", "untrusted_ip": "192.0.2.1", "username": "laptop", "password": "123456secret",
+	}}
+	s.authenticateClientEvent(logger, event)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.clientIDs) != 0 || len(s.connections) != 0 {
+		t.Fatalf("expected the session to be released, got %v %v", s.clientIDs, s.connections)
+	}
+}
+
+func TestKillClient(t *testing.T) {
+	logger = log.Test(t, "doorman")
+
+	server, conn := net.Pipe()
+	defer server.Close()
+
+	s := &VPNServer{
+		clientIDs:   map[string]string{"client1": "7"},
+		killed:      map[string]chan struct{}{},
+		connections: map[string]*pb.Connection{},
+	}
+	s.management = &managementClient{
+		conn:    conn,
+		handler: s.handleClientEvent,
+		replies: make(chan string, 1),
+	}
+	defer s.management.Close()
+	go s.management.run()
+
+	commands := make(chan string, 1)
+	go func() {
+		scanner := bufio.NewScanner(server)
+		for scanner.Scan() {
+			commands <- scanner.Text()
+			server.Write([]byte("SUCCESS: client-kill command succeeded\n"))
+			server.Write([]byte(">CLIENT:DISCONNECT,7\n>CLIENT:ENV,common_name=client1\n>CLIENT:ENV,END\n"))
+		}
+	}()
+
+	if err := s.killClient(context.Background(), "client1", "HALT", "certificate revoked"); err != nil {
+		t.Fatal(err)
+	}
+	if cmd := <-commands; cmd != `client-kill 7 "HALT,certificate revoked"` {
+		t.Fatalf("unexpected command: %s", cmd)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, ok := s.clientIDs["client1"]; ok {
+		t.Fatal("expecting the killed session to be forgotten")
+	}
+	if len(s.killed) != 0 {
+		t.Fatalf("expecting no pending kills, got: %d", len(s.killed))
+	}
+}
+
+func TestKillClientWithoutSession(t *testing.T) {
+	logger = log.Test(t, "doorman")
+
+	server, conn := net.Pipe()
+	defer server.Close()
+
+	s := &VPNServer{
+		clientIDs:   map[string]string{},
+		killed:      map[string]chan struct{}{},
+		connections: map[string]*pb.Connection{},
+	}
+	s.management = &managementClient{
+		conn:    conn,
+		handler: s.handleClientEvent,
+		replies: make(chan string, 1),
+	}
+	defer s.management.Close()
+	go s.management.run()
+
+	commands := make(chan string, 1)
+	go func() {
+		scanner := bufio.NewScanner(server)
+		for scanner.Scan() {
+			cmd := scanner.Text()
+			commands <- cmd
+			if cmd == `kill "client1"` {
+				server.Write([]byte("SUCCESS: common name 'client1' found, 1 client(s) killed\n"))
+			} else {
+				server.Write([]byte("ERROR: common name 'client2' not found\n"))
+			}
+		}
+	}()
+
+	// sessions doorman has no client id for are killed by common name
+	if err := s.killClient(context.Background(), "client1", "RESTART", ""); err != nil {
+		t.Fatal(err)
+	}
+	if cmd := <-commands; cmd != `kill "client1"` {
+		t.Fatalf("unexpected command: %s", cmd)
+	}
+
+	err := s.killClient(context.Background(), "client2", "RESTART", "")
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expecting a client without session to be not found, got: %v", err)
+	}
+	<-commands
+}
@@ -6,24 +6,48 @@ import (
 
 var (
 	ActiveClientTotal               prometheus.Gauge
+	AuditEventTotal                 *prometheus.CounterVec
 	AuthenticationDuration          prometheus.Histogram
 	AuthenticationFailureTotalCount prometheus.Counter
+	AuthenticationLockedOutTotal    prometheus.Counter
 	AuthenticationSuccessTotalCount prometheus.Counter
 	ErrorTotal                      *prometheus.CounterVec
+	FirewallDrift                   *prometheus.GaugeVec
+	FirewallRepairTotal             *prometheus.CounterVec
+	SubnetCacheTotal                *prometheus.CounterVec
+	SubnetFetchDuration             prometheus.Histogram
+	SubnetProjectFailuresTotal      prometheus.Counter
+	SubnetsSkippedTotal             *prometheus.CounterVec
 )
 
 func Init() {
 	initActiveClientTotalCounter()
+	initAuditEventTotal()
 	initAuthenticationDuration()
 	initAuthenticationFailureTotalCount()
+	initAuthenticationLockedOutTotal()
 	initAuthenticationSuccessTotalCount()
 	initErrorTotalCounter()
+	initFirewallDrift()
+	initFirewallRepairTotal()
+	initSubnetCacheTotal()
+	initSubnetFetchDuration()
+	initSubnetProjectFailuresTotal()
+	initSubnetsSkippedTotal()
 
 	prometheus.MustRegister(ActiveClientTotal)
+	prometheus.MustRegister(AuditEventTotal)
 	prometheus.MustRegister(AuthenticationDuration)
 	prometheus.MustRegister(AuthenticationFailureTotalCount)
+	prometheus.MustRegister(AuthenticationLockedOutTotal)
 	prometheus.MustRegister(AuthenticationSuccessTotalCount)
 	prometheus.MustRegister(ErrorTotal)
+	prometheus.MustRegister(FirewallDrift)
+	prometheus.MustRegister(FirewallRepairTotal)
+	prometheus.MustRegister(SubnetCacheTotal)
+	prometheus.MustRegister(SubnetFetchDuration)
+	prometheus.MustRegister(SubnetProjectFailuresTotal)
+	prometheus.MustRegister(SubnetsSkippedTotal)
 
 }
 
@@ -35,6 +59,14 @@ func initActiveClientTotalCounter() {
 	})
 }
 
+func initAuditEventTotal() {
+	AuditEventTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "audit_events",
+		Subsystem: "doorman",
+		Help:      "Number of total security relevant events, by event.",
+	}, []string{"event"})
+}
+
 func initAuthenticationDuration() {
 	buckets := []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
 
@@ -52,6 +84,13 @@ func initAuthenticationFailureTotalCount() {
 	})
 }
 
+func initAuthenticationLockedOutTotal() {
+	AuthenticationLockedOutTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "authentication_locked_out",
+		Help: "Number of total authentication attempts refused because the user or address was locked out.",
+	})
+}
+
 func initAuthenticationSuccessTotalCount() {
 	AuthenticationSuccessTotalCount = prometheus.NewCounter(prometheus.CounterOpts{
 		Name: "authentication_success",
@@ -73,8 +112,72 @@ func initErrorTotalCounter() {
 	initCounterLabels(ErrorTotal, labelValues)
 }
 
+// firewallDriftKinds are the kinds of differences between the firewall and the connected clients.
+var firewallDriftKinds = []string{"chains", "missing", "orphaned", "rules", "routes"}
+
+func initFirewallDrift() {
+	FirewallDrift = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "firewall_drift",
+		Subsystem: "doorman",
+		Help:      "Number of differences between the firewall and the connected clients found by the last check.",
+	}, []string{"kind"})
+
+	for _, kind := range firewallDriftKinds {
+		FirewallDrift.WithLabelValues(kind)
+	}
+}
+
+func initFirewallRepairTotal() {
+	FirewallRepairTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "firewall_repairs",
+		Subsystem: "doorman",
+		Help:      "Number of total differences between the firewall and the connected clients repaired.",
+	}, []string{"kind"})
+
+	labelValues := []prometheus.Labels{}
+	for _, kind := range firewallDriftKinds {
+		labelValues = append(labelValues, prometheus.Labels{"kind": kind})
+	}
+
+	initCounterLabels(FirewallRepairTotal, labelValues)
+}
+
 func initCounterLabels(m *prometheus.CounterVec, l []prometheus.Labels) {
 	for _, labels := range l {
 		m.With(labels)
 	}
 }
+
+func initSubnetCacheTotal() {
+	SubnetCacheTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "subnet_cache_lookups",
+		Subsystem: "doorman",
+		Help:      "Number of total lookups of cached subnets, by result: hit, stale or miss.",
+	}, []string{"result"})
+}
+
+func initSubnetFetchDuration() {
+	buckets := []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+	SubnetFetchDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:      "subnet_fetch_duration",
+		Subsystem: "doorman",
+		Help:      "Histogram of time to fetch a user's subnets on a cache miss or refresh in seconds.",
+		Buckets:   buckets,
+	})
+}
+
+func initSubnetProjectFailuresTotal() {
+	SubnetProjectFailuresTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name:      "subnet_project_failures",
+		Subsystem: "doorman",
+		Help:      "Number of total projects whose private subnets could not be fetched, leaving them out of a client's routes.",
+	})
+}
+
+func initSubnetsSkippedTotal() {
+	SubnetsSkippedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "subnets_skipped",
+		Subsystem: "doorman",
+		Help:      "Number of total private subnets not pushed to clients as they can't be reached, by reason.",
+	}, []string{"reason"})
+}
@@ -0,0 +1,176 @@
+package doorman
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const defaultVPNPools = "192.168.127.0/24"
+
+const (
+	defaultAllocationsPageSize = 1000
+	maxAllocationsPageSize     = 10000 // keeps a page well below grpc's default 4MB message limit
+)
+
+// ipv6PoolMaxPrefix is the longest ipv6 pool prefix that still leaves room for an embedded ipv4 address.
+const ipv6PoolMaxPrefix = 96
+
+// ipPool is one of the address ranges vpn clients get their address from.
+// The network and broadcast addresses are never handed out and neither is the first host address,
+// which is the pool's gateway (the openvpn server's own address in the primary pool).
+type ipPool struct {
+	network *net.IPNet
+}
+
+// parseIPPools parses a comma separated list of CIDRs, the first one is the primary pool that openvpn's
+// server directive is configured with.
+func parseIPPools(cidrs string) ([]*ipPool, error) {
+	var pools []*ipPool
+	for _, cidr := range strings.Split(cidrs, ",") {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, errors.Wrap(err, "parse vpn pool")
+		}
+		if network.IP.To4() == nil {
+			return nil, errors.Errorf("vpn pool %s is not an ipv4 network", cidr)
+		}
+		if ones, bits := network.Mask.Size(); bits-ones < 2 {
+			return nil, errors.Errorf("vpn pool %s is too small, it needs to be at least a /30", cidr)
+		}
+
+		for _, pool := range pools {
+			if pool.network.Contains(network.IP) || network.Contains(pool.network.IP) {
+				return nil, errors.Errorf("vpn pool %s overlaps with %s", cidr, pool)
+			}
+		}
+
+		pools = append(pools, &ipPool{network: network})
+	}
+	if len(pools) == 0 {
+		return nil, errors.New("no vpn pools configured")
+	}
+	return pools, nil
+}
+
+// parseIPv6Pool parses the ipv6 pool clients get their address from.
+// Clients are not allocated ipv6 addresses separately, their ipv4 address is embedded in the lower 32 bits of the pool
+// instead so pinned and sticky addresses carry over.
+func parseIPv6Pool(cidr string) (*ipPool, error) {
+	_, network, err := net.ParseCIDR(strings.TrimSpace(cidr))
+	if err != nil {
+		return nil, errors.Wrap(err, "parse vpn ipv6 pool")
+	}
+	if network.IP.To4() != nil {
+		return nil, errors.Errorf("vpn ipv6 pool %s is not an ipv6 network", cidr)
+	}
+	if ones, _ := network.Mask.Size(); ones > ipv6PoolMaxPrefix {
+		return nil, errors.Errorf("vpn ipv6 pool %s is too small, it needs to be at least a /%d", cidr, ipv6PoolMaxPrefix)
+	}
+	return &ipPool{network: network}, nil
+}
+
+func (p *ipPool) String() string {
+	return p.network.String()
+}
+
+func (p *ipPool) netmask() string {
+	return net.IP(p.network.Mask).String()
+}
+
+func (p *ipPool) gateway() net.IP {
+	ip := p.first()
+	nextIP(ip)
+	return ip
+}
+
+func (p *ipPool) first() net.IP {
+	ip := p.network.IP
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	return append(net.IP(nil), ip...)
+}
+
+func (p *ipPool) broadcast() net.IP {
+	ip := p.first()
+	for i := range ip {
+		ip[i] |= ^p.network.Mask[i]
+	}
+	return ip
+}
+
+// each calls fn for every address of the pool that can be handed out to a client, until fn returns false.
+func (p *ipPool) each(fn func(ip net.IP) bool) {
+	broadcast := p.broadcast()
+	ip := p.gateway()
+	for nextIP(ip); p.network.Contains(ip) && !ip.Equal(broadcast); nextIP(ip) {
+		if !fn(ip) {
+			return
+		}
+	}
+}
+
+// allocatable reports whether ip is one of the addresses of the pool that can be handed out to a client.
+func (p *ipPool) allocatable(ip net.IP) bool {
+	return p.network.Contains(ip) && !ip.Equal(p.first()) && !ip.Equal(p.gateway()) && !ip.Equal(p.broadcast())
+}
+
+// embed returns the address of the ipv6 pool with ip4 as its lower 32 bits.
+func (p *ipPool) embed(ip4 net.IP) net.IP {
+	ip := p.first()
+	copy(ip[net.IPv6len-net.IPv4len:], ip4.To4())
+	return ip
+}
+
+// poolOf returns the pool ip belongs to, or nil.
+func poolOf(pools []*ipPool, ip string) *ipPool {
+	addr := net.ParseIP(ip)
+	for _, pool := range pools {
+		if pool.network.Contains(addr) {
+			return pool
+		}
+	}
+	return nil
+}
+
+// writePoolConfig writes the openvpn server directives matching the configured pools, pool6 is nil unless ipv6 is enabled.
+// Addresses are always assigned by doorman, so openvpn's own pool is disabled.
+func writePoolConfig(w io.Writer, pools []*ipPool, pool6 *ipPool) error {
+	primary := pools[0]
+	if _, err := fmt.Fprintln(w, "# generated by doorman from "+doormanVPNPools+" and "+doormanVPNIPv6Pool+", do not edit"); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(w, "server", primary.network.IP, primary.netmask(), "nopool"); err != nil {
+		return err
+	}
+	for _, pool := range pools[1:] {
+		if _, err := fmt.Fprintln(w, "route", pool.network.IP, pool.netmask()); err != nil {
+			return err
+		}
+	}
+	if pool6 != nil {
+		if _, err := fmt.Fprintln(w, "server-ipv6", pool6); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// http://play.golang.org/p/m8TNTtygK0
+func nextIP(ip net.IP) {
+	for j := len(ip) - 1; j >= 0; j-- {
+		ip[j]++
+		if ip[j] > 0 {
+			break
+		}
+	}
+}
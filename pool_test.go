@@ -0,0 +1,408 @@
+package doorman
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+
+	pb "github.com/equinix/doorman/protobuf"
+	"github.com/packethost/pkg/log"
+)
+
+func TestParseIPPools(t *testing.T) {
+	tests := map[string]struct {
+		cidrs string
+		pools []string
+		err   bool
+	}{
+		"default":      {cidrs: defaultVPNPools, pools: []string{"192.168.127.0/24"}},
+		"multiple":     {cidrs: "10.200.0.0/16, 10.201.0.0/22,", pools: []string{"10.200.0.0/16", "10.201.0.0/22"}},
+		"host bits":    {cidrs: "10.200.1.7/16", pools: []string{"10.200.0.0/16"}},
+		"empty":        {cidrs: " , ", err: true},
+		"invalid":      {cidrs: "10.200.0.0", err: true},
+		"ipv6":         {cidrs: "fd00::/64", err: true},
+		"too small":    {cidrs: "10.200.0.0/31", err: true},
+		"overlapping":  {cidrs: "10.200.0.0/16,10.200.8.0/24", err: true},
+		"overlapped":   {cidrs: "10.200.8.0/24,10.200.0.0/16", err: true},
+		"smallest ok":  {cidrs: "10.200.0.0/30", pools: []string{"10.200.0.0/30"}},
+		"adjacent ok":  {cidrs: "10.200.0.0/24,10.200.1.0/24", pools: []string{"10.200.0.0/24", "10.200.1.0/24"}},
+		"duplicate":    {cidrs: "10.200.0.0/24,10.200.0.0/24", err: true},
+		"no separator": {cidrs: "10.200.0.0/24 10.201.0.0/24", err: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			pools, err := parseIPPools(test.cidrs)
+			if test.err {
+				if err == nil {
+					t.Fatalf("expected an error, got pools: %v", pools)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pools) != len(test.pools) {
+				t.Fatalf("expected %d pools, got: %v", len(test.pools), pools)
+			}
+			for i := range pools {
+				if pools[i].String() != test.pools[i] {
+					t.Fatalf("expected pool %s, got: %s", test.pools[i], pools[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIPPoolEach(t *testing.T) {
+	pools, err := parseIPPools("10.200.0.0/22")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := pools[0]
+
+	if pool.netmask() != "255.255.252.0" {
+		t.Fatalf("unexpected netmask: %s", pool.netmask())
+	}
+	if pool.gateway().String() != "10.200.0.1" {
+		t.Fatalf("unexpected gateway: %s", pool.gateway())
+	}
+
+	var ips []string
+	pool.each(func(ip net.IP) bool {
+		ips = append(ips, ip.String())
+		return true
+	})
+	// network, gateway and broadcast addresses are never handed out
+	if len(ips) != 1021 {
+		t.Fatalf("expected 1021 addresses, got: %d", len(ips))
+	}
+	if ips[0] != "10.200.0.2" || ips[255] != "10.200.1.1" || ips[len(ips)-1] != "10.200.3.254" {
+		t.Fatalf("unexpected addresses: %s, %s ... %s", ips[0], ips[255], ips[len(ips)-1])
+	}
+
+	count := 0
+	pool.each(func(ip net.IP) bool {
+		count++
+		return count < 3
+	})
+	if count != 3 {
+		t.Fatalf("expected each to stop after 3 addresses, got: %d", count)
+	}
+
+	if poolOf(pools, "10.200.3.7") != pool || poolOf(pools, "10.200.4.1") != nil {
+		t.Fatal("unexpected poolOf result")
+	}
+}
+
+func TestWritePoolConfig(t *testing.T) {
+	pools, err := parseIPPools("10.200.0.0/16,10.201.0.0/22")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool6, err := parseIPv6Pool("fd00:d00a::/64")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := writePoolConfig(&buf, pools, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := `# generated by doorman from DOORMAN_VPN_POOLS and DOORMAN_VPN_IPV6_POOL, do not edit
+server 10.200.0.0 255.255.0.0 nopool
+route 10.201.0.0 255.255.252.0
+`
+	if buf.String() != want {
+		t.Fatalf("expected:\n%s\ngot:\n%s", want, buf.String())
+	}
+
+	buf.Reset()
+	if err := writePoolConfig(&buf, pools, pool6); err != nil {
+		t.Fatal(err)
+	}
+	want += "server-ipv6 fd00:d00a::/64\n"
+	if buf.String() != want {
+		t.Fatalf("expected:\n%s\ngot:\n%s", want, buf.String())
+	}
+}
+
+func TestParseIPv6Pool(t *testing.T) {
+	for _, cidr := range []string{"10.200.0.0/16", "fd00:d00a::/97", "fd00:d00a::"} {
+		if _, err := parseIPv6Pool(cidr); err == nil {
+			t.Fatalf("expected %s to be rejected", cidr)
+		}
+	}
+
+	pool6, err := parseIPv6Pool("fd00:d00a::/96")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pool6.gateway().String() != "fd00:d00a::1" {
+		t.Fatalf("unexpected gateway: %s", pool6.gateway())
+	}
+	if ip := pool6.embed(net.ParseIP("192.168.127.2")).String(); ip != "fd00:d00a::c0a8:7f02" {
+		t.Fatalf("unexpected embedded address: %s", ip)
+	}
+}
+
+func TestPushRoute(t *testing.T) {
+	tests := map[string]string{
+		"10.88.111.0/25":  `push "route 10.88.111.0 255.255.255.128"` + "\n",
+		"fd00:8a0:1::/56": `push "route-ipv6 fd00:8a0:1::/56"` + "\n",
+	}
+	for cidr, want := range tests {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if route := pushRoute(network); route != want {
+			t.Fatalf("expected route: %s, got: %s", want, route)
+		}
+	}
+
+	routes := ipv4Routes([]*pb.Route{{Cidr: "10.88.111.0/25"}, {Cidr: "fd00:8a0:1::/56"}})
+	if len(routes) != 1 || routes[0].Cidr != "10.88.111.0/25" {
+		t.Fatalf("unexpected routes: %v", routes)
+	}
+}
+
+func TestReserveNextAvailableIP(t *testing.T) {
+	logger = log.Test(t, "doorman")
+
+	store := newTestStateStore(t)
+
+	pools, err := parseIPPools("10.200.0.0/30,10.201.0.0/30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &VPNServer{
+		store:       store,
+		pools:       pools,
+		allocations: map[string]*pb.Allocation{},
+		pins:        map[string]*pb.Allocation{},
+		sticky:      map[string]string{},
+	}
+
+	first, err := s.reserveNextAvailableIP("client1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Ip != "10.200.0.2" || first.Pool != "10.200.0.0/30" {
+		t.Fatalf("unexpected allocation: %v", first)
+	}
+	again, err := s.reserveNextAvailableIP("client1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != first {
+		t.Fatalf("expected the same allocation for the same client, got: %v", again)
+	}
+
+	// the primary pool only has a single address, so the next client spills over into the second pool
+	second, err := s.reserveNextAvailableIP("client2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Ip != "10.201.0.2" || second.Pool != "10.201.0.0/30" {
+		t.Fatalf("unexpected allocation: %v", second)
+	}
+
+	if _, err := s.reserveNextAvailableIP("client3"); err == nil {
+		t.Fatal("expected an error once all pools are exhausted")
+	}
+
+	var ccd bytes.Buffer
+	s.writeClientAddress(&ccd, second)
+	want := "ifconfig-push 10.201.0.2 255.255.255.252\npush \"route-gateway 10.201.0.1\"\n"
+	if ccd.String() != want {
+		t.Fatalf("expected:\n%s\ngot:\n%s", want, ccd.String())
+	}
+
+	s.pool6, err = parseIPv6Pool("fd00:d00a::/64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ccd.Reset()
+	s.writeClientAddress(&ccd, &pb.Allocation{Ip: first.Ip, Ipv6: s.ipv6For(first.Ip)})
+	want = "ifconfig-push 10.200.0.2 255.255.255.252\nifconfig-ipv6-push fd00:d00a::ac8:2/64 fd00:d00a::1\n"
+	if ccd.String() != want {
+		t.Fatalf("expected:\n%s\ngot:\n%s", want, ccd.String())
+	}
+	s.pool6 = nil
+
+	s.freeIPAllocation("client1")
+	third, err := s.reserveNextAvailableIP("client3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third.Ip != "10.200.0.2" {
+		t.Fatalf("expected the freed address to be handed out again, got: %v", third)
+	}
+
+	stored, err := store.allocations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 2 {
+		t.Fatalf("expected 2 stored allocations, got: %v", stored)
+	}
+}
+
+func TestStickyAndPinnedIPs(t *testing.T) {
+	logger = log.Test(t, "doorman")
+
+	store := newTestStateStore(t)
+
+	pools, err := parseIPPools("10.200.0.0/29")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &VPNServer{
+		store:       store,
+		pools:       pools,
+		stickyIPs:   true,
+		allocations: map[string]*pb.Allocation{},
+		pins:        map[string]*pb.Allocation{},
+		sticky:      map[string]string{},
+		connections: map[string]*pb.Connection{},
+	}
+
+	for _, ip := range []string{"10.200.0.0", "10.200.0.1", "10.200.0.7", "10.200.1.2", "bogus"} {
+		if _, err := s.PinAllocation(context.Background(), &pb.PinAllocationRequest{Client: "client1", Ip: ip}); err == nil {
+			t.Fatalf("expected pinning %s to fail", ip)
+		}
+	}
+	if _, err := s.PinAllocation(context.Background(), &pb.PinAllocationRequest{Client: "client1", Ip: "10.200.0.5"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.PinAllocation(context.Background(), &pb.PinAllocationRequest{Client: "client2", Ip: "10.200.0.5"}); err == nil {
+		t.Fatal("expected pinning an address pinned to another client to fail")
+	}
+
+	reserve := func(client, want string) {
+		t.Helper()
+		allocation, err := s.reserveNextAvailableIP(client)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if allocation.Ip != want {
+			t.Fatalf("expected %s to get %s, got: %s", client, want, allocation.Ip)
+		}
+	}
+
+	reserve("client2", "10.200.0.2")
+	reserve("client3", "10.200.0.3")
+	reserve("client1", "10.200.0.5")
+	s.freeIPAllocation("client2")
+	s.freeIPAllocation("client3")
+
+	// the addresses client2 and client3 last had are kept for them while there are others left
+	reserve("client4", "10.200.0.4")
+	reserve("client2", "10.200.0.2")
+	reserve("client5", "10.200.0.6")
+	reserve("client6", "10.200.0.3")
+	// the pinned address is never handed out to anyone else, even when it is free
+	s.freeIPAllocation("client1")
+	if _, err := s.reserveNextAvailableIP("client7"); err == nil {
+		t.Fatal("expected the pool to be exhausted")
+	}
+
+	list, err := s.ListAllocations(context.Background(), &pb.ListAllocationsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, allocation := range list.Allocations {
+		if allocation.Pinned != (allocation.Ip == "10.200.0.5") {
+			t.Fatalf("unexpected pinned flag: %v", allocation)
+		}
+	}
+
+	if _, err := s.UnpinAllocation(context.Background(), &pb.UnpinAllocationRequest{Client: "client1"}); err != nil {
+		t.Fatal(err)
+	}
+	reserve("client7", "10.200.0.5")
+
+	pins, err := store.pins()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pins) != 0 {
+		t.Fatalf("expected no stored pins, got: %v", pins)
+	}
+	sticky, err := store.sticky()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sticky) != 5 {
+		t.Fatalf("expected 5 sticky addresses, got: %v", sticky)
+	}
+}
+
+func TestListAllocationsPages(t *testing.T) {
+	logger = log.Test(t, "doorman")
+
+	pools, err := parseIPPools("10.201.0.0/29,10.200.0.0/24")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &VPNServer{
+		pools: pools,
+		allocations: map[string]*pb.Allocation{
+			"10.201.0.3": {Client: "client1", Ip: "10.201.0.3", Pool: "10.201.0.0/29"},
+			"10.200.0.7": {Client: "client2", Ip: "10.200.0.7", Pool: "10.200.0.0/24"},
+		},
+		pins: map[string]*pb.Allocation{},
+	}
+
+	list := func(req *pb.ListAllocationsRequest) []*pb.Allocation {
+		t.Helper()
+		var all []*pb.Allocation
+		for pages := 0; ; pages++ {
+			if pages > 10 {
+				t.Fatal("expected the pages to end")
+			}
+			resp, err := s.ListAllocations(context.Background(), req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.Allocations) > int(req.PageSize) {
+				t.Fatalf("expected at most %d allocations, got %d", req.PageSize, len(resp.Allocations))
+			}
+			all = append(all, resp.Allocations...)
+			if resp.NextPageToken == "" {
+				return all
+			}
+			req.PageToken = resp.NextPageToken
+		}
+	}
+
+	// pools are listed in their order, 5 addresses of the /29 and 253 of the /24
+	all := list(&pb.ListAllocationsRequest{PageSize: 100})
+	seen := map[string]bool{}
+	for _, allocation := range all {
+		if seen[allocation.Ip] {
+			t.Fatalf("%s listed twice", allocation.Ip)
+		}
+		seen[allocation.Ip] = true
+	}
+	if len(all) != 258 || all[0].Ip != "10.201.0.2" || all[5].Ip != "10.200.0.2" || all[257].Ip != "10.200.0.254" {
+		t.Fatalf("unexpected allocations: %d, first %v, last %v", len(all), all[0], all[len(all)-1])
+	}
+	if all[1].Client != "client1" {
+		t.Fatalf("expected 10.201.0.3 to be allocated to client1, got %v", all[1])
+	}
+
+	allocated := list(&pb.ListAllocationsRequest{OnlyAllocated: true, PageSize: 1})
+	if len(allocated) != 2 || allocated[0].Client != "client2" || allocated[1].Client != "client1" {
+		t.Fatalf("unexpected allocated addresses: %v", allocated)
+	}
+
+	for _, token := range []string{"bogus", "10.202.0.1"} {
+		if _, err := s.ListAllocations(context.Background(), &pb.ListAllocationsRequest{PageToken: token}); err == nil {
+			t.Fatalf("expected page token %s to be refused", token)
+		}
+	}
+}
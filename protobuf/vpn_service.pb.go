@@ -55,6 +55,7 @@ func (ClientStatus) EnumDescriptor() ([]byte, []int) {
 // MARK: disconnect request/response
 type DisconnectRequest struct {
 	Client               string   `protobuf:"bytes,1,opt,name=client,proto3" json:"client,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -92,6 +93,13 @@ func (m *DisconnectRequest) GetClient() string {
 	return ""
 }
 
+func (m *DisconnectRequest) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
 type DisconnectResponse struct {
 	Status               int32    `protobuf:"varint,1,opt,name=status,proto3" json:"status,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -135,6 +143,8 @@ func (m *DisconnectResponse) GetStatus() int32 {
 type ListAllocationsRequest struct {
 	Client               string   `protobuf:"bytes,1,opt,name=client,proto3" json:"client,omitempty"`
 	OnlyAllocated        bool     `protobuf:"varint,2,opt,name=only_allocated,json=onlyAllocated,proto3" json:"only_allocated,omitempty"`
+	PageSize             int32    `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken            string   `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -179,8 +189,23 @@ func (m *ListAllocationsRequest) GetOnlyAllocated() bool {
 	return false
 }
 
+func (m *ListAllocationsRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *ListAllocationsRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
 type ListAllocationsResponse struct {
 	Allocations          []*Allocation `protobuf:"bytes,1,rep,name=allocations,proto3" json:"allocations,omitempty"`
+	NextPageToken        string        `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
 	XXX_unrecognized     []byte        `json:"-"`
 	XXX_sizecache        int32         `json:"-"`
@@ -218,6 +243,13 @@ func (m *ListAllocationsResponse) GetAllocations() []*Allocation {
 	return nil
 }
 
+func (m *ListAllocationsResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
 // MARK: list connections request/response
 type ListConnectionsResponse struct {
 	Total                int32         `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
@@ -299,9 +331,11 @@ var xxx_messageInfo_ListConnectionsRequest proto.InternalMessageInfo
 
 // MARK: authenticate request/response
 type AuthenticateRequest struct {
-	File                 string   `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
-	Client               string   `protobuf:"bytes,2,opt,name=client,proto3" json:"client,omitempty"`
-	ConnectingIp         string   `protobuf:"bytes,3,opt,name=connecting_ip,json=connectingIp,proto3" json:"connecting_ip,omitempty"`
+	File         string `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	Client       string `protobuf:"bytes,2,opt,name=client,proto3" json:"client,omitempty"`
+	ConnectingIp string `protobuf:"bytes,3,opt,name=connecting_ip,json=connectingIp,proto3" json:"connecting_ip,omitempty"`
+	// auth_control_file of a deferred auth-user-pass-verify script, the decision is written to it in the background
+	ControlFile          string   `protobuf:"bytes,4,opt,name=control_file,json=controlFile,proto3" json:"control_file,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -353,6 +387,13 @@ func (m *AuthenticateRequest) GetConnectingIp() string {
 	return ""
 }
 
+func (m *AuthenticateRequest) GetControlFile() string {
+	if m != nil {
+		return m.ControlFile
+	}
+	return ""
+}
+
 type AuthenticateResponse struct {
 	Status               int32    `protobuf:"varint,1,opt,name=status,proto3" json:"status,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -400,6 +441,10 @@ type Connection struct {
 	Routes               []*Route    `protobuf:"bytes,4,rep,name=routes,proto3" json:"routes,omitempty"`
 	Since                int64       `protobuf:"varint,5,opt,name=since,proto3" json:"since,omitempty"`
 	ConnectingIp         string      `protobuf:"bytes,6,opt,name=connecting_ip,json=connectingIp,proto3" json:"connecting_ip,omitempty"`
+	RealAddress          string      `protobuf:"bytes,7,opt,name=real_address,json=realAddress,proto3" json:"real_address,omitempty"`
+	BytesReceived        int64       `protobuf:"varint,8,opt,name=bytes_received,json=bytesReceived,proto3" json:"bytes_received,omitempty"`
+	BytesSent            int64       `protobuf:"varint,9,opt,name=bytes_sent,json=bytesSent,proto3" json:"bytes_sent,omitempty"`
+	LastRef              int64       `protobuf:"varint,10,opt,name=last_ref,json=lastRef,proto3" json:"last_ref,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
 	XXX_unrecognized     []byte      `json:"-"`
 	XXX_sizecache        int32       `json:"-"`
@@ -472,10 +517,41 @@ func (m *Connection) GetConnectingIp() string {
 	return ""
 }
 
+func (m *Connection) GetRealAddress() string {
+	if m != nil {
+		return m.RealAddress
+	}
+	return ""
+}
+
+func (m *Connection) GetBytesReceived() int64 {
+	if m != nil {
+		return m.BytesReceived
+	}
+	return 0
+}
+
+func (m *Connection) GetBytesSent() int64 {
+	if m != nil {
+		return m.BytesSent
+	}
+	return 0
+}
+
+func (m *Connection) GetLastRef() int64 {
+	if m != nil {
+		return m.LastRef
+	}
+	return 0
+}
+
 // MARK: allocation
 type Allocation struct {
 	Client               string   `protobuf:"bytes,1,opt,name=client,proto3" json:"client,omitempty"`
 	Ip                   string   `protobuf:"bytes,2,opt,name=ip,proto3" json:"ip,omitempty"`
+	Pool                 string   `protobuf:"bytes,3,opt,name=pool,proto3" json:"pool,omitempty"`
+	Pinned               bool     `protobuf:"varint,4,opt,name=pinned,proto3" json:"pinned,omitempty"`
+	Ipv6                 string   `protobuf:"bytes,5,opt,name=ipv6,proto3" json:"ipv6,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -520,315 +596,2178 @@ func (m *Allocation) GetIp() string {
 	return ""
 }
 
-// MARK: Route
-type Route struct {
-	Cidr                 string   `protobuf:"bytes,1,opt,name=cidr,proto3" json:"cidr,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
-}
-
-func (m *Route) Reset()         { *m = Route{} }
-func (m *Route) String() string { return proto.CompactTextString(m) }
-func (*Route) ProtoMessage()    {}
-func (*Route) Descriptor() ([]byte, []int) {
-	return fileDescriptor_9ed45b80aaca82a7, []int{10}
+func (m *Allocation) GetPool() string {
+	if m != nil {
+		return m.Pool
+	}
+	return ""
 }
 
-func (m *Route) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Route.Unmarshal(m, b)
-}
-func (m *Route) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Route.Marshal(b, m, deterministic)
-}
-func (m *Route) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Route.Merge(m, src)
-}
-func (m *Route) XXX_Size() int {
-	return xxx_messageInfo_Route.Size(m)
-}
-func (m *Route) XXX_DiscardUnknown() {
-	xxx_messageInfo_Route.DiscardUnknown(m)
+func (m *Allocation) GetPinned() bool {
+	if m != nil {
+		return m.Pinned
+	}
+	return false
 }
 
-var xxx_messageInfo_Route proto.InternalMessageInfo
-
-func (m *Route) GetCidr() string {
+func (m *Allocation) GetIpv6() string {
 	if m != nil {
-		return m.Cidr
+		return m.Ipv6
 	}
 	return ""
 }
 
-// MARK: create client request/response
-type CreateClientRequest struct {
+// MARK: pin allocation request/response
+type PinAllocationRequest struct {
 	Client               string   `protobuf:"bytes,1,opt,name=client,proto3" json:"client,omitempty"`
-	Force                bool     `protobuf:"varint,2,opt,name=force,proto3" json:"force,omitempty"`
+	Ip                   string   `protobuf:"bytes,2,opt,name=ip,proto3" json:"ip,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *CreateClientRequest) Reset()         { *m = CreateClientRequest{} }
-func (m *CreateClientRequest) String() string { return proto.CompactTextString(m) }
-func (*CreateClientRequest) ProtoMessage()    {}
-func (*CreateClientRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_9ed45b80aaca82a7, []int{11}
+func (m *PinAllocationRequest) Reset()         { *m = PinAllocationRequest{} }
+func (m *PinAllocationRequest) String() string { return proto.CompactTextString(m) }
+func (*PinAllocationRequest) ProtoMessage()    {}
+func (*PinAllocationRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{10}
 }
 
-func (m *CreateClientRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_CreateClientRequest.Unmarshal(m, b)
+func (m *PinAllocationRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PinAllocationRequest.Unmarshal(m, b)
 }
-func (m *CreateClientRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_CreateClientRequest.Marshal(b, m, deterministic)
+func (m *PinAllocationRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PinAllocationRequest.Marshal(b, m, deterministic)
 }
-func (m *CreateClientRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_CreateClientRequest.Merge(m, src)
+func (m *PinAllocationRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PinAllocationRequest.Merge(m, src)
 }
-func (m *CreateClientRequest) XXX_Size() int {
-	return xxx_messageInfo_CreateClientRequest.Size(m)
+func (m *PinAllocationRequest) XXX_Size() int {
+	return xxx_messageInfo_PinAllocationRequest.Size(m)
 }
-func (m *CreateClientRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_CreateClientRequest.DiscardUnknown(m)
+func (m *PinAllocationRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PinAllocationRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_CreateClientRequest proto.InternalMessageInfo
+var xxx_messageInfo_PinAllocationRequest proto.InternalMessageInfo
 
-func (m *CreateClientRequest) GetClient() string {
+func (m *PinAllocationRequest) GetClient() string {
 	if m != nil {
 		return m.Client
 	}
 	return ""
 }
 
-func (m *CreateClientRequest) GetForce() bool {
+func (m *PinAllocationRequest) GetIp() string {
 	if m != nil {
-		return m.Force
+		return m.Ip
 	}
-	return false
+	return ""
 }
 
-type CreateClientResponse struct {
-	Config               string   `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`
+type PinAllocationResponse struct {
+	Status               int32    `protobuf:"varint,1,opt,name=status,proto3" json:"status,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *CreateClientResponse) Reset()         { *m = CreateClientResponse{} }
-func (m *CreateClientResponse) String() string { return proto.CompactTextString(m) }
-func (*CreateClientResponse) ProtoMessage()    {}
-func (*CreateClientResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_9ed45b80aaca82a7, []int{12}
+func (m *PinAllocationResponse) Reset()         { *m = PinAllocationResponse{} }
+func (m *PinAllocationResponse) String() string { return proto.CompactTextString(m) }
+func (*PinAllocationResponse) ProtoMessage()    {}
+func (*PinAllocationResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{11}
 }
 
-func (m *CreateClientResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_CreateClientResponse.Unmarshal(m, b)
+func (m *PinAllocationResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PinAllocationResponse.Unmarshal(m, b)
 }
-func (m *CreateClientResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_CreateClientResponse.Marshal(b, m, deterministic)
+func (m *PinAllocationResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PinAllocationResponse.Marshal(b, m, deterministic)
 }
-func (m *CreateClientResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_CreateClientResponse.Merge(m, src)
+func (m *PinAllocationResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PinAllocationResponse.Merge(m, src)
 }
-func (m *CreateClientResponse) XXX_Size() int {
-	return xxx_messageInfo_CreateClientResponse.Size(m)
+func (m *PinAllocationResponse) XXX_Size() int {
+	return xxx_messageInfo_PinAllocationResponse.Size(m)
 }
-func (m *CreateClientResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_CreateClientResponse.DiscardUnknown(m)
+func (m *PinAllocationResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_PinAllocationResponse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_CreateClientResponse proto.InternalMessageInfo
+var xxx_messageInfo_PinAllocationResponse proto.InternalMessageInfo
 
-func (m *CreateClientResponse) GetConfig() string {
+func (m *PinAllocationResponse) GetStatus() int32 {
 	if m != nil {
-		return m.Config
+		return m.Status
 	}
-	return ""
+	return 0
 }
 
-// MARK: get client request/response
-type GetClientRequest struct {
+// MARK: unpin allocation request/response
+type UnpinAllocationRequest struct {
 	Client               string   `protobuf:"bytes,1,opt,name=client,proto3" json:"client,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *GetClientRequest) Reset()         { *m = GetClientRequest{} }
-func (m *GetClientRequest) String() string { return proto.CompactTextString(m) }
-func (*GetClientRequest) ProtoMessage()    {}
-func (*GetClientRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_9ed45b80aaca82a7, []int{13}
+func (m *UnpinAllocationRequest) Reset()         { *m = UnpinAllocationRequest{} }
+func (m *UnpinAllocationRequest) String() string { return proto.CompactTextString(m) }
+func (*UnpinAllocationRequest) ProtoMessage()    {}
+func (*UnpinAllocationRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{12}
 }
 
-func (m *GetClientRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_GetClientRequest.Unmarshal(m, b)
+func (m *UnpinAllocationRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UnpinAllocationRequest.Unmarshal(m, b)
 }
-func (m *GetClientRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_GetClientRequest.Marshal(b, m, deterministic)
+func (m *UnpinAllocationRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UnpinAllocationRequest.Marshal(b, m, deterministic)
 }
-func (m *GetClientRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GetClientRequest.Merge(m, src)
+func (m *UnpinAllocationRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UnpinAllocationRequest.Merge(m, src)
 }
-func (m *GetClientRequest) XXX_Size() int {
-	return xxx_messageInfo_GetClientRequest.Size(m)
+func (m *UnpinAllocationRequest) XXX_Size() int {
+	return xxx_messageInfo_UnpinAllocationRequest.Size(m)
 }
-func (m *GetClientRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_GetClientRequest.DiscardUnknown(m)
+func (m *UnpinAllocationRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UnpinAllocationRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_GetClientRequest proto.InternalMessageInfo
+var xxx_messageInfo_UnpinAllocationRequest proto.InternalMessageInfo
 
-func (m *GetClientRequest) GetClient() string {
+func (m *UnpinAllocationRequest) GetClient() string {
 	if m != nil {
 		return m.Client
 	}
 	return ""
 }
 
-type GetClientResponse struct {
-	Status               ClientStatus `protobuf:"varint,1,opt,name=status,proto3,enum=protobuf.ClientStatus" json:"status,omitempty"`
-	ExpiresDate          int64        `protobuf:"varint,2,opt,name=expires_date,json=expiresDate,proto3" json:"expires_date,omitempty"`
-	RevocationDate       int64        `protobuf:"varint,3,opt,name=revocation_date,json=revocationDate,proto3" json:"revocation_date,omitempty"`
-	Config               string       `protobuf:"bytes,4,opt,name=config,proto3" json:"config,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
-	XXX_unrecognized     []byte       `json:"-"`
-	XXX_sizecache        int32        `json:"-"`
+type UnpinAllocationResponse struct {
+	Status               int32    `protobuf:"varint,1,opt,name=status,proto3" json:"status,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *GetClientResponse) Reset()         { *m = GetClientResponse{} }
-func (m *GetClientResponse) String() string { return proto.CompactTextString(m) }
-func (*GetClientResponse) ProtoMessage()    {}
-func (*GetClientResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_9ed45b80aaca82a7, []int{14}
+func (m *UnpinAllocationResponse) Reset()         { *m = UnpinAllocationResponse{} }
+func (m *UnpinAllocationResponse) String() string { return proto.CompactTextString(m) }
+func (*UnpinAllocationResponse) ProtoMessage()    {}
+func (*UnpinAllocationResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{13}
 }
 
-func (m *GetClientResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_GetClientResponse.Unmarshal(m, b)
+func (m *UnpinAllocationResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UnpinAllocationResponse.Unmarshal(m, b)
 }
-func (m *GetClientResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_GetClientResponse.Marshal(b, m, deterministic)
+func (m *UnpinAllocationResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UnpinAllocationResponse.Marshal(b, m, deterministic)
 }
-func (m *GetClientResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_GetClientResponse.Merge(m, src)
+func (m *UnpinAllocationResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UnpinAllocationResponse.Merge(m, src)
 }
-func (m *GetClientResponse) XXX_Size() int {
-	return xxx_messageInfo_GetClientResponse.Size(m)
+func (m *UnpinAllocationResponse) XXX_Size() int {
+	return xxx_messageInfo_UnpinAllocationResponse.Size(m)
 }
-func (m *GetClientResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_GetClientResponse.DiscardUnknown(m)
+func (m *UnpinAllocationResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_UnpinAllocationResponse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_GetClientResponse proto.InternalMessageInfo
+var xxx_messageInfo_UnpinAllocationResponse proto.InternalMessageInfo
 
-func (m *GetClientResponse) GetStatus() ClientStatus {
+func (m *UnpinAllocationResponse) GetStatus() int32 {
 	if m != nil {
 		return m.Status
 	}
-	return ClientStatus_EXPIRED
+	return 0
 }
 
-func (m *GetClientResponse) GetExpiresDate() int64 {
-	if m != nil {
-		return m.ExpiresDate
-	}
-	return 0
+// MARK: list pins request/response
+type ListPinsRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *GetClientResponse) GetRevocationDate() int64 {
-	if m != nil {
-		return m.RevocationDate
-	}
-	return 0
+func (m *ListPinsRequest) Reset()         { *m = ListPinsRequest{} }
+func (m *ListPinsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListPinsRequest) ProtoMessage()    {}
+func (*ListPinsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{14}
 }
 
-func (m *GetClientResponse) GetConfig() string {
-	if m != nil {
-		return m.Config
-	}
-	return ""
+func (m *ListPinsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListPinsRequest.Unmarshal(m, b)
+}
+func (m *ListPinsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListPinsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListPinsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListPinsRequest.Merge(m, src)
+}
+func (m *ListPinsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListPinsRequest.Size(m)
+}
+func (m *ListPinsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListPinsRequest.DiscardUnknown(m)
 }
 
-// MARK: revoke client request/response
-type RevokeClientRequest struct {
-	Client               string   `protobuf:"bytes,1,opt,name=client,proto3" json:"client,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+var xxx_messageInfo_ListPinsRequest proto.InternalMessageInfo
+
+type ListPinsResponse struct {
+	Pins                 []*Allocation `protobuf:"bytes,1,rep,name=pins,proto3" json:"pins,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
 }
 
-func (m *RevokeClientRequest) Reset()         { *m = RevokeClientRequest{} }
-func (m *RevokeClientRequest) String() string { return proto.CompactTextString(m) }
-func (*RevokeClientRequest) ProtoMessage()    {}
-func (*RevokeClientRequest) Descriptor() ([]byte, []int) {
+func (m *ListPinsResponse) Reset()         { *m = ListPinsResponse{} }
+func (m *ListPinsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListPinsResponse) ProtoMessage()    {}
+func (*ListPinsResponse) Descriptor() ([]byte, []int) {
 	return fileDescriptor_9ed45b80aaca82a7, []int{15}
 }
 
-func (m *RevokeClientRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_RevokeClientRequest.Unmarshal(m, b)
+func (m *ListPinsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListPinsResponse.Unmarshal(m, b)
 }
-func (m *RevokeClientRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_RevokeClientRequest.Marshal(b, m, deterministic)
+func (m *ListPinsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListPinsResponse.Marshal(b, m, deterministic)
 }
-func (m *RevokeClientRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_RevokeClientRequest.Merge(m, src)
+func (m *ListPinsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListPinsResponse.Merge(m, src)
 }
-func (m *RevokeClientRequest) XXX_Size() int {
-	return xxx_messageInfo_RevokeClientRequest.Size(m)
+func (m *ListPinsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListPinsResponse.Size(m)
 }
-func (m *RevokeClientRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_RevokeClientRequest.DiscardUnknown(m)
+func (m *ListPinsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListPinsResponse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_RevokeClientRequest proto.InternalMessageInfo
+var xxx_messageInfo_ListPinsResponse proto.InternalMessageInfo
 
-func (m *RevokeClientRequest) GetClient() string {
+func (m *ListPinsResponse) GetPins() []*Allocation {
 	if m != nil {
-		return m.Client
+		return m.Pins
 	}
-	return ""
+	return nil
 }
 
-type RevokeClientResponse struct {
-	Status               int32    `protobuf:"varint,1,opt,name=status,proto3" json:"status,omitempty"`
+// MARK: firewall diff request/response
+type FirewallDiffRequest struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *RevokeClientResponse) Reset()         { *m = RevokeClientResponse{} }
-func (m *RevokeClientResponse) String() string { return proto.CompactTextString(m) }
-func (*RevokeClientResponse) ProtoMessage()    {}
-func (*RevokeClientResponse) Descriptor() ([]byte, []int) {
+func (m *FirewallDiffRequest) Reset()         { *m = FirewallDiffRequest{} }
+func (m *FirewallDiffRequest) String() string { return proto.CompactTextString(m) }
+func (*FirewallDiffRequest) ProtoMessage()    {}
+func (*FirewallDiffRequest) Descriptor() ([]byte, []int) {
 	return fileDescriptor_9ed45b80aaca82a7, []int{16}
 }
 
-func (m *RevokeClientResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_RevokeClientResponse.Unmarshal(m, b)
+func (m *FirewallDiffRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FirewallDiffRequest.Unmarshal(m, b)
 }
-func (m *RevokeClientResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_RevokeClientResponse.Marshal(b, m, deterministic)
+func (m *FirewallDiffRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FirewallDiffRequest.Marshal(b, m, deterministic)
 }
-func (m *RevokeClientResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_RevokeClientResponse.Merge(m, src)
+func (m *FirewallDiffRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FirewallDiffRequest.Merge(m, src)
 }
-func (m *RevokeClientResponse) XXX_Size() int {
-	return xxx_messageInfo_RevokeClientResponse.Size(m)
+func (m *FirewallDiffRequest) XXX_Size() int {
+	return xxx_messageInfo_FirewallDiffRequest.Size(m)
 }
-func (m *RevokeClientResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_RevokeClientResponse.DiscardUnknown(m)
+func (m *FirewallDiffRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_FirewallDiffRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_RevokeClientResponse proto.InternalMessageInfo
+var xxx_messageInfo_FirewallDiffRequest proto.InternalMessageInfo
 
-func (m *RevokeClientResponse) GetStatus() int32 {
+type FirewallDiffResponse struct {
+	Drifts               []*FirewallDrift `protobuf:"bytes,1,rep,name=drifts,proto3" json:"drifts,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *FirewallDiffResponse) Reset()         { *m = FirewallDiffResponse{} }
+func (m *FirewallDiffResponse) String() string { return proto.CompactTextString(m) }
+func (*FirewallDiffResponse) ProtoMessage()    {}
+func (*FirewallDiffResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{17}
+}
+
+func (m *FirewallDiffResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FirewallDiffResponse.Unmarshal(m, b)
+}
+func (m *FirewallDiffResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FirewallDiffResponse.Marshal(b, m, deterministic)
+}
+func (m *FirewallDiffResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FirewallDiffResponse.Merge(m, src)
+}
+func (m *FirewallDiffResponse) XXX_Size() int {
+	return xxx_messageInfo_FirewallDiffResponse.Size(m)
+}
+func (m *FirewallDiffResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_FirewallDiffResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FirewallDiffResponse proto.InternalMessageInfo
+
+func (m *FirewallDiffResponse) GetDrifts() []*FirewallDrift {
+	if m != nil {
+		return m.Drifts
+	}
+	return nil
+}
+
+// FirewallDrift is a difference between the firewall and the connected clients.
+type FirewallDrift struct {
+	Kind                 string   `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Ip                   string   `protobuf:"bytes,2,opt,name=ip,proto3" json:"ip,omitempty"`
+	Ipv6                 string   `protobuf:"bytes,3,opt,name=ipv6,proto3" json:"ipv6,omitempty"`
+	Client               string   `protobuf:"bytes,4,opt,name=client,proto3" json:"client,omitempty"`
+	MissingRoutes        []string `protobuf:"bytes,5,rep,name=missing_routes,json=missingRoutes,proto3" json:"missing_routes,omitempty"`
+	ExtraRoutes          []string `protobuf:"bytes,6,rep,name=extra_routes,json=extraRoutes,proto3" json:"extra_routes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FirewallDrift) Reset()         { *m = FirewallDrift{} }
+func (m *FirewallDrift) String() string { return proto.CompactTextString(m) }
+func (*FirewallDrift) ProtoMessage()    {}
+func (*FirewallDrift) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{18}
+}
+
+func (m *FirewallDrift) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FirewallDrift.Unmarshal(m, b)
+}
+func (m *FirewallDrift) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FirewallDrift.Marshal(b, m, deterministic)
+}
+func (m *FirewallDrift) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FirewallDrift.Merge(m, src)
+}
+func (m *FirewallDrift) XXX_Size() int {
+	return xxx_messageInfo_FirewallDrift.Size(m)
+}
+func (m *FirewallDrift) XXX_DiscardUnknown() {
+	xxx_messageInfo_FirewallDrift.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FirewallDrift proto.InternalMessageInfo
+
+func (m *FirewallDrift) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *FirewallDrift) GetIp() string {
+	if m != nil {
+		return m.Ip
+	}
+	return ""
+}
+
+func (m *FirewallDrift) GetIpv6() string {
+	if m != nil {
+		return m.Ipv6
+	}
+	return ""
+}
+
+func (m *FirewallDrift) GetClient() string {
+	if m != nil {
+		return m.Client
+	}
+	return ""
+}
+
+func (m *FirewallDrift) GetMissingRoutes() []string {
+	if m != nil {
+		return m.MissingRoutes
+	}
+	return nil
+}
+
+func (m *FirewallDrift) GetExtraRoutes() []string {
+	if m != nil {
+		return m.ExtraRoutes
+	}
+	return nil
+}
+
+// MARK: enroll local user request/response
+type EnrollLocalUserRequest struct {
+	User                 string   `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Password             string   `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	Routes               []string `protobuf:"bytes,3,rep,name=routes,proto3" json:"routes,omitempty"`
+	Force                bool     `protobuf:"varint,4,opt,name=force,proto3" json:"force,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EnrollLocalUserRequest) Reset()         { *m = EnrollLocalUserRequest{} }
+func (m *EnrollLocalUserRequest) String() string { return proto.CompactTextString(m) }
+func (*EnrollLocalUserRequest) ProtoMessage()    {}
+func (*EnrollLocalUserRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{19}
+}
+
+func (m *EnrollLocalUserRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EnrollLocalUserRequest.Unmarshal(m, b)
+}
+func (m *EnrollLocalUserRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EnrollLocalUserRequest.Marshal(b, m, deterministic)
+}
+func (m *EnrollLocalUserRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EnrollLocalUserRequest.Merge(m, src)
+}
+func (m *EnrollLocalUserRequest) XXX_Size() int {
+	return xxx_messageInfo_EnrollLocalUserRequest.Size(m)
+}
+func (m *EnrollLocalUserRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_EnrollLocalUserRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EnrollLocalUserRequest proto.InternalMessageInfo
+
+func (m *EnrollLocalUserRequest) GetUser() string {
+	if m != nil {
+		return m.User
+	}
+	return ""
+}
+
+func (m *EnrollLocalUserRequest) GetPassword() string {
+	if m != nil {
+		return m.Password
+	}
+	return ""
+}
+
+func (m *EnrollLocalUserRequest) GetRoutes() []string {
+	if m != nil {
+		return m.Routes
+	}
+	return nil
+}
+
+func (m *EnrollLocalUserRequest) GetForce() bool {
+	if m != nil {
+		return m.Force
+	}
+	return false
+}
+
+type EnrollLocalUserResponse struct {
+	TotpSecret           string   `protobuf:"bytes,1,opt,name=totp_secret,json=totpSecret,proto3" json:"totp_secret,omitempty"`
+	TotpUrl              string   `protobuf:"bytes,2,opt,name=totp_url,json=totpUrl,proto3" json:"totp_url,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EnrollLocalUserResponse) Reset()         { *m = EnrollLocalUserResponse{} }
+func (m *EnrollLocalUserResponse) String() string { return proto.CompactTextString(m) }
+func (*EnrollLocalUserResponse) ProtoMessage()    {}
+func (*EnrollLocalUserResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{20}
+}
+
+func (m *EnrollLocalUserResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EnrollLocalUserResponse.Unmarshal(m, b)
+}
+func (m *EnrollLocalUserResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EnrollLocalUserResponse.Marshal(b, m, deterministic)
+}
+func (m *EnrollLocalUserResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EnrollLocalUserResponse.Merge(m, src)
+}
+func (m *EnrollLocalUserResponse) XXX_Size() int {
+	return xxx_messageInfo_EnrollLocalUserResponse.Size(m)
+}
+func (m *EnrollLocalUserResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_EnrollLocalUserResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EnrollLocalUserResponse proto.InternalMessageInfo
+
+func (m *EnrollLocalUserResponse) GetTotpSecret() string {
+	if m != nil {
+		return m.TotpSecret
+	}
+	return ""
+}
+
+func (m *EnrollLocalUserResponse) GetTotpUrl() string {
+	if m != nil {
+		return m.TotpUrl
+	}
+	return ""
+}
+
+// MARK: delete local user request/response
+type DeleteLocalUserRequest struct {
+	User                 string   `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteLocalUserRequest) Reset()         { *m = DeleteLocalUserRequest{} }
+func (m *DeleteLocalUserRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteLocalUserRequest) ProtoMessage()    {}
+func (*DeleteLocalUserRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{21}
+}
+
+func (m *DeleteLocalUserRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteLocalUserRequest.Unmarshal(m, b)
+}
+func (m *DeleteLocalUserRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteLocalUserRequest.Marshal(b, m, deterministic)
+}
+func (m *DeleteLocalUserRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteLocalUserRequest.Merge(m, src)
+}
+func (m *DeleteLocalUserRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteLocalUserRequest.Size(m)
+}
+func (m *DeleteLocalUserRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteLocalUserRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteLocalUserRequest proto.InternalMessageInfo
+
+func (m *DeleteLocalUserRequest) GetUser() string {
+	if m != nil {
+		return m.User
+	}
+	return ""
+}
+
+type DeleteLocalUserResponse struct {
+	Status               int32    `protobuf:"varint,1,opt,name=status,proto3" json:"status,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteLocalUserResponse) Reset()         { *m = DeleteLocalUserResponse{} }
+func (m *DeleteLocalUserResponse) String() string { return proto.CompactTextString(m) }
+func (*DeleteLocalUserResponse) ProtoMessage()    {}
+func (*DeleteLocalUserResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{22}
+}
+
+func (m *DeleteLocalUserResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteLocalUserResponse.Unmarshal(m, b)
+}
+func (m *DeleteLocalUserResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteLocalUserResponse.Marshal(b, m, deterministic)
+}
+func (m *DeleteLocalUserResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteLocalUserResponse.Merge(m, src)
+}
+func (m *DeleteLocalUserResponse) XXX_Size() int {
+	return xxx_messageInfo_DeleteLocalUserResponse.Size(m)
+}
+func (m *DeleteLocalUserResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteLocalUserResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteLocalUserResponse proto.InternalMessageInfo
+
+func (m *DeleteLocalUserResponse) GetStatus() int32 {
+	if m != nil {
+		return m.Status
+	}
+	return 0
+}
+
+// MARK: list local users request/response
+type ListLocalUsersRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListLocalUsersRequest) Reset()         { *m = ListLocalUsersRequest{} }
+func (m *ListLocalUsersRequest) String() string { return proto.CompactTextString(m) }
+func (*ListLocalUsersRequest) ProtoMessage()    {}
+func (*ListLocalUsersRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{23}
+}
+
+func (m *ListLocalUsersRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListLocalUsersRequest.Unmarshal(m, b)
+}
+func (m *ListLocalUsersRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListLocalUsersRequest.Marshal(b, m, deterministic)
+}
+func (m *ListLocalUsersRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListLocalUsersRequest.Merge(m, src)
+}
+func (m *ListLocalUsersRequest) XXX_Size() int {
+	return xxx_messageInfo_ListLocalUsersRequest.Size(m)
+}
+func (m *ListLocalUsersRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListLocalUsersRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListLocalUsersRequest proto.InternalMessageInfo
+
+type ListLocalUsersResponse struct {
+	Users                []*LocalUser `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *ListLocalUsersResponse) Reset()         { *m = ListLocalUsersResponse{} }
+func (m *ListLocalUsersResponse) String() string { return proto.CompactTextString(m) }
+func (*ListLocalUsersResponse) ProtoMessage()    {}
+func (*ListLocalUsersResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{24}
+}
+
+func (m *ListLocalUsersResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListLocalUsersResponse.Unmarshal(m, b)
+}
+func (m *ListLocalUsersResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListLocalUsersResponse.Marshal(b, m, deterministic)
+}
+func (m *ListLocalUsersResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListLocalUsersResponse.Merge(m, src)
+}
+func (m *ListLocalUsersResponse) XXX_Size() int {
+	return xxx_messageInfo_ListLocalUsersResponse.Size(m)
+}
+func (m *ListLocalUsersResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListLocalUsersResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListLocalUsersResponse proto.InternalMessageInfo
+
+func (m *ListLocalUsersResponse) GetUsers() []*LocalUser {
+	if m != nil {
+		return m.Users
+	}
+	return nil
+}
+
+// MARK: local user
+type LocalUser struct {
+	User                 string   `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	PasswordHash         []byte   `protobuf:"bytes,2,opt,name=password_hash,json=passwordHash,proto3" json:"password_hash,omitempty"`
+	TotpSecret           []byte   `protobuf:"bytes,3,opt,name=totp_secret,json=totpSecret,proto3" json:"totp_secret,omitempty"`
+	Routes               []string `protobuf:"bytes,4,rep,name=routes,proto3" json:"routes,omitempty"`
+	Created              int64    `protobuf:"varint,5,opt,name=created,proto3" json:"created,omitempty"`
+	LastTotpStep         int64    `protobuf:"varint,6,opt,name=last_totp_step,json=lastTotpStep,proto3" json:"last_totp_step,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LocalUser) Reset()         { *m = LocalUser{} }
+func (m *LocalUser) String() string { return proto.CompactTextString(m) }
+func (*LocalUser) ProtoMessage()    {}
+func (*LocalUser) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{25}
+}
+
+func (m *LocalUser) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LocalUser.Unmarshal(m, b)
+}
+func (m *LocalUser) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LocalUser.Marshal(b, m, deterministic)
+}
+func (m *LocalUser) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LocalUser.Merge(m, src)
+}
+func (m *LocalUser) XXX_Size() int {
+	return xxx_messageInfo_LocalUser.Size(m)
+}
+func (m *LocalUser) XXX_DiscardUnknown() {
+	xxx_messageInfo_LocalUser.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LocalUser proto.InternalMessageInfo
+
+func (m *LocalUser) GetUser() string {
+	if m != nil {
+		return m.User
+	}
+	return ""
+}
+
+func (m *LocalUser) GetPasswordHash() []byte {
+	if m != nil {
+		return m.PasswordHash
+	}
+	return nil
+}
+
+func (m *LocalUser) GetTotpSecret() []byte {
+	if m != nil {
+		return m.TotpSecret
+	}
+	return nil
+}
+
+func (m *LocalUser) GetRoutes() []string {
+	if m != nil {
+		return m.Routes
+	}
+	return nil
+}
+
+func (m *LocalUser) GetCreated() int64 {
+	if m != nil {
+		return m.Created
+	}
+	return 0
+}
+
+func (m *LocalUser) GetLastTotpStep() int64 {
+	if m != nil {
+		return m.LastTotpStep
+	}
+	return 0
+}
+
+// MARK: list lockouts request/response
+type ListLockoutsRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListLockoutsRequest) Reset()         { *m = ListLockoutsRequest{} }
+func (m *ListLockoutsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListLockoutsRequest) ProtoMessage()    {}
+func (*ListLockoutsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{26}
+}
+
+func (m *ListLockoutsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListLockoutsRequest.Unmarshal(m, b)
+}
+func (m *ListLockoutsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListLockoutsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListLockoutsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListLockoutsRequest.Merge(m, src)
+}
+func (m *ListLockoutsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListLockoutsRequest.Size(m)
+}
+func (m *ListLockoutsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListLockoutsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListLockoutsRequest proto.InternalMessageInfo
+
+type ListLockoutsResponse struct {
+	Lockouts             []*Lockout `protobuf:"bytes,1,rep,name=lockouts,proto3" json:"lockouts,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *ListLockoutsResponse) Reset()         { *m = ListLockoutsResponse{} }
+func (m *ListLockoutsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListLockoutsResponse) ProtoMessage()    {}
+func (*ListLockoutsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{27}
+}
+
+func (m *ListLockoutsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListLockoutsResponse.Unmarshal(m, b)
+}
+func (m *ListLockoutsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListLockoutsResponse.Marshal(b, m, deterministic)
+}
+func (m *ListLockoutsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListLockoutsResponse.Merge(m, src)
+}
+func (m *ListLockoutsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListLockoutsResponse.Size(m)
+}
+func (m *ListLockoutsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListLockoutsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListLockoutsResponse proto.InternalMessageInfo
+
+func (m *ListLockoutsResponse) GetLockouts() []*Lockout {
+	if m != nil {
+		return m.Lockouts
+	}
+	return nil
+}
+
+// MARK: clear lockout request/response
+type ClearLockoutRequest struct {
+	Kind                 string   `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Key                  string   `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	All                  bool     `protobuf:"varint,3,opt,name=all,proto3" json:"all,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ClearLockoutRequest) Reset()         { *m = ClearLockoutRequest{} }
+func (m *ClearLockoutRequest) String() string { return proto.CompactTextString(m) }
+func (*ClearLockoutRequest) ProtoMessage()    {}
+func (*ClearLockoutRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{28}
+}
+
+func (m *ClearLockoutRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ClearLockoutRequest.Unmarshal(m, b)
+}
+func (m *ClearLockoutRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ClearLockoutRequest.Marshal(b, m, deterministic)
+}
+func (m *ClearLockoutRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ClearLockoutRequest.Merge(m, src)
+}
+func (m *ClearLockoutRequest) XXX_Size() int {
+	return xxx_messageInfo_ClearLockoutRequest.Size(m)
+}
+func (m *ClearLockoutRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ClearLockoutRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ClearLockoutRequest proto.InternalMessageInfo
+
+func (m *ClearLockoutRequest) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *ClearLockoutRequest) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *ClearLockoutRequest) GetAll() bool {
+	if m != nil {
+		return m.All
+	}
+	return false
+}
+
+type ClearLockoutResponse struct {
+	Cleared              int32    `protobuf:"varint,1,opt,name=cleared,proto3" json:"cleared,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ClearLockoutResponse) Reset()         { *m = ClearLockoutResponse{} }
+func (m *ClearLockoutResponse) String() string { return proto.CompactTextString(m) }
+func (*ClearLockoutResponse) ProtoMessage()    {}
+func (*ClearLockoutResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{29}
+}
+
+func (m *ClearLockoutResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ClearLockoutResponse.Unmarshal(m, b)
+}
+func (m *ClearLockoutResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ClearLockoutResponse.Marshal(b, m, deterministic)
+}
+func (m *ClearLockoutResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ClearLockoutResponse.Merge(m, src)
+}
+func (m *ClearLockoutResponse) XXX_Size() int {
+	return xxx_messageInfo_ClearLockoutResponse.Size(m)
+}
+func (m *ClearLockoutResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ClearLockoutResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ClearLockoutResponse proto.InternalMessageInfo
+
+func (m *ClearLockoutResponse) GetCleared() int32 {
+	if m != nil {
+		return m.Cleared
+	}
+	return 0
+}
+
+// MARK: lockout
+type Lockout struct {
+	Kind                 string   `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Key                  string   `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	Failures             int32    `protobuf:"varint,3,opt,name=failures,proto3" json:"failures,omitempty"`
+	LastFailure          int64    `protobuf:"varint,4,opt,name=last_failure,json=lastFailure,proto3" json:"last_failure,omitempty"`
+	LockedUntil          int64    `protobuf:"varint,5,opt,name=locked_until,json=lockedUntil,proto3" json:"locked_until,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Lockout) Reset()         { *m = Lockout{} }
+func (m *Lockout) String() string { return proto.CompactTextString(m) }
+func (*Lockout) ProtoMessage()    {}
+func (*Lockout) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{30}
+}
+
+func (m *Lockout) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Lockout.Unmarshal(m, b)
+}
+func (m *Lockout) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Lockout.Marshal(b, m, deterministic)
+}
+func (m *Lockout) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Lockout.Merge(m, src)
+}
+func (m *Lockout) XXX_Size() int {
+	return xxx_messageInfo_Lockout.Size(m)
+}
+func (m *Lockout) XXX_DiscardUnknown() {
+	xxx_messageInfo_Lockout.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Lockout proto.InternalMessageInfo
+
+func (m *Lockout) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *Lockout) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *Lockout) GetFailures() int32 {
+	if m != nil {
+		return m.Failures
+	}
+	return 0
+}
+
+func (m *Lockout) GetLastFailure() int64 {
+	if m != nil {
+		return m.LastFailure
+	}
+	return 0
+}
+
+func (m *Lockout) GetLockedUntil() int64 {
+	if m != nil {
+		return m.LockedUntil
+	}
+	return 0
+}
+
+// MARK: enroll service account request/response
+type EnrollServiceAccountRequest struct {
+	Name     string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Client   string   `protobuf:"bytes,2,opt,name=client,proto3" json:"client,omitempty"`
+	Projects []string `protobuf:"bytes,3,rep,name=projects,proto3" json:"projects,omitempty"`
+	// issue a doorman secret to log in with, instead of an equinix api token
+	IssueSecret          bool     `protobuf:"varint,4,opt,name=issue_secret,json=issueSecret,proto3" json:"issue_secret,omitempty"`
+	Force                bool     `protobuf:"varint,5,opt,name=force,proto3" json:"force,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EnrollServiceAccountRequest) Reset()         { *m = EnrollServiceAccountRequest{} }
+func (m *EnrollServiceAccountRequest) String() string { return proto.CompactTextString(m) }
+func (*EnrollServiceAccountRequest) ProtoMessage()    {}
+func (*EnrollServiceAccountRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{31}
+}
+
+func (m *EnrollServiceAccountRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EnrollServiceAccountRequest.Unmarshal(m, b)
+}
+func (m *EnrollServiceAccountRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EnrollServiceAccountRequest.Marshal(b, m, deterministic)
+}
+func (m *EnrollServiceAccountRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EnrollServiceAccountRequest.Merge(m, src)
+}
+func (m *EnrollServiceAccountRequest) XXX_Size() int {
+	return xxx_messageInfo_EnrollServiceAccountRequest.Size(m)
+}
+func (m *EnrollServiceAccountRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_EnrollServiceAccountRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EnrollServiceAccountRequest proto.InternalMessageInfo
+
+func (m *EnrollServiceAccountRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *EnrollServiceAccountRequest) GetClient() string {
+	if m != nil {
+		return m.Client
+	}
+	return ""
+}
+
+func (m *EnrollServiceAccountRequest) GetProjects() []string {
+	if m != nil {
+		return m.Projects
+	}
+	return nil
+}
+
+func (m *EnrollServiceAccountRequest) GetIssueSecret() bool {
+	if m != nil {
+		return m.IssueSecret
+	}
+	return false
+}
+
+func (m *EnrollServiceAccountRequest) GetForce() bool {
+	if m != nil {
+		return m.Force
+	}
+	return false
+}
+
+type EnrollServiceAccountResponse struct {
+	Secret               string   `protobuf:"bytes,1,opt,name=secret,proto3" json:"secret,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EnrollServiceAccountResponse) Reset()         { *m = EnrollServiceAccountResponse{} }
+func (m *EnrollServiceAccountResponse) String() string { return proto.CompactTextString(m) }
+func (*EnrollServiceAccountResponse) ProtoMessage()    {}
+func (*EnrollServiceAccountResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{32}
+}
+
+func (m *EnrollServiceAccountResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EnrollServiceAccountResponse.Unmarshal(m, b)
+}
+func (m *EnrollServiceAccountResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EnrollServiceAccountResponse.Marshal(b, m, deterministic)
+}
+func (m *EnrollServiceAccountResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EnrollServiceAccountResponse.Merge(m, src)
+}
+func (m *EnrollServiceAccountResponse) XXX_Size() int {
+	return xxx_messageInfo_EnrollServiceAccountResponse.Size(m)
+}
+func (m *EnrollServiceAccountResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_EnrollServiceAccountResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EnrollServiceAccountResponse proto.InternalMessageInfo
+
+func (m *EnrollServiceAccountResponse) GetSecret() string {
+	if m != nil {
+		return m.Secret
+	}
+	return ""
+}
+
+// MARK: delete service account request/response
+type DeleteServiceAccountRequest struct {
+	Client               string   `protobuf:"bytes,1,opt,name=client,proto3" json:"client,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteServiceAccountRequest) Reset()         { *m = DeleteServiceAccountRequest{} }
+func (m *DeleteServiceAccountRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteServiceAccountRequest) ProtoMessage()    {}
+func (*DeleteServiceAccountRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{33}
+}
+
+func (m *DeleteServiceAccountRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteServiceAccountRequest.Unmarshal(m, b)
+}
+func (m *DeleteServiceAccountRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteServiceAccountRequest.Marshal(b, m, deterministic)
+}
+func (m *DeleteServiceAccountRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteServiceAccountRequest.Merge(m, src)
+}
+func (m *DeleteServiceAccountRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteServiceAccountRequest.Size(m)
+}
+func (m *DeleteServiceAccountRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteServiceAccountRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteServiceAccountRequest proto.InternalMessageInfo
+
+func (m *DeleteServiceAccountRequest) GetClient() string {
+	if m != nil {
+		return m.Client
+	}
+	return ""
+}
+
+type DeleteServiceAccountResponse struct {
+	Status               int32    `protobuf:"varint,1,opt,name=status,proto3" json:"status,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteServiceAccountResponse) Reset()         { *m = DeleteServiceAccountResponse{} }
+func (m *DeleteServiceAccountResponse) String() string { return proto.CompactTextString(m) }
+func (*DeleteServiceAccountResponse) ProtoMessage()    {}
+func (*DeleteServiceAccountResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{34}
+}
+
+func (m *DeleteServiceAccountResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteServiceAccountResponse.Unmarshal(m, b)
+}
+func (m *DeleteServiceAccountResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteServiceAccountResponse.Marshal(b, m, deterministic)
+}
+func (m *DeleteServiceAccountResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteServiceAccountResponse.Merge(m, src)
+}
+func (m *DeleteServiceAccountResponse) XXX_Size() int {
+	return xxx_messageInfo_DeleteServiceAccountResponse.Size(m)
+}
+func (m *DeleteServiceAccountResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteServiceAccountResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteServiceAccountResponse proto.InternalMessageInfo
+
+func (m *DeleteServiceAccountResponse) GetStatus() int32 {
+	if m != nil {
+		return m.Status
+	}
+	return 0
+}
+
+// MARK: list service accounts request/response
+type ListServiceAccountsRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListServiceAccountsRequest) Reset()         { *m = ListServiceAccountsRequest{} }
+func (m *ListServiceAccountsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListServiceAccountsRequest) ProtoMessage()    {}
+func (*ListServiceAccountsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{35}
+}
+
+func (m *ListServiceAccountsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListServiceAccountsRequest.Unmarshal(m, b)
+}
+func (m *ListServiceAccountsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListServiceAccountsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListServiceAccountsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListServiceAccountsRequest.Merge(m, src)
+}
+func (m *ListServiceAccountsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListServiceAccountsRequest.Size(m)
+}
+func (m *ListServiceAccountsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListServiceAccountsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListServiceAccountsRequest proto.InternalMessageInfo
+
+type ListServiceAccountsResponse struct {
+	Accounts             []*ServiceAccount `protobuf:"bytes,1,rep,name=accounts,proto3" json:"accounts,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *ListServiceAccountsResponse) Reset()         { *m = ListServiceAccountsResponse{} }
+func (m *ListServiceAccountsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListServiceAccountsResponse) ProtoMessage()    {}
+func (*ListServiceAccountsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{36}
+}
+
+func (m *ListServiceAccountsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListServiceAccountsResponse.Unmarshal(m, b)
+}
+func (m *ListServiceAccountsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListServiceAccountsResponse.Marshal(b, m, deterministic)
+}
+func (m *ListServiceAccountsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListServiceAccountsResponse.Merge(m, src)
+}
+func (m *ListServiceAccountsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListServiceAccountsResponse.Size(m)
+}
+func (m *ListServiceAccountsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListServiceAccountsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListServiceAccountsResponse proto.InternalMessageInfo
+
+func (m *ListServiceAccountsResponse) GetAccounts() []*ServiceAccount {
+	if m != nil {
+		return m.Accounts
+	}
+	return nil
+}
+
+// MARK: service account
+type ServiceAccount struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Client               string   `protobuf:"bytes,2,opt,name=client,proto3" json:"client,omitempty"`
+	Projects             []string `protobuf:"bytes,3,rep,name=projects,proto3" json:"projects,omitempty"`
+	SecretHash           []byte   `protobuf:"bytes,4,opt,name=secret_hash,json=secretHash,proto3" json:"secret_hash,omitempty"`
+	Created              int64    `protobuf:"varint,5,opt,name=created,proto3" json:"created,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ServiceAccount) Reset()         { *m = ServiceAccount{} }
+func (m *ServiceAccount) String() string { return proto.CompactTextString(m) }
+func (*ServiceAccount) ProtoMessage()    {}
+func (*ServiceAccount) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{37}
+}
+
+func (m *ServiceAccount) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ServiceAccount.Unmarshal(m, b)
+}
+func (m *ServiceAccount) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ServiceAccount.Marshal(b, m, deterministic)
+}
+func (m *ServiceAccount) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ServiceAccount.Merge(m, src)
+}
+func (m *ServiceAccount) XXX_Size() int {
+	return xxx_messageInfo_ServiceAccount.Size(m)
+}
+func (m *ServiceAccount) XXX_DiscardUnknown() {
+	xxx_messageInfo_ServiceAccount.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ServiceAccount proto.InternalMessageInfo
+
+func (m *ServiceAccount) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ServiceAccount) GetClient() string {
+	if m != nil {
+		return m.Client
+	}
+	return ""
+}
+
+func (m *ServiceAccount) GetProjects() []string {
+	if m != nil {
+		return m.Projects
+	}
+	return nil
+}
+
+func (m *ServiceAccount) GetSecretHash() []byte {
+	if m != nil {
+		return m.SecretHash
+	}
+	return nil
+}
+
+func (m *ServiceAccount) GetCreated() int64 {
+	if m != nil {
+		return m.Created
+	}
+	return 0
+}
+
+// MARK: create break glass request/response
+type CreateBreakGlassRequest struct {
+	Client               string   `protobuf:"bytes,1,opt,name=client,proto3" json:"client,omitempty"`
+	Routes               []string `protobuf:"bytes,2,rep,name=routes,proto3" json:"routes,omitempty"`
+	Lifetime             int64    `protobuf:"varint,3,opt,name=lifetime,proto3" json:"lifetime,omitempty"`
+	Uses                 int32    `protobuf:"varint,4,opt,name=uses,proto3" json:"uses,omitempty"`
+	Reason               string   `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateBreakGlassRequest) Reset()         { *m = CreateBreakGlassRequest{} }
+func (m *CreateBreakGlassRequest) String() string { return proto.CompactTextString(m) }
+func (*CreateBreakGlassRequest) ProtoMessage()    {}
+func (*CreateBreakGlassRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{38}
+}
+
+func (m *CreateBreakGlassRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateBreakGlassRequest.Unmarshal(m, b)
+}
+func (m *CreateBreakGlassRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateBreakGlassRequest.Marshal(b, m, deterministic)
+}
+func (m *CreateBreakGlassRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateBreakGlassRequest.Merge(m, src)
+}
+func (m *CreateBreakGlassRequest) XXX_Size() int {
+	return xxx_messageInfo_CreateBreakGlassRequest.Size(m)
+}
+func (m *CreateBreakGlassRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateBreakGlassRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateBreakGlassRequest proto.InternalMessageInfo
+
+func (m *CreateBreakGlassRequest) GetClient() string {
+	if m != nil {
+		return m.Client
+	}
+	return ""
+}
+
+func (m *CreateBreakGlassRequest) GetRoutes() []string {
+	if m != nil {
+		return m.Routes
+	}
+	return nil
+}
+
+func (m *CreateBreakGlassRequest) GetLifetime() int64 {
+	if m != nil {
+		return m.Lifetime
+	}
+	return 0
+}
+
+func (m *CreateBreakGlassRequest) GetUses() int32 {
+	if m != nil {
+		return m.Uses
+	}
+	return 0
+}
+
+func (m *CreateBreakGlassRequest) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+type CreateBreakGlassResponse struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Credential           string   `protobuf:"bytes,2,opt,name=credential,proto3" json:"credential,omitempty"`
+	Expires              int64    `protobuf:"varint,3,opt,name=expires,proto3" json:"expires,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateBreakGlassResponse) Reset()         { *m = CreateBreakGlassResponse{} }
+func (m *CreateBreakGlassResponse) String() string { return proto.CompactTextString(m) }
+func (*CreateBreakGlassResponse) ProtoMessage()    {}
+func (*CreateBreakGlassResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{39}
+}
+
+func (m *CreateBreakGlassResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateBreakGlassResponse.Unmarshal(m, b)
+}
+func (m *CreateBreakGlassResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateBreakGlassResponse.Marshal(b, m, deterministic)
+}
+func (m *CreateBreakGlassResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateBreakGlassResponse.Merge(m, src)
+}
+func (m *CreateBreakGlassResponse) XXX_Size() int {
+	return xxx_messageInfo_CreateBreakGlassResponse.Size(m)
+}
+func (m *CreateBreakGlassResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateBreakGlassResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateBreakGlassResponse proto.InternalMessageInfo
+
+func (m *CreateBreakGlassResponse) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *CreateBreakGlassResponse) GetCredential() string {
+	if m != nil {
+		return m.Credential
+	}
+	return ""
+}
+
+func (m *CreateBreakGlassResponse) GetExpires() int64 {
+	if m != nil {
+		return m.Expires
+	}
+	return 0
+}
+
+// MARK: list break glass request/response
+type ListBreakGlassRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListBreakGlassRequest) Reset()         { *m = ListBreakGlassRequest{} }
+func (m *ListBreakGlassRequest) String() string { return proto.CompactTextString(m) }
+func (*ListBreakGlassRequest) ProtoMessage()    {}
+func (*ListBreakGlassRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{40}
+}
+
+func (m *ListBreakGlassRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListBreakGlassRequest.Unmarshal(m, b)
+}
+func (m *ListBreakGlassRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListBreakGlassRequest.Marshal(b, m, deterministic)
+}
+func (m *ListBreakGlassRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListBreakGlassRequest.Merge(m, src)
+}
+func (m *ListBreakGlassRequest) XXX_Size() int {
+	return xxx_messageInfo_ListBreakGlassRequest.Size(m)
+}
+func (m *ListBreakGlassRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListBreakGlassRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListBreakGlassRequest proto.InternalMessageInfo
+
+type ListBreakGlassResponse struct {
+	Credentials          []*BreakGlass `protobuf:"bytes,1,rep,name=credentials,proto3" json:"credentials,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *ListBreakGlassResponse) Reset()         { *m = ListBreakGlassResponse{} }
+func (m *ListBreakGlassResponse) String() string { return proto.CompactTextString(m) }
+func (*ListBreakGlassResponse) ProtoMessage()    {}
+func (*ListBreakGlassResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{41}
+}
+
+func (m *ListBreakGlassResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListBreakGlassResponse.Unmarshal(m, b)
+}
+func (m *ListBreakGlassResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListBreakGlassResponse.Marshal(b, m, deterministic)
+}
+func (m *ListBreakGlassResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListBreakGlassResponse.Merge(m, src)
+}
+func (m *ListBreakGlassResponse) XXX_Size() int {
+	return xxx_messageInfo_ListBreakGlassResponse.Size(m)
+}
+func (m *ListBreakGlassResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListBreakGlassResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListBreakGlassResponse proto.InternalMessageInfo
+
+func (m *ListBreakGlassResponse) GetCredentials() []*BreakGlass {
+	if m != nil {
+		return m.Credentials
+	}
+	return nil
+}
+
+// MARK: revoke break glass request/response
+type RevokeBreakGlassRequest struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RevokeBreakGlassRequest) Reset()         { *m = RevokeBreakGlassRequest{} }
+func (m *RevokeBreakGlassRequest) String() string { return proto.CompactTextString(m) }
+func (*RevokeBreakGlassRequest) ProtoMessage()    {}
+func (*RevokeBreakGlassRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{42}
+}
+
+func (m *RevokeBreakGlassRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RevokeBreakGlassRequest.Unmarshal(m, b)
+}
+func (m *RevokeBreakGlassRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RevokeBreakGlassRequest.Marshal(b, m, deterministic)
+}
+func (m *RevokeBreakGlassRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RevokeBreakGlassRequest.Merge(m, src)
+}
+func (m *RevokeBreakGlassRequest) XXX_Size() int {
+	return xxx_messageInfo_RevokeBreakGlassRequest.Size(m)
+}
+func (m *RevokeBreakGlassRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RevokeBreakGlassRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RevokeBreakGlassRequest proto.InternalMessageInfo
+
+func (m *RevokeBreakGlassRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type RevokeBreakGlassResponse struct {
+	Status               int32    `protobuf:"varint,1,opt,name=status,proto3" json:"status,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RevokeBreakGlassResponse) Reset()         { *m = RevokeBreakGlassResponse{} }
+func (m *RevokeBreakGlassResponse) String() string { return proto.CompactTextString(m) }
+func (*RevokeBreakGlassResponse) ProtoMessage()    {}
+func (*RevokeBreakGlassResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{43}
+}
+
+func (m *RevokeBreakGlassResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RevokeBreakGlassResponse.Unmarshal(m, b)
+}
+func (m *RevokeBreakGlassResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RevokeBreakGlassResponse.Marshal(b, m, deterministic)
+}
+func (m *RevokeBreakGlassResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RevokeBreakGlassResponse.Merge(m, src)
+}
+func (m *RevokeBreakGlassResponse) XXX_Size() int {
+	return xxx_messageInfo_RevokeBreakGlassResponse.Size(m)
+}
+func (m *RevokeBreakGlassResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RevokeBreakGlassResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RevokeBreakGlassResponse proto.InternalMessageInfo
+
+func (m *RevokeBreakGlassResponse) GetStatus() int32 {
+	if m != nil {
+		return m.Status
+	}
+	return 0
+}
+
+// MARK: break glass
+type BreakGlass struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Client               string   `protobuf:"bytes,2,opt,name=client,proto3" json:"client,omitempty"`
+	Routes               []string `protobuf:"bytes,3,rep,name=routes,proto3" json:"routes,omitempty"`
+	SecretHash           []byte   `protobuf:"bytes,4,opt,name=secret_hash,json=secretHash,proto3" json:"secret_hash,omitempty"`
+	Created              int64    `protobuf:"varint,5,opt,name=created,proto3" json:"created,omitempty"`
+	Expires              int64    `protobuf:"varint,6,opt,name=expires,proto3" json:"expires,omitempty"`
+	UsesLeft             int32    `protobuf:"varint,7,opt,name=uses_left,json=usesLeft,proto3" json:"uses_left,omitempty"`
+	Used                 int32    `protobuf:"varint,8,opt,name=used,proto3" json:"used,omitempty"`
+	Reason               string   `protobuf:"bytes,9,opt,name=reason,proto3" json:"reason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BreakGlass) Reset()         { *m = BreakGlass{} }
+func (m *BreakGlass) String() string { return proto.CompactTextString(m) }
+func (*BreakGlass) ProtoMessage()    {}
+func (*BreakGlass) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{44}
+}
+
+func (m *BreakGlass) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BreakGlass.Unmarshal(m, b)
+}
+func (m *BreakGlass) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BreakGlass.Marshal(b, m, deterministic)
+}
+func (m *BreakGlass) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BreakGlass.Merge(m, src)
+}
+func (m *BreakGlass) XXX_Size() int {
+	return xxx_messageInfo_BreakGlass.Size(m)
+}
+func (m *BreakGlass) XXX_DiscardUnknown() {
+	xxx_messageInfo_BreakGlass.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BreakGlass proto.InternalMessageInfo
+
+func (m *BreakGlass) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *BreakGlass) GetClient() string {
+	if m != nil {
+		return m.Client
+	}
+	return ""
+}
+
+func (m *BreakGlass) GetRoutes() []string {
+	if m != nil {
+		return m.Routes
+	}
+	return nil
+}
+
+func (m *BreakGlass) GetSecretHash() []byte {
+	if m != nil {
+		return m.SecretHash
+	}
+	return nil
+}
+
+func (m *BreakGlass) GetCreated() int64 {
+	if m != nil {
+		return m.Created
+	}
+	return 0
+}
+
+func (m *BreakGlass) GetExpires() int64 {
+	if m != nil {
+		return m.Expires
+	}
+	return 0
+}
+
+func (m *BreakGlass) GetUsesLeft() int32 {
+	if m != nil {
+		return m.UsesLeft
+	}
+	return 0
+}
+
+func (m *BreakGlass) GetUsed() int32 {
+	if m != nil {
+		return m.Used
+	}
+	return 0
+}
+
+func (m *BreakGlass) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+// MARK: invalidate subnets request/response
+type InvalidateSubnetsRequest struct {
+	User                 string   `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	All                  bool     `protobuf:"varint,2,opt,name=all,proto3" json:"all,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *InvalidateSubnetsRequest) Reset()         { *m = InvalidateSubnetsRequest{} }
+func (m *InvalidateSubnetsRequest) String() string { return proto.CompactTextString(m) }
+func (*InvalidateSubnetsRequest) ProtoMessage()    {}
+func (*InvalidateSubnetsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{45}
+}
+
+func (m *InvalidateSubnetsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_InvalidateSubnetsRequest.Unmarshal(m, b)
+}
+func (m *InvalidateSubnetsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_InvalidateSubnetsRequest.Marshal(b, m, deterministic)
+}
+func (m *InvalidateSubnetsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_InvalidateSubnetsRequest.Merge(m, src)
+}
+func (m *InvalidateSubnetsRequest) XXX_Size() int {
+	return xxx_messageInfo_InvalidateSubnetsRequest.Size(m)
+}
+func (m *InvalidateSubnetsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_InvalidateSubnetsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_InvalidateSubnetsRequest proto.InternalMessageInfo
+
+func (m *InvalidateSubnetsRequest) GetUser() string {
+	if m != nil {
+		return m.User
+	}
+	return ""
+}
+
+func (m *InvalidateSubnetsRequest) GetAll() bool {
+	if m != nil {
+		return m.All
+	}
+	return false
+}
+
+type InvalidateSubnetsResponse struct {
+	Invalidated          int32    `protobuf:"varint,1,opt,name=invalidated,proto3" json:"invalidated,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *InvalidateSubnetsResponse) Reset()         { *m = InvalidateSubnetsResponse{} }
+func (m *InvalidateSubnetsResponse) String() string { return proto.CompactTextString(m) }
+func (*InvalidateSubnetsResponse) ProtoMessage()    {}
+func (*InvalidateSubnetsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{46}
+}
+
+func (m *InvalidateSubnetsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_InvalidateSubnetsResponse.Unmarshal(m, b)
+}
+func (m *InvalidateSubnetsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_InvalidateSubnetsResponse.Marshal(b, m, deterministic)
+}
+func (m *InvalidateSubnetsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_InvalidateSubnetsResponse.Merge(m, src)
+}
+func (m *InvalidateSubnetsResponse) XXX_Size() int {
+	return xxx_messageInfo_InvalidateSubnetsResponse.Size(m)
+}
+func (m *InvalidateSubnetsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_InvalidateSubnetsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_InvalidateSubnetsResponse proto.InternalMessageInfo
+
+func (m *InvalidateSubnetsResponse) GetInvalidated() int32 {
+	if m != nil {
+		return m.Invalidated
+	}
+	return 0
+}
+
+// MARK: Route
+type Route struct {
+	Cidr                 string   `protobuf:"bytes,1,opt,name=cidr,proto3" json:"cidr,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Route) Reset()         { *m = Route{} }
+func (m *Route) String() string { return proto.CompactTextString(m) }
+func (*Route) ProtoMessage()    {}
+func (*Route) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{47}
+}
+
+func (m *Route) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Route.Unmarshal(m, b)
+}
+func (m *Route) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Route.Marshal(b, m, deterministic)
+}
+func (m *Route) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Route.Merge(m, src)
+}
+func (m *Route) XXX_Size() int {
+	return xxx_messageInfo_Route.Size(m)
+}
+func (m *Route) XXX_DiscardUnknown() {
+	xxx_messageInfo_Route.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Route proto.InternalMessageInfo
+
+func (m *Route) GetCidr() string {
+	if m != nil {
+		return m.Cidr
+	}
+	return ""
+}
+
+// MARK: create client request/response
+type CreateClientRequest struct {
+	Client string `protobuf:"bytes,1,opt,name=client,proto3" json:"client,omitempty"`
+	Force  bool   `protobuf:"varint,2,opt,name=force,proto3" json:"force,omitempty"`
+	// user the certificate is issued to, only they may log in with it. Defaults to client
+	Owner                string   `protobuf:"bytes,3,opt,name=owner,proto3" json:"owner,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateClientRequest) Reset()         { *m = CreateClientRequest{} }
+func (m *CreateClientRequest) String() string { return proto.CompactTextString(m) }
+func (*CreateClientRequest) ProtoMessage()    {}
+func (*CreateClientRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{48}
+}
+
+func (m *CreateClientRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateClientRequest.Unmarshal(m, b)
+}
+func (m *CreateClientRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateClientRequest.Marshal(b, m, deterministic)
+}
+func (m *CreateClientRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateClientRequest.Merge(m, src)
+}
+func (m *CreateClientRequest) XXX_Size() int {
+	return xxx_messageInfo_CreateClientRequest.Size(m)
+}
+func (m *CreateClientRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateClientRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateClientRequest proto.InternalMessageInfo
+
+func (m *CreateClientRequest) GetClient() string {
+	if m != nil {
+		return m.Client
+	}
+	return ""
+}
+
+func (m *CreateClientRequest) GetForce() bool {
+	if m != nil {
+		return m.Force
+	}
+	return false
+}
+
+func (m *CreateClientRequest) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+type CreateClientResponse struct {
+	Config               string   `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateClientResponse) Reset()         { *m = CreateClientResponse{} }
+func (m *CreateClientResponse) String() string { return proto.CompactTextString(m) }
+func (*CreateClientResponse) ProtoMessage()    {}
+func (*CreateClientResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{49}
+}
+
+func (m *CreateClientResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateClientResponse.Unmarshal(m, b)
+}
+func (m *CreateClientResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateClientResponse.Marshal(b, m, deterministic)
+}
+func (m *CreateClientResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateClientResponse.Merge(m, src)
+}
+func (m *CreateClientResponse) XXX_Size() int {
+	return xxx_messageInfo_CreateClientResponse.Size(m)
+}
+func (m *CreateClientResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateClientResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateClientResponse proto.InternalMessageInfo
+
+func (m *CreateClientResponse) GetConfig() string {
+	if m != nil {
+		return m.Config
+	}
+	return ""
+}
+
+// MARK: get client request/response
+type GetClientRequest struct {
+	Client               string   `protobuf:"bytes,1,opt,name=client,proto3" json:"client,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetClientRequest) Reset()         { *m = GetClientRequest{} }
+func (m *GetClientRequest) String() string { return proto.CompactTextString(m) }
+func (*GetClientRequest) ProtoMessage()    {}
+func (*GetClientRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{50}
+}
+
+func (m *GetClientRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetClientRequest.Unmarshal(m, b)
+}
+func (m *GetClientRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetClientRequest.Marshal(b, m, deterministic)
+}
+func (m *GetClientRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetClientRequest.Merge(m, src)
+}
+func (m *GetClientRequest) XXX_Size() int {
+	return xxx_messageInfo_GetClientRequest.Size(m)
+}
+func (m *GetClientRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetClientRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetClientRequest proto.InternalMessageInfo
+
+func (m *GetClientRequest) GetClient() string {
+	if m != nil {
+		return m.Client
+	}
+	return ""
+}
+
+type GetClientResponse struct {
+	Status               ClientStatus `protobuf:"varint,1,opt,name=status,proto3,enum=protobuf.ClientStatus" json:"status,omitempty"`
+	ExpiresDate          int64        `protobuf:"varint,2,opt,name=expires_date,json=expiresDate,proto3" json:"expires_date,omitempty"`
+	RevocationDate       int64        `protobuf:"varint,3,opt,name=revocation_date,json=revocationDate,proto3" json:"revocation_date,omitempty"`
+	Config               string       `protobuf:"bytes,4,opt,name=config,proto3" json:"config,omitempty"`
+	Owner                string       `protobuf:"bytes,5,opt,name=owner,proto3" json:"owner,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *GetClientResponse) Reset()         { *m = GetClientResponse{} }
+func (m *GetClientResponse) String() string { return proto.CompactTextString(m) }
+func (*GetClientResponse) ProtoMessage()    {}
+func (*GetClientResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{51}
+}
+
+func (m *GetClientResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetClientResponse.Unmarshal(m, b)
+}
+func (m *GetClientResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetClientResponse.Marshal(b, m, deterministic)
+}
+func (m *GetClientResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetClientResponse.Merge(m, src)
+}
+func (m *GetClientResponse) XXX_Size() int {
+	return xxx_messageInfo_GetClientResponse.Size(m)
+}
+func (m *GetClientResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetClientResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetClientResponse proto.InternalMessageInfo
+
+func (m *GetClientResponse) GetStatus() ClientStatus {
+	if m != nil {
+		return m.Status
+	}
+	return ClientStatus_EXPIRED
+}
+
+func (m *GetClientResponse) GetExpiresDate() int64 {
+	if m != nil {
+		return m.ExpiresDate
+	}
+	return 0
+}
+
+func (m *GetClientResponse) GetRevocationDate() int64 {
+	if m != nil {
+		return m.RevocationDate
+	}
+	return 0
+}
+
+func (m *GetClientResponse) GetConfig() string {
+	if m != nil {
+		return m.Config
+	}
+	return ""
+}
+
+func (m *GetClientResponse) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+// MARK: revoke client request/response
+type RevokeClientRequest struct {
+	Client               string   `protobuf:"bytes,1,opt,name=client,proto3" json:"client,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RevokeClientRequest) Reset()         { *m = RevokeClientRequest{} }
+func (m *RevokeClientRequest) String() string { return proto.CompactTextString(m) }
+func (*RevokeClientRequest) ProtoMessage()    {}
+func (*RevokeClientRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{52}
+}
+
+func (m *RevokeClientRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RevokeClientRequest.Unmarshal(m, b)
+}
+func (m *RevokeClientRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RevokeClientRequest.Marshal(b, m, deterministic)
+}
+func (m *RevokeClientRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RevokeClientRequest.Merge(m, src)
+}
+func (m *RevokeClientRequest) XXX_Size() int {
+	return xxx_messageInfo_RevokeClientRequest.Size(m)
+}
+func (m *RevokeClientRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RevokeClientRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RevokeClientRequest proto.InternalMessageInfo
+
+func (m *RevokeClientRequest) GetClient() string {
+	if m != nil {
+		return m.Client
+	}
+	return ""
+}
+
+type RevokeClientResponse struct {
+	Status               int32    `protobuf:"varint,1,opt,name=status,proto3" json:"status,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RevokeClientResponse) Reset()         { *m = RevokeClientResponse{} }
+func (m *RevokeClientResponse) String() string { return proto.CompactTextString(m) }
+func (*RevokeClientResponse) ProtoMessage()    {}
+func (*RevokeClientResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{53}
+}
+
+func (m *RevokeClientResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RevokeClientResponse.Unmarshal(m, b)
+}
+func (m *RevokeClientResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RevokeClientResponse.Marshal(b, m, deterministic)
+}
+func (m *RevokeClientResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RevokeClientResponse.Merge(m, src)
+}
+func (m *RevokeClientResponse) XXX_Size() int {
+	return xxx_messageInfo_RevokeClientResponse.Size(m)
+}
+func (m *RevokeClientResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RevokeClientResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RevokeClientResponse proto.InternalMessageInfo
+
+func (m *RevokeClientResponse) GetStatus() int32 {
 	if m != nil {
 		return m.Status
 	}
 	return 0
 }
 
+// MARK: client owner
+type ClientOwner struct {
+	Client               string   `protobuf:"bytes,1,opt,name=client,proto3" json:"client,omitempty"`
+	Owner                string   `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	Created              int64    `protobuf:"varint,3,opt,name=created,proto3" json:"created,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ClientOwner) Reset()         { *m = ClientOwner{} }
+func (m *ClientOwner) String() string { return proto.CompactTextString(m) }
+func (*ClientOwner) ProtoMessage()    {}
+func (*ClientOwner) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9ed45b80aaca82a7, []int{54}
+}
+
+func (m *ClientOwner) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ClientOwner.Unmarshal(m, b)
+}
+func (m *ClientOwner) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ClientOwner.Marshal(b, m, deterministic)
+}
+func (m *ClientOwner) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ClientOwner.Merge(m, src)
+}
+func (m *ClientOwner) XXX_Size() int {
+	return xxx_messageInfo_ClientOwner.Size(m)
+}
+func (m *ClientOwner) XXX_DiscardUnknown() {
+	xxx_messageInfo_ClientOwner.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ClientOwner proto.InternalMessageInfo
+
+func (m *ClientOwner) GetClient() string {
+	if m != nil {
+		return m.Client
+	}
+	return ""
+}
+
+func (m *ClientOwner) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *ClientOwner) GetCreated() int64 {
+	if m != nil {
+		return m.Created
+	}
+	return 0
+}
+
 // MARK: list clients request/response
 type ListClientsRequest struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -840,7 +2779,7 @@ func (m *ListClientsRequest) Reset()         { *m = ListClientsRequest{} }
 func (m *ListClientsRequest) String() string { return proto.CompactTextString(m) }
 func (*ListClientsRequest) ProtoMessage()    {}
 func (*ListClientsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_9ed45b80aaca82a7, []int{17}
+	return fileDescriptor_9ed45b80aaca82a7, []int{55}
 }
 
 func (m *ListClientsRequest) XXX_Unmarshal(b []byte) error {
@@ -872,7 +2811,7 @@ func (m *ListClientsResponse) Reset()         { *m = ListClientsResponse{} }
 func (m *ListClientsResponse) String() string { return proto.CompactTextString(m) }
 func (*ListClientsResponse) ProtoMessage()    {}
 func (*ListClientsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_9ed45b80aaca82a7, []int{18}
+	return fileDescriptor_9ed45b80aaca82a7, []int{56}
 }
 
 func (m *ListClientsResponse) XXX_Unmarshal(b []byte) error {
@@ -914,7 +2853,7 @@ func (m *Client) Reset()         { *m = Client{} }
 func (m *Client) String() string { return proto.CompactTextString(m) }
 func (*Client) ProtoMessage()    {}
 func (*Client) Descriptor() ([]byte, []int) {
-	return fileDescriptor_9ed45b80aaca82a7, []int{19}
+	return fileDescriptor_9ed45b80aaca82a7, []int{57}
 }
 
 func (m *Client) XXX_Unmarshal(b []byte) error {
@@ -975,6 +2914,43 @@ func init() {
 	proto.RegisterType((*AuthenticateResponse)(nil), "protobuf.AuthenticateResponse")
 	proto.RegisterType((*Connection)(nil), "protobuf.Connection")
 	proto.RegisterType((*Allocation)(nil), "protobuf.Allocation")
+	proto.RegisterType((*PinAllocationRequest)(nil), "protobuf.PinAllocationRequest")
+	proto.RegisterType((*PinAllocationResponse)(nil), "protobuf.PinAllocationResponse")
+	proto.RegisterType((*UnpinAllocationRequest)(nil), "protobuf.UnpinAllocationRequest")
+	proto.RegisterType((*UnpinAllocationResponse)(nil), "protobuf.UnpinAllocationResponse")
+	proto.RegisterType((*ListPinsRequest)(nil), "protobuf.ListPinsRequest")
+	proto.RegisterType((*ListPinsResponse)(nil), "protobuf.ListPinsResponse")
+	proto.RegisterType((*FirewallDiffRequest)(nil), "protobuf.FirewallDiffRequest")
+	proto.RegisterType((*FirewallDiffResponse)(nil), "protobuf.FirewallDiffResponse")
+	proto.RegisterType((*FirewallDrift)(nil), "protobuf.FirewallDrift")
+	proto.RegisterType((*EnrollLocalUserRequest)(nil), "protobuf.EnrollLocalUserRequest")
+	proto.RegisterType((*EnrollLocalUserResponse)(nil), "protobuf.EnrollLocalUserResponse")
+	proto.RegisterType((*DeleteLocalUserRequest)(nil), "protobuf.DeleteLocalUserRequest")
+	proto.RegisterType((*DeleteLocalUserResponse)(nil), "protobuf.DeleteLocalUserResponse")
+	proto.RegisterType((*ListLocalUsersRequest)(nil), "protobuf.ListLocalUsersRequest")
+	proto.RegisterType((*ListLocalUsersResponse)(nil), "protobuf.ListLocalUsersResponse")
+	proto.RegisterType((*LocalUser)(nil), "protobuf.LocalUser")
+	proto.RegisterType((*ListLockoutsRequest)(nil), "protobuf.ListLockoutsRequest")
+	proto.RegisterType((*ListLockoutsResponse)(nil), "protobuf.ListLockoutsResponse")
+	proto.RegisterType((*ClearLockoutRequest)(nil), "protobuf.ClearLockoutRequest")
+	proto.RegisterType((*ClearLockoutResponse)(nil), "protobuf.ClearLockoutResponse")
+	proto.RegisterType((*Lockout)(nil), "protobuf.Lockout")
+	proto.RegisterType((*EnrollServiceAccountRequest)(nil), "protobuf.EnrollServiceAccountRequest")
+	proto.RegisterType((*EnrollServiceAccountResponse)(nil), "protobuf.EnrollServiceAccountResponse")
+	proto.RegisterType((*DeleteServiceAccountRequest)(nil), "protobuf.DeleteServiceAccountRequest")
+	proto.RegisterType((*DeleteServiceAccountResponse)(nil), "protobuf.DeleteServiceAccountResponse")
+	proto.RegisterType((*ListServiceAccountsRequest)(nil), "protobuf.ListServiceAccountsRequest")
+	proto.RegisterType((*ListServiceAccountsResponse)(nil), "protobuf.ListServiceAccountsResponse")
+	proto.RegisterType((*ServiceAccount)(nil), "protobuf.ServiceAccount")
+	proto.RegisterType((*CreateBreakGlassRequest)(nil), "protobuf.CreateBreakGlassRequest")
+	proto.RegisterType((*CreateBreakGlassResponse)(nil), "protobuf.CreateBreakGlassResponse")
+	proto.RegisterType((*ListBreakGlassRequest)(nil), "protobuf.ListBreakGlassRequest")
+	proto.RegisterType((*ListBreakGlassResponse)(nil), "protobuf.ListBreakGlassResponse")
+	proto.RegisterType((*RevokeBreakGlassRequest)(nil), "protobuf.RevokeBreakGlassRequest")
+	proto.RegisterType((*RevokeBreakGlassResponse)(nil), "protobuf.RevokeBreakGlassResponse")
+	proto.RegisterType((*BreakGlass)(nil), "protobuf.BreakGlass")
+	proto.RegisterType((*InvalidateSubnetsRequest)(nil), "protobuf.InvalidateSubnetsRequest")
+	proto.RegisterType((*InvalidateSubnetsResponse)(nil), "protobuf.InvalidateSubnetsResponse")
 	proto.RegisterType((*Route)(nil), "protobuf.Route")
 	proto.RegisterType((*CreateClientRequest)(nil), "protobuf.CreateClientRequest")
 	proto.RegisterType((*CreateClientResponse)(nil), "protobuf.CreateClientResponse")
@@ -982,6 +2958,7 @@ func init() {
 	proto.RegisterType((*GetClientResponse)(nil), "protobuf.GetClientResponse")
 	proto.RegisterType((*RevokeClientRequest)(nil), "protobuf.RevokeClientRequest")
 	proto.RegisterType((*RevokeClientResponse)(nil), "protobuf.RevokeClientResponse")
+	proto.RegisterType((*ClientOwner)(nil), "protobuf.ClientOwner")
 	proto.RegisterType((*ListClientsRequest)(nil), "protobuf.ListClientsRequest")
 	proto.RegisterType((*ListClientsResponse)(nil), "protobuf.ListClientsResponse")
 	proto.RegisterType((*Client)(nil), "protobuf.Client")
@@ -990,54 +2967,137 @@ func init() {
 func init() { proto.RegisterFile("vpn_service.proto", fileDescriptor_9ed45b80aaca82a7) }
 
 var fileDescriptor_9ed45b80aaca82a7 = []byte{
-	// 741 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x55, 0xcd, 0x6e, 0xd3, 0x40,
-	0x10, 0xc6, 0xf9, 0x6b, 0x3b, 0x49, 0xd3, 0x74, 0x13, 0x15, 0xcb, 0x6d, 0x51, 0x6a, 0x84, 0x1a,
-	0x15, 0xc8, 0xa1, 0xad, 0xb8, 0x47, 0x49, 0x54, 0x15, 0x0a, 0x94, 0xad, 0x14, 0xb8, 0x45, 0xae,
-	0xbb, 0x29, 0x2b, 0x82, 0x6d, 0xec, 0x4d, 0x04, 0x77, 0x1e, 0x83, 0x3b, 0xef, 0xc4, 0xd3, 0x20,
-	0xef, 0xae, 0xb3, 0x6b, 0xc7, 0x21, 0xbd, 0x71, 0x4a, 0x76, 0xe6, 0xf3, 0x37, 0x33, 0xdf, 0xec,
-	0xcc, 0xc2, 0xee, 0x3c, 0xf0, 0xc6, 0x11, 0x09, 0xe7, 0xd4, 0x25, 0xdd, 0x20, 0xf4, 0x99, 0x8f,
-	0x36, 0xf9, 0xcf, 0xed, 0x6c, 0x62, 0x3f, 0x87, 0xdd, 0x01, 0x8d, 0x5c, 0xdf, 0xf3, 0x88, 0xcb,
-	0x30, 0xf9, 0x36, 0x23, 0x11, 0x43, 0x7b, 0x50, 0x71, 0xa7, 0x94, 0x78, 0xcc, 0x34, 0xda, 0x46,
-	0x67, 0x0b, 0xcb, 0x93, 0xfd, 0x02, 0x90, 0x0e, 0x8e, 0x02, 0xdf, 0x8b, 0x48, 0x8c, 0x8e, 0x98,
-	0xc3, 0x66, 0x11, 0x47, 0x97, 0xb1, 0x3c, 0xd9, 0x1f, 0x61, 0xef, 0x8a, 0x46, 0xac, 0x37, 0x9d,
-	0xfa, 0xae, 0xc3, 0xa8, 0xef, 0x45, 0x6b, 0xf8, 0xd1, 0x33, 0xa8, 0xfb, 0xde, 0xf4, 0xc7, 0xd8,
-	0x11, 0x9f, 0x90, 0x3b, 0xb3, 0xd0, 0x36, 0x3a, 0x9b, 0x78, 0x3b, 0xb6, 0xf6, 0x12, 0xa3, 0xfd,
-	0x01, 0x1e, 0x2f, 0x11, 0xcb, 0x5c, 0x5e, 0x41, 0xd5, 0x51, 0x66, 0xd3, 0x68, 0x17, 0x3b, 0xd5,
-	0xd3, 0x56, 0x37, 0x29, 0xb7, 0xab, 0xbe, 0xc1, 0x3a, 0xd0, 0xbe, 0x17, 0x94, 0x7d, 0x51, 0x5a,
-	0x8a, 0xb2, 0x05, 0x65, 0xe6, 0x33, 0x67, 0x2a, 0xab, 0x13, 0x87, 0x38, 0x90, 0xab, 0xc0, 0x66,
-	0x21, 0x1b, 0x48, 0x31, 0x61, 0x1d, 0x68, 0x9b, 0x42, 0x94, 0x54, 0x20, 0x2e, 0x8a, 0x3d, 0x81,
-	0x66, 0x6f, 0xc6, 0x3e, 0x13, 0x8f, 0xd1, 0xb8, 0xcc, 0x44, 0x2b, 0x04, 0xa5, 0x09, 0x9d, 0x12,
-	0xa9, 0x14, 0xff, 0xaf, 0xe9, 0x57, 0x48, 0xe9, 0xf7, 0x14, 0xb6, 0x93, 0x58, 0xde, 0xfd, 0x98,
-	0x06, 0x66, 0x91, 0xbb, 0x6b, 0xca, 0x78, 0x19, 0xd8, 0x5d, 0x68, 0xa5, 0xe3, 0xac, 0x69, 0xe3,
-	0x1f, 0x03, 0x40, 0xa5, 0xbb, 0xb2, 0x77, 0x16, 0x6c, 0xce, 0x22, 0x12, 0x7a, 0xce, 0x57, 0x22,
-	0xb3, 0x5a, 0x9c, 0xd1, 0x39, 0x80, 0x12, 0x9b, 0x27, 0xb5, 0xaa, 0x29, 0x1a, 0x0e, 0x1d, 0x43,
-	0x25, 0xf4, 0x67, 0x8c, 0x44, 0x66, 0x89, 0xab, 0xbb, 0xa3, 0xbe, 0xc0, 0xb1, 0x1d, 0x4b, 0x77,
-	0xdc, 0xa1, 0x88, 0x7a, 0x2e, 0x31, 0xcb, 0x6d, 0xa3, 0x53, 0xc4, 0xe2, 0xb0, 0x2c, 0x46, 0x25,
-	0x47, 0x8c, 0x73, 0x00, 0x15, 0x7d, 0x65, 0x6d, 0x75, 0x28, 0xd0, 0x40, 0x56, 0x55, 0xa0, 0x81,
-	0xbd, 0x0f, 0x65, 0x9e, 0x41, 0xdc, 0x1c, 0x97, 0xde, 0x85, 0x49, 0x73, 0xe2, 0xff, 0x76, 0x1f,
-	0x9a, 0xfd, 0x90, 0x38, 0x8c, 0xf4, 0xf9, 0xc7, 0xeb, 0xee, 0x7c, 0x0b, 0xca, 0x13, 0x3f, 0x74,
-	0x89, 0xbc, 0xea, 0xe2, 0x10, 0x37, 0x29, 0x4d, 0xa2, 0x9a, 0xe4, 0xfa, 0xde, 0x84, 0xde, 0x2f,
-	0x58, 0xf8, 0xc9, 0x3e, 0x81, 0xc6, 0x05, 0x61, 0x0f, 0x8a, 0x68, 0xff, 0x36, 0x60, 0x57, 0x03,
-	0x4b, 0xe6, 0x6e, 0xaa, 0xfd, 0xf5, 0xd3, 0x3d, 0xed, 0x2e, 0x73, 0xe4, 0x0d, 0xf7, 0x26, 0xd7,
-	0x02, 0x1d, 0x41, 0x8d, 0x7c, 0x0f, 0x68, 0x48, 0xa2, 0xf1, 0x9d, 0xc3, 0x44, 0xfa, 0x45, 0x5c,
-	0x95, 0xb6, 0x81, 0xc3, 0x08, 0x3a, 0x86, 0x9d, 0x90, 0xcc, 0xa5, 0xb8, 0x02, 0x55, 0xe4, 0xa8,
-	0xba, 0x32, 0x73, 0xa0, 0xaa, 0xaa, 0x94, 0xaa, 0xea, 0x25, 0x34, 0x31, 0x99, 0xfb, 0x5f, 0x1e,
-	0x26, 0x65, 0x2c, 0x5a, 0x1a, 0xbe, 0xe6, 0x66, 0xb7, 0x00, 0xf1, 0x59, 0xe4, 0xe8, 0xc5, 0x1c,
-	0xf6, 0xa0, 0x99, 0xb2, 0x4a, 0x92, 0x13, 0xd8, 0x10, 0x61, 0x92, 0xad, 0xd2, 0xc8, 0x0a, 0x84,
-	0x13, 0x80, 0xfd, 0xcb, 0x80, 0x8a, 0xb0, 0xfd, 0x77, 0x59, 0x85, 0x4e, 0x25, 0x5d, 0xa7, 0x93,
-	0x33, 0xa8, 0xe9, 0xb1, 0x51, 0x15, 0x36, 0x86, 0x9f, 0xae, 0x2f, 0xf1, 0x70, 0xd0, 0x78, 0x84,
-	0xb6, 0xa0, 0x3c, 0xea, 0x5d, 0x5d, 0x0e, 0x1a, 0x46, 0x6c, 0xc7, 0xc3, 0xd1, 0xfb, 0x37, 0xc3,
-	0x41, 0xa3, 0x70, 0xfa, 0xb3, 0x0c, 0x30, 0xba, 0x7e, 0x77, 0x23, 0xde, 0x11, 0x34, 0x82, 0x9d,
-	0xcc, 0x1e, 0x43, 0x6d, 0x55, 0x5a, 0xfe, 0x8a, 0xb3, 0x8e, 0xfe, 0x81, 0x90, 0x32, 0x4b, 0x5e,
-	0x6d, 0xb7, 0x67, 0x79, 0x97, 0xdf, 0x93, 0x2c, 0x6f, 0xde, 0xc3, 0x70, 0x01, 0xa0, 0x9e, 0x2e,
-	0xb4, 0xaf, 0x3e, 0x58, 0x7a, 0xfd, 0xac, 0x83, 0x7c, 0xa7, 0x24, 0x7a, 0x0b, 0x35, 0x7d, 0x7d,
-	0xa2, 0x43, 0x6d, 0x8f, 0x2d, 0xaf, 0x6f, 0xeb, 0xc9, 0x2a, 0xb7, 0xa2, 0xd3, 0x07, 0x5d, 0xa7,
-	0xcb, 0xd9, 0x22, 0x3a, 0x5d, 0xee, 0x7e, 0x18, 0xc0, 0xd6, 0x62, 0xb4, 0x91, 0xa5, 0xc0, 0xd9,
-	0xe5, 0x60, 0xed, 0xe7, 0xfa, 0x54, 0x52, 0xfa, 0x20, 0xe9, 0x49, 0xe5, 0xcc, 0xa3, 0x9e, 0x54,
-	0xee, 0xfc, 0xbd, 0x86, 0xaa, 0x36, 0x51, 0xe8, 0x20, 0x73, 0x0b, 0x52, 0xe3, 0x67, 0x1d, 0xae,
-	0xf0, 0x0a, 0xae, 0xdb, 0x0a, 0xf7, 0x9e, 0xfd, 0x0d, 0x00, 0x00, 0xff, 0xff, 0x50, 0xc9, 0x95,
-	0x90, 0xd5, 0x08, 0x00, 0x00,
+	// 2068 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x58, 0xef, 0x6e, 0xdc, 0xb8,
+	0x11, 0xef, 0xee, 0x7a, 0xed, 0xdd, 0x59, 0xdb, 0xb1, 0xe5, 0xcd, 0x5a, 0x91, 0x1d, 0xc7, 0x56,
+	0x2e, 0xb9, 0x5c, 0x70, 0xe7, 0xbb, 0xe6, 0xae, 0xf9, 0xd4, 0x16, 0x75, 0x6d, 0x27, 0x4d, 0x9b,
+	0xeb, 0x19, 0xf2, 0x39, 0xe8, 0x01, 0x05, 0x16, 0xb2, 0x96, 0xeb, 0xb0, 0x56, 0x24, 0x55, 0xe4,
+	0x3a, 0xc9, 0xbd, 0x41, 0x3f, 0x16, 0xfd, 0x5a, 0xf4, 0x7b, 0x1f, 0xa2, 0xaf, 0xd2, 0x07, 0xe8,
+	0x43, 0x14, 0xc5, 0x90, 0x94, 0x48, 0xfd, 0xdb, 0x4d, 0x8b, 0x02, 0xf7, 0x49, 0xe2, 0xcc, 0x8f,
+	0x33, 0xe4, 0xfc, 0x23, 0x87, 0xb0, 0x79, 0x93, 0x44, 0x63, 0x46, 0xd2, 0x1b, 0x1a, 0x90, 0xc3,
+	0x24, 0x8d, 0x79, 0x6c, 0xf5, 0xc4, 0xe7, 0x72, 0x36, 0x75, 0x4f, 0x61, 0xf3, 0x84, 0xb2, 0x20,
+	0x8e, 0x22, 0x12, 0x70, 0x8f, 0xfc, 0x71, 0x46, 0x18, 0xb7, 0x46, 0xb0, 0x1c, 0x84, 0x94, 0x44,
+	0xdc, 0x6e, 0xed, 0xb7, 0x1e, 0xf5, 0x3d, 0x35, 0xb2, 0x6c, 0x58, 0x79, 0x43, 0x18, 0xf3, 0xaf,
+	0x88, 0xdd, 0x16, 0x8c, 0x6c, 0xe8, 0x7e, 0x0a, 0x96, 0x29, 0x86, 0x25, 0x71, 0xc4, 0x08, 0xca,
+	0x61, 0xdc, 0xe7, 0x33, 0x26, 0xe4, 0x74, 0x3d, 0x35, 0x72, 0xff, 0xd2, 0x82, 0xd1, 0x4b, 0xca,
+	0xf8, 0x51, 0x18, 0xc6, 0x81, 0xcf, 0x69, 0x1c, 0xb1, 0x45, 0xaa, 0x1f, 0xc0, 0x7a, 0x1c, 0x85,
+	0xef, 0xc7, 0xbe, 0x9c, 0x42, 0x26, 0x62, 0x05, 0x3d, 0x6f, 0x0d, 0xa9, 0x47, 0x19, 0xd1, 0xda,
+	0x81, 0x7e, 0xe2, 0x5f, 0x91, 0x31, 0xa3, 0xdf, 0x13, 0xbb, 0x23, 0x94, 0xf6, 0x90, 0x70, 0x4e,
+	0xbf, 0x27, 0xd6, 0x5d, 0x00, 0xc1, 0xe4, 0xf1, 0x35, 0x89, 0xec, 0x25, 0x21, 0x5f, 0xc0, 0xbf,
+	0x45, 0x82, 0xfb, 0x1e, 0xb6, 0x2b, 0x8b, 0x52, 0x1b, 0x79, 0x0a, 0x03, 0x5f, 0x93, 0xed, 0xd6,
+	0x7e, 0xe7, 0xd1, 0xe0, 0xc9, 0xf0, 0x30, 0xb3, 0xe2, 0xa1, 0x9e, 0xe3, 0x99, 0x40, 0xeb, 0x21,
+	0xdc, 0x8a, 0xc8, 0x3b, 0x3e, 0x36, 0xd4, 0x4a, 0xc3, 0xad, 0x21, 0xf9, 0x2c, 0x57, 0x7d, 0x25,
+	0x55, 0x1f, 0x4b, 0xfb, 0x15, 0x54, 0x0f, 0xa1, 0xcb, 0x63, 0xee, 0x87, 0xca, 0x84, 0x72, 0x80,
+	0x0b, 0x0a, 0x34, 0xd8, 0x6e, 0x97, 0x17, 0xa4, 0x25, 0x79, 0x26, 0xd0, 0xb5, 0xa5, 0xe1, 0x0b,
+	0x8a, 0x84, 0xe1, 0xdd, 0x3f, 0xb5, 0x60, 0xeb, 0x68, 0xc6, 0x5f, 0x93, 0x88, 0x53, 0xb4, 0x65,
+	0xe6, 0x10, 0x0b, 0x96, 0xa6, 0x34, 0x24, 0xca, 0x1d, 0xe2, 0xdf, 0x70, 0x52, 0xbb, 0xe0, 0xa4,
+	0xfb, 0xb0, 0x96, 0x29, 0x8b, 0xae, 0xc6, 0x34, 0x11, 0x1e, 0xe8, 0x7b, 0xab, 0x9a, 0xf8, 0x22,
+	0xb1, 0x0e, 0x00, 0xc7, 0x3c, 0x8d, 0xc3, 0xb1, 0x10, 0x2c, 0xfd, 0x30, 0x50, 0xb4, 0x67, 0x34,
+	0x24, 0xee, 0x21, 0x0c, 0x8b, 0x4b, 0x59, 0x10, 0x4f, 0xff, 0x6c, 0x03, 0xe8, 0x2d, 0x35, 0xc6,
+	0x90, 0x03, 0xbd, 0x19, 0x23, 0x69, 0xe4, 0xbf, 0xc9, 0xe2, 0x37, 0x1f, 0x5b, 0x5f, 0x01, 0x68,
+	0xc7, 0x89, 0x75, 0x37, 0x39, 0xd8, 0xc0, 0x59, 0x1f, 0xc3, 0x72, 0x1a, 0xcf, 0x38, 0x61, 0xf6,
+	0x92, 0xf0, 0xc0, 0x2d, 0x3d, 0xc3, 0x43, 0xba, 0xa7, 0xd8, 0xe8, 0x45, 0x46, 0xa3, 0x80, 0xd8,
+	0xdd, 0xfd, 0xd6, 0xa3, 0x8e, 0x27, 0x07, 0x55, 0x7b, 0x2d, 0xd7, 0xdb, 0x2b, 0x25, 0x7e, 0x38,
+	0xf6, 0x27, 0x93, 0x94, 0x30, 0x66, 0xaf, 0x48, 0x7b, 0x21, 0xed, 0x48, 0x92, 0x30, 0x39, 0x2e,
+	0xdf, 0x73, 0xc2, 0xc6, 0x29, 0x09, 0x08, 0xbd, 0x21, 0x13, 0xbb, 0x27, 0xd4, 0xac, 0x09, 0xaa,
+	0xa7, 0x88, 0x18, 0xff, 0x12, 0xc6, 0xd0, 0x36, 0x7d, 0x01, 0xe9, 0x0b, 0xca, 0x39, 0x9a, 0xe7,
+	0x0e, 0xf4, 0x42, 0x9f, 0xf1, 0x71, 0x4a, 0xa6, 0x36, 0x08, 0xe6, 0x0a, 0x8e, 0x3d, 0x32, 0x75,
+	0x39, 0x80, 0xb6, 0x40, 0xa3, 0x7d, 0xd7, 0xa1, 0x4d, 0x13, 0x65, 0xd9, 0x36, 0x4d, 0x30, 0x74,
+	0x92, 0x38, 0x0e, 0x55, 0x14, 0x88, 0x7f, 0x9c, 0x9b, 0xd0, 0x28, 0x22, 0x13, 0xe1, 0xf7, 0x9e,
+	0xa7, 0x46, 0x88, 0xa5, 0xc9, 0xcd, 0x53, 0x61, 0x9f, 0xbe, 0x27, 0xfe, 0xdd, 0x9f, 0xc3, 0xf0,
+	0x8c, 0x46, 0x86, 0xe9, 0x17, 0xd4, 0x88, 0x92, 0x7e, 0xf7, 0x73, 0xb8, 0x5d, 0x9a, 0xbf, 0x20,
+	0x8e, 0xbe, 0x80, 0xd1, 0x45, 0x94, 0xfc, 0x17, 0x2a, 0xdd, 0x1f, 0xc3, 0x76, 0x65, 0xc6, 0x02,
+	0x25, 0x9b, 0x70, 0x0b, 0x53, 0xf0, 0x8c, 0xea, 0xdc, 0xfb, 0x29, 0x6c, 0x68, 0x92, 0x9a, 0xfe,
+	0x08, 0x96, 0x12, 0xba, 0xa0, 0xd6, 0x08, 0x84, 0x7b, 0x1b, 0xb6, 0x9e, 0xd1, 0x94, 0xbc, 0xf5,
+	0xc3, 0xf0, 0x84, 0x4e, 0xa7, 0x99, 0xd0, 0xe7, 0x30, 0x2c, 0x92, 0x95, 0xe0, 0xcf, 0x61, 0x79,
+	0x92, 0xd2, 0x29, 0xcf, 0x44, 0x6f, 0x6b, 0xd1, 0x39, 0x1e, 0xf9, 0x9e, 0x82, 0xb9, 0x7f, 0x6f,
+	0xc1, 0x5a, 0x81, 0x83, 0xce, 0xba, 0xa6, 0xd1, 0x24, 0xab, 0x09, 0xf8, 0x5f, 0xe7, 0x7c, 0xe1,
+	0xd0, 0x8e, 0x76, 0xa8, 0x61, 0xc5, 0xa5, 0x72, 0x71, 0x7f, 0x43, 0x19, 0xc3, 0x24, 0x50, 0xe9,
+	0xd4, 0xdd, 0xef, 0x60, 0x95, 0x54, 0x54, 0x91, 0x4b, 0x0c, 0x33, 0x81, 0xbc, 0xe3, 0xa9, 0x9f,
+	0x81, 0x96, 0x05, 0x68, 0x20, 0x68, 0x12, 0xe2, 0xde, 0xc0, 0xe8, 0x34, 0x4a, 0xe3, 0x30, 0x7c,
+	0x19, 0x07, 0x7e, 0x78, 0xc1, 0x48, 0x6a, 0xd4, 0x31, 0x4c, 0xf6, 0x6c, 0xcd, 0xf8, 0x8f, 0x05,
+	0x21, 0xf1, 0x19, 0x7b, 0x1b, 0xa7, 0x93, 0xac, 0x20, 0x64, 0x63, 0x5c, 0xab, 0x52, 0xd3, 0x11,
+	0x6a, 0x8c, 0x4c, 0x9e, 0xc6, 0x69, 0x40, 0x54, 0xfc, 0xca, 0x81, 0x7b, 0x01, 0xdb, 0x15, 0xbd,
+	0xca, 0xde, 0xf7, 0x60, 0xc0, 0x63, 0x9e, 0x8c, 0x19, 0x09, 0x52, 0x92, 0xc5, 0x0f, 0x20, 0xe9,
+	0x5c, 0x50, 0x30, 0xef, 0x04, 0x60, 0x96, 0x86, 0xd9, 0xb1, 0x8a, 0xe3, 0x8b, 0x34, 0x74, 0x3f,
+	0x85, 0xd1, 0x09, 0x09, 0x09, 0x27, 0x1f, 0xb2, 0x1d, 0x0c, 0xc6, 0x0a, 0x7a, 0x41, 0x30, 0x6e,
+	0xc3, 0x6d, 0x8c, 0xbc, 0x7c, 0x42, 0x1e, 0x92, 0xc7, 0x30, 0x2a, 0x33, 0x94, 0xa8, 0x4f, 0xa0,
+	0x8b, 0xda, 0xb2, 0xf0, 0xd9, 0xd2, 0xe1, 0xa3, 0xd5, 0x4a, 0x84, 0xfb, 0x8f, 0x16, 0xf4, 0x73,
+	0x62, 0xad, 0x07, 0xee, 0xc3, 0x5a, 0x66, 0xf1, 0xf1, 0x6b, 0x9f, 0xbd, 0x16, 0x06, 0x58, 0xf5,
+	0x56, 0x33, 0xe2, 0xaf, 0x7c, 0xf6, 0xba, 0x6c, 0xc1, 0x8e, 0x80, 0x98, 0x16, 0x1c, 0x15, 0xca,
+	0xb0, 0xf6, 0x95, 0x0d, 0x2b, 0x41, 0x4a, 0xc4, 0x6d, 0x41, 0xd6, 0xdd, 0x6c, 0x68, 0x7d, 0x04,
+	0xeb, 0xa2, 0xd6, 0x49, 0xb9, 0x9c, 0xc8, 0xd2, 0xdb, 0xf1, 0x56, 0x91, 0xfa, 0x2d, 0x4a, 0xe6,
+	0x24, 0xc1, 0xcc, 0x52, 0x46, 0xb8, 0x8e, 0x67, 0x3c, 0xb7, 0xcd, 0x29, 0x0c, 0x8b, 0x64, 0x65,
+	0x99, 0xcf, 0xa0, 0x17, 0x2a, 0x9a, 0x32, 0xce, 0x66, 0xc1, 0x38, 0xc8, 0xf1, 0x72, 0x88, 0xfb,
+	0x35, 0x6c, 0x1d, 0x87, 0xc4, 0x4f, 0x33, 0x8e, 0xf6, 0x6c, 0x25, 0xb9, 0x36, 0xa0, 0x73, 0x4d,
+	0xde, 0xab, 0xe8, 0xc0, 0x5f, 0xa4, 0xf8, 0xa1, 0x2c, 0xad, 0x3d, 0x0f, 0x7f, 0xdd, 0x2f, 0x60,
+	0x58, 0x14, 0xa7, 0x56, 0x85, 0x46, 0x40, 0x3a, 0x99, 0x28, 0xdf, 0x67, 0x43, 0xf7, 0xcf, 0x2d,
+	0x58, 0x51, 0xe8, 0x0f, 0xd4, 0xea, 0x40, 0x6f, 0xea, 0xd3, 0x70, 0x96, 0x8a, 0xb4, 0x10, 0xb7,
+	0xab, 0x6c, 0x8c, 0xd9, 0x29, 0x4c, 0xaa, 0x08, 0x22, 0x3f, 0x3a, 0xde, 0x00, 0x69, 0xcf, 0x24,
+	0x49, 0x40, 0xe2, 0xe0, 0x9a, 0x4c, 0xc6, 0xb3, 0x88, 0xd3, 0x50, 0x39, 0x65, 0x20, 0x69, 0x17,
+	0x48, 0x72, 0xff, 0xd6, 0x82, 0x1d, 0x99, 0x49, 0xe7, 0xf2, 0xc6, 0x7a, 0x14, 0x04, 0xf1, 0x2c,
+	0x32, 0xad, 0x23, 0xce, 0x6f, 0xb5, 0x4e, 0xfc, 0x6f, 0xbc, 0x8e, 0x60, 0x7a, 0xa7, 0xf1, 0x1f,
+	0x48, 0xc0, 0xb3, 0x24, 0xce, 0xc7, 0xb8, 0x14, 0xca, 0xd8, 0x8c, 0x64, 0x41, 0x25, 0xb3, 0x79,
+	0x20, 0x68, 0x2a, 0xaa, 0xf2, 0x4c, 0xef, 0x9a, 0x99, 0xfe, 0x14, 0x76, 0xeb, 0xd7, 0x67, 0x64,
+	0x9a, 0x99, 0xe9, 0x6a, 0xe4, 0xfe, 0x04, 0x76, 0x64, 0x72, 0xd6, 0xef, 0xab, 0xe9, 0x80, 0x79,
+	0x0a, 0xbb, 0xf5, 0xd3, 0x16, 0x24, 0xf6, 0x2e, 0x38, 0x18, 0xa3, 0xc5, 0x59, 0x79, 0x04, 0x9f,
+	0xc3, 0x4e, 0x2d, 0x57, 0x09, 0xfd, 0x0a, 0x7a, 0xbe, 0xa2, 0xa9, 0x40, 0xb6, 0x75, 0x20, 0x97,
+	0x16, 0x92, 0x23, 0xf1, 0x56, 0xbf, 0x5e, 0x64, 0xfe, 0xdf, 0xbc, 0x75, 0x0f, 0x06, 0xd2, 0x8c,
+	0xb2, 0x48, 0x2c, 0xc9, 0x0a, 0x20, 0x49, 0xa2, 0x44, 0x34, 0x66, 0x3a, 0x06, 0xf9, 0xf6, 0xb1,
+	0xf8, 0xff, 0x65, 0x4a, 0xfc, 0xeb, 0xe7, 0xa1, 0xcf, 0x16, 0x36, 0x1b, 0xba, 0x9e, 0xb4, 0x0b,
+	0xf5, 0xc4, 0x81, 0x5e, 0x48, 0xa7, 0x84, 0xd3, 0x37, 0xb2, 0xb9, 0xe8, 0x78, 0xf9, 0x58, 0x55,
+	0x37, 0x26, 0xd6, 0xd6, 0x15, 0xd5, 0x8d, 0x09, 0x39, 0xc4, 0x67, 0x71, 0xa4, 0xae, 0x35, 0x6a,
+	0xe4, 0x4e, 0xc0, 0xae, 0x2e, 0x49, 0xd9, 0x1e, 0xcf, 0xd1, 0x2c, 0x0d, 0xdb, 0x74, 0x62, 0xed,
+	0x01, 0x04, 0x29, 0x99, 0x90, 0x88, 0x53, 0x3f, 0x3b, 0x1f, 0x0c, 0x0a, 0xee, 0x9c, 0xbc, 0x4b,
+	0x68, 0x96, 0x91, 0x1d, 0x2f, 0x1b, 0x66, 0xb5, 0xbd, 0xb2, 0x6d, 0xf7, 0x0c, 0x46, 0x65, 0x86,
+	0xee, 0x73, 0xb4, 0xe8, 0x9a, 0xbb, 0x87, 0x31, 0xc5, 0x04, 0xba, 0x9f, 0xc0, 0xb6, 0x47, 0x6e,
+	0xe2, 0xeb, 0x1a, 0x1b, 0x97, 0xf6, 0xe3, 0x3e, 0x01, 0xbb, 0x0a, 0x5d, 0x10, 0xcc, 0xff, 0x6a,
+	0x01, 0x68, 0x78, 0xc5, 0x44, 0x4d, 0x11, 0xd5, 0x74, 0x84, 0xff, 0xef, 0xd1, 0x64, 0x5a, 0x7b,
+	0xb9, 0x60, 0x6d, 0xec, 0x3c, 0xd1, 0xe7, 0xe3, 0x90, 0x4c, 0xb9, 0xb8, 0xa3, 0x77, 0x45, 0x77,
+	0xc1, 0x5e, 0x92, 0x69, 0x76, 0x5a, 0xcb, 0x6b, 0xb9, 0x0c, 0x8e, 0x89, 0x11, 0x1c, 0xfd, 0x42,
+	0x70, 0xfc, 0x02, 0xec, 0x17, 0xd1, 0x8d, 0x1f, 0xd2, 0x89, 0xcf, 0xc9, 0xf9, 0xec, 0x32, 0x22,
+	0x79, 0xde, 0xd6, 0x1e, 0xa1, 0xea, 0x24, 0x68, 0xeb, 0x93, 0xe0, 0x67, 0x70, 0xa7, 0x46, 0x82,
+	0xb2, 0xf1, 0x3e, 0x0c, 0x68, 0xce, 0xcc, 0x8e, 0x04, 0x93, 0xe4, 0xee, 0x40, 0x57, 0xdc, 0xa6,
+	0x50, 0x5b, 0x40, 0x27, 0xb9, 0x36, 0xfc, 0x77, 0xbf, 0x83, 0x2d, 0x19, 0xba, 0xc7, 0xc2, 0xc6,
+	0x8b, 0x32, 0x29, 0xaf, 0xa1, 0x6d, 0xa3, 0x86, 0x22, 0x35, 0x7e, 0x1b, 0x91, 0x54, 0x5d, 0x0e,
+	0xe5, 0x00, 0xbb, 0xbe, 0xa2, 0x68, 0x1d, 0x15, 0x41, 0x1c, 0x4d, 0xe9, 0x55, 0x2e, 0x5b, 0x8c,
+	0xdc, 0xc7, 0xb0, 0xf1, 0x9c, 0xf0, 0x0f, 0x5a, 0x07, 0xde, 0x44, 0x36, 0x0d, 0xb0, 0x92, 0x7c,
+	0x58, 0x88, 0xb7, 0xf5, 0x27, 0x23, 0xa3, 0x81, 0x16, 0xc8, 0x73, 0xc1, 0xcd, 0xe2, 0x50, 0x5e,
+	0x40, 0x85, 0xbb, 0xc7, 0x68, 0x2a, 0xb1, 0xa9, 0x8e, 0x37, 0x50, 0xb4, 0x13, 0x9f, 0x13, 0xeb,
+	0x63, 0xb8, 0x95, 0x92, 0x1b, 0x75, 0x41, 0x97, 0x28, 0x99, 0x96, 0xeb, 0x9a, 0x2c, 0x80, 0x7a,
+	0x57, 0x4b, 0xe6, 0xae, 0xb4, 0x6d, 0xba, 0xa6, 0x6d, 0x3e, 0x83, 0x2d, 0x99, 0x35, 0x1f, 0xb6,
+	0xdd, 0x43, 0x18, 0x16, 0xe1, 0x0b, 0x12, 0xec, 0x02, 0x06, 0x12, 0xf9, 0x0d, 0x6a, 0x9b, 0xe7,
+	0x4d, 0xb9, 0xb6, 0xb6, 0xb1, 0x36, 0x33, 0x5b, 0x3a, 0xc5, 0xda, 0x3b, 0x04, 0x4b, 0xbc, 0x36,
+	0x88, 0xd9, 0x79, 0xf9, 0x39, 0x82, 0xad, 0x02, 0x55, 0xad, 0xed, 0x31, 0xde, 0x53, 0x04, 0x49,
+	0xd5, 0x9d, 0x8d, 0xb2, 0x37, 0xbc, 0x0c, 0xe0, 0xfe, 0xb5, 0x05, 0xcb, 0x92, 0xf6, 0x83, 0xfb,
+	0xb0, 0xa6, 0x9f, 0x79, 0xfc, 0x25, 0xac, 0x9a, 0xba, 0xad, 0x01, 0xac, 0x9c, 0xfe, 0xee, 0xec,
+	0x85, 0x77, 0x7a, 0xb2, 0xf1, 0x23, 0xab, 0x0f, 0xdd, 0x57, 0x47, 0x2f, 0x5f, 0x9c, 0x6c, 0xb4,
+	0x90, 0xee, 0x9d, 0xbe, 0xfa, 0xe6, 0x37, 0xa7, 0x27, 0x1b, 0xed, 0x27, 0xff, 0xbe, 0x05, 0xf0,
+	0xea, 0xec, 0xb7, 0xea, 0x04, 0xb5, 0x5e, 0xc9, 0x36, 0xd1, 0x78, 0xa9, 0xb1, 0xf6, 0x8d, 0xdb,
+	0x64, 0xed, 0x23, 0x8e, 0x73, 0x30, 0x07, 0xa1, 0xcc, 0xac, 0xe4, 0x1a, 0xaf, 0x5c, 0x65, 0xb9,
+	0xd5, 0x57, 0x39, 0xe7, 0x60, 0x0e, 0x42, 0xc9, 0x7d, 0x0e, 0xa0, 0x5f, 0x00, 0xad, 0x1d, 0x3d,
+	0xa1, 0xf2, 0xbc, 0xe8, 0xec, 0xd6, 0x33, 0x95, 0xa0, 0xaf, 0x61, 0xd5, 0x7c, 0xfc, 0xb1, 0xee,
+	0x6a, 0x74, 0xcd, 0xfb, 0x94, 0xb3, 0xd7, 0xc4, 0xd6, 0xe2, 0xcc, 0xaa, 0x62, 0x8a, 0xab, 0x29,
+	0x64, 0xce, 0x5e, 0x13, 0x5b, 0x89, 0x3b, 0x81, 0x7e, 0x5e, 0x47, 0x2c, 0x47, 0x83, 0xcb, 0x95,
+	0xc8, 0xd9, 0xa9, 0xe5, 0xe9, 0x45, 0x99, 0xf9, 0x69, 0x2e, 0xaa, 0x26, 0xcd, 0x9d, 0xbd, 0x26,
+	0xb6, 0x12, 0xf7, 0x6b, 0x18, 0x18, 0x19, 0x65, 0xed, 0x96, 0xa2, 0xa0, 0x90, 0x7e, 0xce, 0xdd,
+	0x06, 0xae, 0x92, 0x75, 0x06, 0x6b, 0x85, 0x47, 0x13, 0xcb, 0x50, 0x5e, 0xf7, 0x1a, 0xe3, 0xdc,
+	0x6b, 0xe4, 0xeb, 0x88, 0x2b, 0xbd, 0x91, 0x98, 0x11, 0x57, 0xff, 0xe0, 0xe2, 0x1c, 0xcc, 0x41,
+	0x28, 0xb9, 0x47, 0xd0, 0xcb, 0x5e, 0x4d, 0xac, 0x3b, 0xc5, 0x4d, 0x19, 0x8f, 0x2b, 0x8e, 0x53,
+	0xc7, 0xd2, 0x7e, 0x30, 0xdf, 0x48, 0x4c, 0x3f, 0xd4, 0x3c, 0xa9, 0x38, 0x7b, 0x4d, 0x6c, 0xbd,
+	0xd3, 0xd2, 0x2b, 0x80, 0xb9, 0xd3, 0xfa, 0x87, 0x09, 0xe7, 0x60, 0x0e, 0x42, 0xcb, 0x2d, 0x35,
+	0xf6, 0xa6, 0xdc, 0xfa, 0x17, 0x02, 0xe7, 0x60, 0x0e, 0x42, 0xc9, 0x3d, 0x87, 0xf5, 0x62, 0x93,
+	0x6f, 0xdd, 0x2b, 0x1a, 0xab, 0xf2, 0x2e, 0xe0, 0xec, 0x37, 0x03, 0xb4, 0x4d, 0xcd, 0xee, 0xd8,
+	0xba, 0x5b, 0x99, 0x61, 0x36, 0xd3, 0xce, 0x5e, 0x13, 0xdb, 0xc8, 0x5f, 0xa3, 0xad, 0x2d, 0xe4,
+	0x6f, 0xb5, 0x7b, 0x76, 0xf6, 0x9a, 0xd8, 0x4a, 0x1c, 0x81, 0x61, 0x5d, 0xfb, 0x66, 0x3d, 0x28,
+	0x7b, 0xa1, 0xb6, 0x4d, 0x73, 0x1e, 0x2e, 0x82, 0x69, 0x35, 0x75, 0x6d, 0x9b, 0xa9, 0x66, 0x4e,
+	0x37, 0xe8, 0x3c, 0x5c, 0x04, 0x53, 0x6a, 0x2e, 0xe5, 0x51, 0x5a, 0xe4, 0x32, 0xeb, 0xa3, 0xa2,
+	0x4d, 0xeb, 0x9b, 0x40, 0xe7, 0xc1, 0x02, 0x94, 0xd2, 0xf1, 0x1d, 0x6c, 0x94, 0x9b, 0x15, 0xeb,
+	0xa0, 0x5c, 0x25, 0x2b, 0xf7, 0x7e, 0xc7, 0x9d, 0x07, 0x29, 0xc6, 0x9f, 0x21, 0xb8, 0x14, 0x7f,
+	0x55, 0xb1, 0xfb, 0xcd, 0x00, 0xbd, 0xde, 0x72, 0x83, 0x61, 0xae, 0xb7, 0xa1, 0x4f, 0x71, 0xdc,
+	0x79, 0x10, 0x25, 0xfa, 0xf7, 0xb0, 0x59, 0xb9, 0x58, 0x5b, 0xc6, 0xc4, 0xa6, 0x7b, 0xbb, 0x73,
+	0x7f, 0x2e, 0x46, 0x4a, 0xbf, 0x5c, 0x16, 0x98, 0x2f, 0xff, 0x33, 0x00, 0x16, 0x0a, 0x93, 0x2f,
+	0xb0, 0x1b, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -1060,6 +3120,22 @@ type VPNServiceClient interface {
 	GetClient(ctx context.Context, in *GetClientRequest, opts ...grpc.CallOption) (*GetClientResponse, error)
 	RevokeClient(ctx context.Context, in *RevokeClientRequest, opts ...grpc.CallOption) (*RevokeClientResponse, error)
 	ListClients(ctx context.Context, in *ListClientsRequest, opts ...grpc.CallOption) (*ListClientsResponse, error)
+	PinAllocation(ctx context.Context, in *PinAllocationRequest, opts ...grpc.CallOption) (*PinAllocationResponse, error)
+	UnpinAllocation(ctx context.Context, in *UnpinAllocationRequest, opts ...grpc.CallOption) (*UnpinAllocationResponse, error)
+	ListPins(ctx context.Context, in *ListPinsRequest, opts ...grpc.CallOption) (*ListPinsResponse, error)
+	FirewallDiff(ctx context.Context, in *FirewallDiffRequest, opts ...grpc.CallOption) (*FirewallDiffResponse, error)
+	EnrollLocalUser(ctx context.Context, in *EnrollLocalUserRequest, opts ...grpc.CallOption) (*EnrollLocalUserResponse, error)
+	DeleteLocalUser(ctx context.Context, in *DeleteLocalUserRequest, opts ...grpc.CallOption) (*DeleteLocalUserResponse, error)
+	ListLocalUsers(ctx context.Context, in *ListLocalUsersRequest, opts ...grpc.CallOption) (*ListLocalUsersResponse, error)
+	ListLockouts(ctx context.Context, in *ListLockoutsRequest, opts ...grpc.CallOption) (*ListLockoutsResponse, error)
+	ClearLockout(ctx context.Context, in *ClearLockoutRequest, opts ...grpc.CallOption) (*ClearLockoutResponse, error)
+	EnrollServiceAccount(ctx context.Context, in *EnrollServiceAccountRequest, opts ...grpc.CallOption) (*EnrollServiceAccountResponse, error)
+	DeleteServiceAccount(ctx context.Context, in *DeleteServiceAccountRequest, opts ...grpc.CallOption) (*DeleteServiceAccountResponse, error)
+	ListServiceAccounts(ctx context.Context, in *ListServiceAccountsRequest, opts ...grpc.CallOption) (*ListServiceAccountsResponse, error)
+	CreateBreakGlass(ctx context.Context, in *CreateBreakGlassRequest, opts ...grpc.CallOption) (*CreateBreakGlassResponse, error)
+	ListBreakGlass(ctx context.Context, in *ListBreakGlassRequest, opts ...grpc.CallOption) (*ListBreakGlassResponse, error)
+	RevokeBreakGlass(ctx context.Context, in *RevokeBreakGlassRequest, opts ...grpc.CallOption) (*RevokeBreakGlassResponse, error)
+	InvalidateSubnets(ctx context.Context, in *InvalidateSubnetsRequest, opts ...grpc.CallOption) (*InvalidateSubnetsResponse, error)
 }
 
 type vPNServiceClient struct {
@@ -1142,6 +3218,150 @@ func (c *vPNServiceClient) ListClients(ctx context.Context, in *ListClientsReque
 	return out, nil
 }
 
+func (c *vPNServiceClient) PinAllocation(ctx context.Context, in *PinAllocationRequest, opts ...grpc.CallOption) (*PinAllocationResponse, error) {
+	out := new(PinAllocationResponse)
+	err := c.cc.Invoke(ctx, "/protobuf.VPNService/PinAllocation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vPNServiceClient) UnpinAllocation(ctx context.Context, in *UnpinAllocationRequest, opts ...grpc.CallOption) (*UnpinAllocationResponse, error) {
+	out := new(UnpinAllocationResponse)
+	err := c.cc.Invoke(ctx, "/protobuf.VPNService/UnpinAllocation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vPNServiceClient) ListPins(ctx context.Context, in *ListPinsRequest, opts ...grpc.CallOption) (*ListPinsResponse, error) {
+	out := new(ListPinsResponse)
+	err := c.cc.Invoke(ctx, "/protobuf.VPNService/ListPins", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vPNServiceClient) FirewallDiff(ctx context.Context, in *FirewallDiffRequest, opts ...grpc.CallOption) (*FirewallDiffResponse, error) {
+	out := new(FirewallDiffResponse)
+	err := c.cc.Invoke(ctx, "/protobuf.VPNService/FirewallDiff", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vPNServiceClient) EnrollLocalUser(ctx context.Context, in *EnrollLocalUserRequest, opts ...grpc.CallOption) (*EnrollLocalUserResponse, error) {
+	out := new(EnrollLocalUserResponse)
+	err := c.cc.Invoke(ctx, "/protobuf.VPNService/EnrollLocalUser", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vPNServiceClient) DeleteLocalUser(ctx context.Context, in *DeleteLocalUserRequest, opts ...grpc.CallOption) (*DeleteLocalUserResponse, error) {
+	out := new(DeleteLocalUserResponse)
+	err := c.cc.Invoke(ctx, "/protobuf.VPNService/DeleteLocalUser", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vPNServiceClient) ListLocalUsers(ctx context.Context, in *ListLocalUsersRequest, opts ...grpc.CallOption) (*ListLocalUsersResponse, error) {
+	out := new(ListLocalUsersResponse)
+	err := c.cc.Invoke(ctx, "/protobuf.VPNService/ListLocalUsers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vPNServiceClient) ListLockouts(ctx context.Context, in *ListLockoutsRequest, opts ...grpc.CallOption) (*ListLockoutsResponse, error) {
+	out := new(ListLockoutsResponse)
+	err := c.cc.Invoke(ctx, "/protobuf.VPNService/ListLockouts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vPNServiceClient) ClearLockout(ctx context.Context, in *ClearLockoutRequest, opts ...grpc.CallOption) (*ClearLockoutResponse, error) {
+	out := new(ClearLockoutResponse)
+	err := c.cc.Invoke(ctx, "/protobuf.VPNService/ClearLockout", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vPNServiceClient) EnrollServiceAccount(ctx context.Context, in *EnrollServiceAccountRequest, opts ...grpc.CallOption) (*EnrollServiceAccountResponse, error) {
+	out := new(EnrollServiceAccountResponse)
+	err := c.cc.Invoke(ctx, "/protobuf.VPNService/EnrollServiceAccount", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vPNServiceClient) DeleteServiceAccount(ctx context.Context, in *DeleteServiceAccountRequest, opts ...grpc.CallOption) (*DeleteServiceAccountResponse, error) {
+	out := new(DeleteServiceAccountResponse)
+	err := c.cc.Invoke(ctx, "/protobuf.VPNService/DeleteServiceAccount", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vPNServiceClient) ListServiceAccounts(ctx context.Context, in *ListServiceAccountsRequest, opts ...grpc.CallOption) (*ListServiceAccountsResponse, error) {
+	out := new(ListServiceAccountsResponse)
+	err := c.cc.Invoke(ctx, "/protobuf.VPNService/ListServiceAccounts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vPNServiceClient) CreateBreakGlass(ctx context.Context, in *CreateBreakGlassRequest, opts ...grpc.CallOption) (*CreateBreakGlassResponse, error) {
+	out := new(CreateBreakGlassResponse)
+	err := c.cc.Invoke(ctx, "/protobuf.VPNService/CreateBreakGlass", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vPNServiceClient) ListBreakGlass(ctx context.Context, in *ListBreakGlassRequest, opts ...grpc.CallOption) (*ListBreakGlassResponse, error) {
+	out := new(ListBreakGlassResponse)
+	err := c.cc.Invoke(ctx, "/protobuf.VPNService/ListBreakGlass", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vPNServiceClient) RevokeBreakGlass(ctx context.Context, in *RevokeBreakGlassRequest, opts ...grpc.CallOption) (*RevokeBreakGlassResponse, error) {
+	out := new(RevokeBreakGlassResponse)
+	err := c.cc.Invoke(ctx, "/protobuf.VPNService/RevokeBreakGlass", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vPNServiceClient) InvalidateSubnets(ctx context.Context, in *InvalidateSubnetsRequest, opts ...grpc.CallOption) (*InvalidateSubnetsResponse, error) {
+	out := new(InvalidateSubnetsResponse)
+	err := c.cc.Invoke(ctx, "/protobuf.VPNService/InvalidateSubnets", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // VPNServiceServer is the server API for VPNService service.
 type VPNServiceServer interface {
 	ListConnections(context.Context, *ListConnectionsRequest) (*ListConnectionsResponse, error)
@@ -1152,6 +3372,22 @@ type VPNServiceServer interface {
 	GetClient(context.Context, *GetClientRequest) (*GetClientResponse, error)
 	RevokeClient(context.Context, *RevokeClientRequest) (*RevokeClientResponse, error)
 	ListClients(context.Context, *ListClientsRequest) (*ListClientsResponse, error)
+	PinAllocation(context.Context, *PinAllocationRequest) (*PinAllocationResponse, error)
+	UnpinAllocation(context.Context, *UnpinAllocationRequest) (*UnpinAllocationResponse, error)
+	ListPins(context.Context, *ListPinsRequest) (*ListPinsResponse, error)
+	FirewallDiff(context.Context, *FirewallDiffRequest) (*FirewallDiffResponse, error)
+	EnrollLocalUser(context.Context, *EnrollLocalUserRequest) (*EnrollLocalUserResponse, error)
+	DeleteLocalUser(context.Context, *DeleteLocalUserRequest) (*DeleteLocalUserResponse, error)
+	ListLocalUsers(context.Context, *ListLocalUsersRequest) (*ListLocalUsersResponse, error)
+	ListLockouts(context.Context, *ListLockoutsRequest) (*ListLockoutsResponse, error)
+	ClearLockout(context.Context, *ClearLockoutRequest) (*ClearLockoutResponse, error)
+	EnrollServiceAccount(context.Context, *EnrollServiceAccountRequest) (*EnrollServiceAccountResponse, error)
+	DeleteServiceAccount(context.Context, *DeleteServiceAccountRequest) (*DeleteServiceAccountResponse, error)
+	ListServiceAccounts(context.Context, *ListServiceAccountsRequest) (*ListServiceAccountsResponse, error)
+	CreateBreakGlass(context.Context, *CreateBreakGlassRequest) (*CreateBreakGlassResponse, error)
+	ListBreakGlass(context.Context, *ListBreakGlassRequest) (*ListBreakGlassResponse, error)
+	RevokeBreakGlass(context.Context, *RevokeBreakGlassRequest) (*RevokeBreakGlassResponse, error)
+	InvalidateSubnets(context.Context, *InvalidateSubnetsRequest) (*InvalidateSubnetsResponse, error)
 }
 
 // UnimplementedVPNServiceServer can be embedded to have forward compatible implementations.
@@ -1182,6 +3418,54 @@ func (*UnimplementedVPNServiceServer) RevokeClient(ctx context.Context, req *Rev
 func (*UnimplementedVPNServiceServer) ListClients(ctx context.Context, req *ListClientsRequest) (*ListClientsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListClients not implemented")
 }
+func (*UnimplementedVPNServiceServer) PinAllocation(ctx context.Context, req *PinAllocationRequest) (*PinAllocationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PinAllocation not implemented")
+}
+func (*UnimplementedVPNServiceServer) UnpinAllocation(ctx context.Context, req *UnpinAllocationRequest) (*UnpinAllocationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnpinAllocation not implemented")
+}
+func (*UnimplementedVPNServiceServer) ListPins(ctx context.Context, req *ListPinsRequest) (*ListPinsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPins not implemented")
+}
+func (*UnimplementedVPNServiceServer) FirewallDiff(ctx context.Context, req *FirewallDiffRequest) (*FirewallDiffResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FirewallDiff not implemented")
+}
+func (*UnimplementedVPNServiceServer) EnrollLocalUser(ctx context.Context, req *EnrollLocalUserRequest) (*EnrollLocalUserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnrollLocalUser not implemented")
+}
+func (*UnimplementedVPNServiceServer) DeleteLocalUser(ctx context.Context, req *DeleteLocalUserRequest) (*DeleteLocalUserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteLocalUser not implemented")
+}
+func (*UnimplementedVPNServiceServer) ListLocalUsers(ctx context.Context, req *ListLocalUsersRequest) (*ListLocalUsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLocalUsers not implemented")
+}
+func (*UnimplementedVPNServiceServer) ListLockouts(ctx context.Context, req *ListLockoutsRequest) (*ListLockoutsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLockouts not implemented")
+}
+func (*UnimplementedVPNServiceServer) ClearLockout(ctx context.Context, req *ClearLockoutRequest) (*ClearLockoutResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClearLockout not implemented")
+}
+func (*UnimplementedVPNServiceServer) EnrollServiceAccount(ctx context.Context, req *EnrollServiceAccountRequest) (*EnrollServiceAccountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnrollServiceAccount not implemented")
+}
+func (*UnimplementedVPNServiceServer) DeleteServiceAccount(ctx context.Context, req *DeleteServiceAccountRequest) (*DeleteServiceAccountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteServiceAccount not implemented")
+}
+func (*UnimplementedVPNServiceServer) ListServiceAccounts(ctx context.Context, req *ListServiceAccountsRequest) (*ListServiceAccountsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListServiceAccounts not implemented")
+}
+func (*UnimplementedVPNServiceServer) CreateBreakGlass(ctx context.Context, req *CreateBreakGlassRequest) (*CreateBreakGlassResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateBreakGlass not implemented")
+}
+func (*UnimplementedVPNServiceServer) ListBreakGlass(ctx context.Context, req *ListBreakGlassRequest) (*ListBreakGlassResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBreakGlass not implemented")
+}
+func (*UnimplementedVPNServiceServer) RevokeBreakGlass(ctx context.Context, req *RevokeBreakGlassRequest) (*RevokeBreakGlassResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeBreakGlass not implemented")
+}
+func (*UnimplementedVPNServiceServer) InvalidateSubnets(ctx context.Context, req *InvalidateSubnetsRequest) (*InvalidateSubnetsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InvalidateSubnets not implemented")
+}
 
 func RegisterVPNServiceServer(s *grpc.Server, srv VPNServiceServer) {
 	s.RegisterService(&_VPNService_serviceDesc, srv)
@@ -1331,6 +3615,294 @@ func _VPNService_ListClients_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _VPNService_PinAllocation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PinAllocationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VPNServiceServer).PinAllocation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.VPNService/PinAllocation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VPNServiceServer).PinAllocation(ctx, req.(*PinAllocationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VPNService_UnpinAllocation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnpinAllocationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VPNServiceServer).UnpinAllocation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.VPNService/UnpinAllocation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VPNServiceServer).UnpinAllocation(ctx, req.(*UnpinAllocationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VPNService_ListPins_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPinsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VPNServiceServer).ListPins(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.VPNService/ListPins",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VPNServiceServer).ListPins(ctx, req.(*ListPinsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VPNService_FirewallDiff_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FirewallDiffRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VPNServiceServer).FirewallDiff(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.VPNService/FirewallDiff",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VPNServiceServer).FirewallDiff(ctx, req.(*FirewallDiffRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VPNService_EnrollLocalUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnrollLocalUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VPNServiceServer).EnrollLocalUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.VPNService/EnrollLocalUser",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VPNServiceServer).EnrollLocalUser(ctx, req.(*EnrollLocalUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VPNService_DeleteLocalUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteLocalUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VPNServiceServer).DeleteLocalUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.VPNService/DeleteLocalUser",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VPNServiceServer).DeleteLocalUser(ctx, req.(*DeleteLocalUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VPNService_ListLocalUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLocalUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VPNServiceServer).ListLocalUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.VPNService/ListLocalUsers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VPNServiceServer).ListLocalUsers(ctx, req.(*ListLocalUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VPNService_ListLockouts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLockoutsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VPNServiceServer).ListLockouts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.VPNService/ListLockouts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VPNServiceServer).ListLockouts(ctx, req.(*ListLockoutsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VPNService_ClearLockout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClearLockoutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VPNServiceServer).ClearLockout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.VPNService/ClearLockout",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VPNServiceServer).ClearLockout(ctx, req.(*ClearLockoutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VPNService_EnrollServiceAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnrollServiceAccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VPNServiceServer).EnrollServiceAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.VPNService/EnrollServiceAccount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VPNServiceServer).EnrollServiceAccount(ctx, req.(*EnrollServiceAccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VPNService_DeleteServiceAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteServiceAccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VPNServiceServer).DeleteServiceAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.VPNService/DeleteServiceAccount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VPNServiceServer).DeleteServiceAccount(ctx, req.(*DeleteServiceAccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VPNService_ListServiceAccounts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListServiceAccountsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VPNServiceServer).ListServiceAccounts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.VPNService/ListServiceAccounts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VPNServiceServer).ListServiceAccounts(ctx, req.(*ListServiceAccountsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VPNService_CreateBreakGlass_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateBreakGlassRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VPNServiceServer).CreateBreakGlass(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.VPNService/CreateBreakGlass",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VPNServiceServer).CreateBreakGlass(ctx, req.(*CreateBreakGlassRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VPNService_ListBreakGlass_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBreakGlassRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VPNServiceServer).ListBreakGlass(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.VPNService/ListBreakGlass",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VPNServiceServer).ListBreakGlass(ctx, req.(*ListBreakGlassRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VPNService_RevokeBreakGlass_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeBreakGlassRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VPNServiceServer).RevokeBreakGlass(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.VPNService/RevokeBreakGlass",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VPNServiceServer).RevokeBreakGlass(ctx, req.(*RevokeBreakGlassRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VPNService_InvalidateSubnets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InvalidateSubnetsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VPNServiceServer).InvalidateSubnets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.VPNService/InvalidateSubnets",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VPNServiceServer).InvalidateSubnets(ctx, req.(*InvalidateSubnetsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _VPNService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "protobuf.VPNService",
 	HandlerType: (*VPNServiceServer)(nil),
@@ -1367,6 +3939,70 @@ var _VPNService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "ListClients",
 			Handler:    _VPNService_ListClients_Handler,
 		},
+		{
+			MethodName: "PinAllocation",
+			Handler:    _VPNService_PinAllocation_Handler,
+		},
+		{
+			MethodName: "UnpinAllocation",
+			Handler:    _VPNService_UnpinAllocation_Handler,
+		},
+		{
+			MethodName: "ListPins",
+			Handler:    _VPNService_ListPins_Handler,
+		},
+		{
+			MethodName: "FirewallDiff",
+			Handler:    _VPNService_FirewallDiff_Handler,
+		},
+		{
+			MethodName: "EnrollLocalUser",
+			Handler:    _VPNService_EnrollLocalUser_Handler,
+		},
+		{
+			MethodName: "DeleteLocalUser",
+			Handler:    _VPNService_DeleteLocalUser_Handler,
+		},
+		{
+			MethodName: "ListLocalUsers",
+			Handler:    _VPNService_ListLocalUsers_Handler,
+		},
+		{
+			MethodName: "ListLockouts",
+			Handler:    _VPNService_ListLockouts_Handler,
+		},
+		{
+			MethodName: "ClearLockout",
+			Handler:    _VPNService_ClearLockout_Handler,
+		},
+		{
+			MethodName: "EnrollServiceAccount",
+			Handler:    _VPNService_EnrollServiceAccount_Handler,
+		},
+		{
+			MethodName: "DeleteServiceAccount",
+			Handler:    _VPNService_DeleteServiceAccount_Handler,
+		},
+		{
+			MethodName: "ListServiceAccounts",
+			Handler:    _VPNService_ListServiceAccounts_Handler,
+		},
+		{
+			MethodName: "CreateBreakGlass",
+			Handler:    _VPNService_CreateBreakGlass_Handler,
+		},
+		{
+			MethodName: "ListBreakGlass",
+			Handler:    _VPNService_ListBreakGlass_Handler,
+		},
+		{
+			MethodName: "RevokeBreakGlass",
+			Handler:    _VPNService_RevokeBreakGlass_Handler,
+		},
+		{
+			MethodName: "InvalidateSubnets",
+			Handler:    _VPNService_InvalidateSubnets_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "vpn_service.proto",
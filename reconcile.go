@@ -0,0 +1,206 @@
+package doorman
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/equinix/doorman/metrics"
+	pb "github.com/equinix/doorman/protobuf"
+	"github.com/pkg/errors"
+)
+
+const defaultReconcileInterval = time.Minute
+
+// Kinds of differences between the firewall and the connected clients.
+const (
+	driftChains   = "chains"   // doorman's chains are gone, e.g. after an iptables -F
+	driftMissing  = "missing"  // a connected client has no sets or rules
+	driftOrphaned = "orphaned" // sets or rules of a client that is not connected
+	driftRules    = "rules"    // some of a connected client's rules are gone
+	driftRoutes   = "routes"   // a connected client's sets do not match its routes
+)
+
+var driftKinds = []string{driftChains, driftMissing, driftOrphaned, driftRules, driftRoutes}
+
+// firewallDrift compares what the firewall has set up with the connected clients.
+// The caller needs to hold firewallMu, so no client is half set up.
+func (s *VPNServer) firewallDrift() ([]*pb.FirewallDrift, error) {
+	state, err := s.firewall.State()
+	if err != nil {
+		return nil, errors.WithMessage(err, "read firewall state")
+	}
+	if !state.ready {
+		return []*pb.FirewallDrift{{Kind: driftChains}}, nil
+	}
+
+	s.mu.RLock()
+	connections := map[string]*pb.Connection{} // ip -> connection
+	for _, connection := range s.connections {
+		connections[connection.Allocation.Ip] = connection
+	}
+	s.mu.RUnlock()
+
+	drifts := []*pb.FirewallDrift{}
+	for ip, connection := range connections {
+		drift := &pb.FirewallDrift{Ip: ip, Ipv6: connection.Allocation.Ipv6, Client: connection.Client}
+		want := connectionRoutes(connection)
+
+		client, ok := state.clients[ip]
+		switch {
+		case !ok:
+			drift.Kind = driftMissing
+			drift.MissingRoutes = want
+		case !client.enabled || client.ipv6 != connection.Allocation.Ipv6:
+			drift.Kind = driftRules
+		default:
+			drift.MissingRoutes = difference(want, client.routes)
+			drift.ExtraRoutes = difference(client.routes, want)
+			if len(drift.MissingRoutes) == 0 && len(drift.ExtraRoutes) == 0 {
+				continue
+			}
+			drift.Kind = driftRoutes
+		}
+		drifts = append(drifts, drift)
+	}
+
+	for ip, client := range state.clients {
+		if _, ok := connections[ip]; ok {
+			continue
+		}
+		ipv6 := client.ipv6
+		if ipv6 == "" {
+			ipv6 = s.ipv6For(ip)
+		}
+		drifts = append(drifts, &pb.FirewallDrift{Kind: driftOrphaned, Ip: ip, Ipv6: ipv6, ExtraRoutes: client.routes})
+	}
+
+	sort.Slice(drifts, func(i, j int) bool {
+		return bytes.Compare(net.ParseIP(drifts[i].Ip), net.ParseIP(drifts[j].Ip)) < 0
+	})
+	return drifts, nil
+}
+
+// connectionRoutes returns the connection's routes the firewall is expected to have, normalized.
+func connectionRoutes(connection *pb.Connection) []string {
+	var ranges []addrRange
+	for _, route := range connection.Routes {
+		_, network, err := net.ParseCIDR(route.Cidr)
+		if err != nil {
+			continue
+		}
+		if network.IP.To4() == nil && connection.Allocation.Ipv6 == "" {
+			continue
+		}
+		ranges = append(ranges, networkRange(network))
+	}
+	return normalizeRoutes(ranges)
+}
+
+// difference returns the elements of a that are not in b.
+func difference(a, b []string) []string {
+	in := map[string]bool{}
+	for _, s := range b {
+		in[s] = true
+	}
+	var diff []string
+	for _, s := range a {
+		if !in[s] {
+			diff = append(diff, s)
+		}
+	}
+	return diff
+}
+
+// reconcileFirewall repairs the differences between the firewall and the connected clients.
+func (s *VPNServer) reconcileFirewall() {
+	s.firewallMu.Lock()
+	defer s.firewallMu.Unlock()
+
+	drifts, err := s.firewallDrift()
+	if err != nil {
+		logger.Error(err)
+		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+		return
+	}
+
+	counts := map[string]int{}
+	for _, drift := range drifts {
+		counts[drift.Kind]++
+
+		log := logger.With("kind", drift.Kind, "ip", drift.Ip, "client", drift.Client)
+		if len(drift.MissingRoutes) > 0 {
+			log = log.With("missing_routes", drift.MissingRoutes)
+		}
+		if len(drift.ExtraRoutes) > 0 {
+			log = log.With("extra_routes", drift.ExtraRoutes)
+		}
+		log.Info("firewall drifted")
+
+		if err := s.repairFirewall(drift); err != nil {
+			log.Error(errors.WithMessage(err, "repair firewall"))
+			metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+			continue
+		}
+		metrics.FirewallRepairTotal.WithLabelValues(drift.Kind).Inc()
+		log.Info("repaired firewall")
+	}
+	for _, kind := range driftKinds {
+		metrics.FirewallDrift.WithLabelValues(kind).Set(float64(counts[kind]))
+	}
+}
+
+// repairFirewall removes orphans, and sets up clients whose sets or rules drifted from scratch.
+func (s *VPNServer) repairFirewall(drift *pb.FirewallDrift) error {
+	if drift.Kind == driftOrphaned {
+		return s.firewall.Remove(&pb.Allocation{Ip: drift.Ip, Ipv6: drift.Ipv6})
+	}
+
+	s.mu.RLock()
+	var connections []*pb.Connection
+	for _, connection := range s.connections {
+		if drift.Kind == driftChains || connection.Allocation.Ip == drift.Ip {
+			connections = append(connections, connection)
+		}
+	}
+	s.mu.RUnlock()
+
+	if drift.Kind == driftChains {
+		if err := s.firewall.Init(); err != nil {
+			return err
+		}
+	}
+
+	var firstErr error
+	for _, connection := range connections {
+		err := s.firewall.Remove(connection.Allocation)
+		if err == nil {
+			err = s.restoreFirewall(connection)
+		}
+		if err != nil {
+			// leave nothing half set up, the next round will try again
+			s.firewall.Remove(connection.Allocation)
+			if firstErr == nil {
+				firstErr = errors.WithMessagef(err, "restore firewall of %s", connection.Client)
+			}
+		}
+	}
+	return firstErr
+}
+
+// reconcileFirewallLoop checks the firewall for drift every interval until ctx is done.
+func (s *VPNServer) reconcileFirewallLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.reconcileFirewall()
+		}
+	}
+}
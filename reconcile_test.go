@@ -0,0 +1,101 @@
+package doorman
+
+import (
+	"reflect"
+	"testing"
+
+	pb "github.com/equinix/doorman/protobuf"
+	"github.com/packethost/pkg/log"
+)
+
+func TestReconcileFirewall(t *testing.T) {
+	logger = log.Test(t, "doorman")
+
+	pool6, err := parseIPv6Pool("fd00:d00a::/64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	firewall := newFakeFirewall()
+	s := &VPNServer{
+		firewall: firewall,
+		pool6:    pool6,
+		connections: map[string]*pb.Connection{
+			"ok": {
+				Client:     "ok",
+				Allocation: &pb.Allocation{Client: "ok", Ip: "192.168.127.2", Ipv6: "fd00:d00a::c0a8:7f02"},
+				Routes:     []*pb.Route{{Cidr: "10.88.111.0/25"}, {Cidr: "10.88.111.128/25"}, {Cidr: "fd00:8a0:1::/56"}},
+			},
+			"missing": {
+				Client:     "missing",
+				Allocation: &pb.Allocation{Client: "missing", Ip: "192.168.127.3"},
+				Routes:     []*pb.Route{{Cidr: "10.88.112.0/25"}},
+			},
+			"disabled": {
+				Client:     "disabled",
+				Allocation: &pb.Allocation{Client: "disabled", Ip: "192.168.127.4"},
+				Routes:     []*pb.Route{{Cidr: "10.88.113.0/25"}},
+			},
+			"routes": {
+				Client:     "routes",
+				Allocation: &pb.Allocation{Client: "routes", Ip: "192.168.127.5"},
+				Routes:     []*pb.Route{{Cidr: "10.88.114.0/25"}, {Cidr: "10.88.115.0/25"}},
+			},
+		},
+	}
+	firewall.sets = map[string][]string{
+		// the same networks, stored differently
+		"192.168.127.2": {"10.88.111.0/24", "fd00:8a0:1::/56"},
+		"192.168.127.4": {"10.88.113.0/25"},
+		"192.168.127.5": {"10.88.114.0/25", "10.88.116.0/25"},
+		"192.168.127.6": {"10.88.117.0/25"},
+	}
+	firewall.ipv6 = map[string]string{"192.168.127.2": "fd00:d00a::c0a8:7f02"}
+	firewall.enabled = map[string]bool{"192.168.127.2": true, "192.168.127.5": true, "192.168.127.6": true}
+
+	s.firewallMu.Lock()
+	drifts, err := s.firewallDrift()
+	s.firewallMu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*pb.FirewallDrift{
+		{Kind: driftMissing, Ip: "192.168.127.3", Client: "missing", MissingRoutes: []string{"10.88.112.0/25"}},
+		{Kind: driftRules, Ip: "192.168.127.4", Client: "disabled"},
+		{Kind: driftRoutes, Ip: "192.168.127.5", Client: "routes", MissingRoutes: []string{"10.88.115.0/25"}, ExtraRoutes: []string{"10.88.116.0/25"}},
+		{Kind: driftOrphaned, Ip: "192.168.127.6", Ipv6: "fd00:d00a::c0a8:7f06", ExtraRoutes: []string{"10.88.117.0/25"}},
+	}
+	if !reflect.DeepEqual(drifts, want) {
+		t.Fatalf("expected:\n%v\ngot:\n%v", want, drifts)
+	}
+
+	s.reconcileFirewall()
+	wantSets := map[string][]string{
+		"192.168.127.2": {"10.88.111.0/24", "fd00:8a0:1::/56"},
+		"192.168.127.3": {"10.88.112.0/25"},
+		"192.168.127.4": {"10.88.113.0/25"},
+		"192.168.127.5": {"10.88.114.0/25", "10.88.115.0/25"},
+	}
+	if !reflect.DeepEqual(firewall.sets, wantSets) {
+		t.Fatalf("expected sets %v, got %v", wantSets, firewall.sets)
+	}
+	for ip := range wantSets {
+		if !firewall.enabled[ip] {
+			t.Fatalf("expected %s to be enabled", ip)
+		}
+	}
+	if drifts, err := s.firewallDrift(); err != nil || len(drifts) != 0 {
+		t.Fatalf("expected no drift after reconciling, got %v, %v", drifts, err)
+	}
+
+	// losing doorman's chains takes every client's rules with them
+	firewall.broken = true
+	firewall.sets = map[string][]string{}
+	firewall.enabled = map[string]bool{}
+	if drifts, err := s.firewallDrift(); err != nil || !reflect.DeepEqual(drifts, []*pb.FirewallDrift{{Kind: driftChains}}) {
+		t.Fatalf("expected chains drift, got %v, %v", drifts, err)
+	}
+	s.reconcileFirewall()
+	if firewall.broken || len(firewall.sets) != 4 || len(firewall.enabled) != 4 {
+		t.Fatalf("expected all clients to be restored, got %v, %v", firewall.sets, firewall.enabled)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"net"
@@ -13,181 +12,463 @@ import (
 	"os"
 	"os/exec"
 	"os/signal"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
-	retryable "github.com/hashicorp/go-retryablehttp"
 	"github.com/equinix/doorman/metrics"
 	pb "github.com/equinix/doorman/protobuf"
-	"github.com/packethost/packngo"
+	"github.com/golang/protobuf/proto"
 	"github.com/packethost/pkg/grpc"
 	"github.com/packethost/pkg/log"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const (
 	// environment variables
-	doormanConsumerToken = "DOORMAN_CONSUMER_TOKEN"
-	doormanApiHost       = "DOORMAN_API_HOST"
-	doormanEnvironment   = "EQUINIX_ENV"
-	doormanFacilityCode  = "FACILITY"
-	doormanMagicIP       = "DOORMAN_MAGIC_IP"
-	promethuesServerPort = "PROMETHUES_SERVER_PORT"
-
-	doormanOpenVPNCCD = "/etc/openvpn/ccd" // client-config-directory
-	doormanEasyRSADir = "/etc/openvpn/easy-rsa"
-	easyrsa           = "/usr/share/easy-rsa/easyrsa"
+	doormanConsumerToken       = "DOORMAN_CONSUMER_TOKEN"
+	doormanApiHost             = "DOORMAN_API_HOST"
+	doormanEnvironment         = "EQUINIX_ENV"
+	doormanFacilityCode        = "FACILITY"
+	doormanMetro               = "DOORMAN_METRO"
+	doormanMagicIP             = "DOORMAN_MAGIC_IP"
+	doormanStateFile           = "DOORMAN_STATE_FILE"
+	doormanVPNPools            = "DOORMAN_VPN_POOLS"
+	doormanStickyIPs           = "DOORMAN_STICKY_IPS"
+	doormanVPNIPv6Pool         = "DOORMAN_VPN_IPV6_POOL"
+	doormanFirewall            = "DOORMAN_FIREWALL"
+	doormanReconcile           = "DOORMAN_RECONCILE_INTERVAL"
+	doormanAuth                = "DOORMAN_AUTH"
+	doormanSessionLifetime     = "DOORMAN_SESSION_TOKEN_LIFETIME"
+	doormanAuthTimeout         = "DOORMAN_AUTH_TIMEOUT"
+	doormanLockoutUser         = "DOORMAN_LOCKOUT_USER_THRESHOLD"
+	doormanLockoutIP           = "DOORMAN_LOCKOUT_IP_THRESHOLD"
+	doormanLockoutDuration     = "DOORMAN_LOCKOUT_DURATION"
+	doormanLockoutMax          = "DOORMAN_LOCKOUT_MAX"
+	doormanServiceAccountToken = "DOORMAN_SERVICE_ACCOUNT_TOKEN"
+	doormanSubnetsFile         = "DOORMAN_SUBNETS_FILE"
+	doormanSubnetCacheTTL      = "DOORMAN_SUBNET_CACHE_TTL"
+	doormanSubnetCacheStale    = "DOORMAN_SUBNET_CACHE_STALE"
+	doormanOpenVPNTmpDirEnv    = "DOORMAN_OPENVPN_TMP_DIR"
+	doormanLDAPURL             = "DOORMAN_LDAP_URL"
+	doormanLDAPStartTLS        = "DOORMAN_LDAP_START_TLS"
+	doormanLDAPCAFile          = "DOORMAN_LDAP_CA_FILE"
+	doormanLDAPBindDN          = "DOORMAN_LDAP_BIND_DN"
+	doormanLDAPBindPassword    = "DOORMAN_LDAP_BIND_PASSWORD"
+	doormanLDAPBaseDN          = "DOORMAN_LDAP_BASE_DN"
+	doormanLDAPUserFilter      = "DOORMAN_LDAP_USER_FILTER"
+	doormanLDAPGroupFilter     = "DOORMAN_LDAP_GROUP_FILTER"
+	doormanLDAPTOTPAttribute   = "DOORMAN_LDAP_TOTP_ATTRIBUTE"
+	doormanLDAPGroups          = "DOORMAN_LDAP_GROUPS"
+	doormanOIDCIssuer          = "DOORMAN_OIDC_ISSUER"
+	doormanOIDCClientID        = "DOORMAN_OIDC_CLIENT_ID"
+	doormanOIDCClientSecret    = "DOORMAN_OIDC_CLIENT_SECRET"
+	doormanOIDCScopes          = "DOORMAN_OIDC_SCOPES"
+	doormanOIDCUserClaim       = "DOORMAN_OIDC_USER_CLAIM"
+	doormanOIDCGroupsClaim     = "DOORMAN_OIDC_GROUPS_CLAIM"
+	doormanOIDCGroups          = "DOORMAN_OIDC_GROUPS"
+	promethuesServerPort       = "PROMETHUES_SERVER_PORT"
+
+	doormanOpenVPNCCD    = "/etc/openvpn/ccd" // client-config-directory
+	doormanOpenVPNTmpDir = "/dev/shm"         // tmp-dir, where openvpn creates auth control files
+	doormanOpenVPNStatus = "/etc/openvpn/status"
+	doormanOpenVPNMgmt   = "/etc/openvpn/management.sock"
+	doormanOpenVPNPools  = "/etc/openvpn/pools.conf"
+	doormanEasyRSADir    = "/etc/openvpn/easy-rsa"
+	doormanStateDB       = "/etc/openvpn/doorman/state.db"
+	easyrsa              = "/usr/share/easy-rsa/easyrsa"
 )
 
-const (
-	commandCreate = "/app/fw-add.sh create %s"
-	commandAdd    = "/app/fw-add.sh add %s %s/%d"
-	commandEnable = "/app/fw-add.sh enable %s %s"
-
-	commandDisable = "/app/fw-del.sh disable %s %s"
-	commandDelete  = "/app/fw-del.sh delete %s"
-)
-
-type AuthToken struct {
-	ID    string `json:"id"`
-	Token string `json:"token"`
-}
-
 type VPNServer struct {
-	magicIP       string
-	facilityCode  string
-	consumerToken string
-	apiHost       string
-	sessions      *url.URL
+	magicIP         string
+	facilityCode    string
+	ccd             string // openvpn's client-config-dir
+	tmpDir          string // openvpn's tmp-dir
+	consumerToken   string
+	store           *stateStore
+	authenticator   Authenticator
+	localUsers      *localAuthenticator // nil unless local users are enabled
+	sessions        *sessionTokens      // nil if session tokens are disabled
+	authTimeout     time.Duration       // how long a client's authentication may take
+	lockouts        *lockouts
+	serviceAccounts *serviceAccounts
+	breakGlass      *breakGlass
+	subnetProvider  SubnetProvider
+	subnetCache     *subnetCache // nil if the subnet cache is disabled
+	firewall        Firewall
+	pools           []*ipPool
+	pool6           *ipPool // nil unless ipv6 is enabled
+	stickyIPs       bool
+	reconcile       time.Duration // how often the firewall is checked for drift, never if 0
+
+	// firewallMu is held for reading while a client's firewall rules and connection change together,
+	// and for writing while the firewall is compared with the connections.
+	firewallMu sync.RWMutex
 
 	mu          sync.RWMutex
-	allocations []pb.Allocation
+	management  *managementClient
+	clientIDs   map[string]string         // client -> openvpn client id of its current session
+	killed      map[string]chan struct{}  // openvpn client id -> closed once the killed session disconnected
+	allocations map[string]*pb.Allocation // ip -> allocation, only allocated addresses are tracked
+	pins        map[string]*pb.Allocation // client -> address pinned to it
+	sticky      map[string]string         // ip -> client it was last allocated to
 	connections map[string]*pb.Connection
 }
 
 // MARK: implement VPNService (vpn_service.pb.go)
+// ListAllocations lists the addresses of all pools, or only the allocated ones, a page at a time. Pages are ordered by
+// pool and address, or only by address for allocated ones, and continue after the address in the page token.
 func (s *VPNServer) ListAllocations(ctx context.Context, in *pb.ListAllocationsRequest) (*pb.ListAllocationsResponse, error) {
-	logger.Info("got list allocations request")
+	logger.With("page_token", in.PageToken, "page_size", in.PageSize).Info("got list allocations request")
+
+	size := int(in.PageSize)
+	if size <= 0 {
+		size = defaultAllocationsPageSize
+	}
+	if size > maxAllocationsPageSize {
+		size = maxAllocationsPageSize
+	}
+	var after net.IP
+	if in.PageToken != "" {
+		if after = net.ParseIP(in.PageToken); after == nil {
+			return nil, errors.Errorf("invalid page token %q", in.PageToken)
+		}
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	allocations := make([]*pb.Allocation, 0, len(s.allocations))
-	for _, allocation := range s.allocations {
-		if !in.OnlyAllocated || allocation.Client != "" {
-			allocations = append(allocations, &pb.Allocation{Client: allocation.Client, Ip: allocation.Ip})
+	pinned := s.pinnedIPs()
+
+	var allocations []*pb.Allocation
+	more := false
+	if in.OnlyAllocated {
+		for _, allocation := range s.allocations {
+			if after != nil && bytes.Compare(net.ParseIP(allocation.Ip), after) <= 0 {
+				continue
+			}
+			allocations = append(allocations, &pb.Allocation{
+				Client: allocation.Client,
+				Ip:     allocation.Ip,
+				Pool:   allocation.Pool,
+				Pinned: pinned[allocation.Ip],
+			})
+		}
+		sort.Slice(allocations, func(i, j int) bool {
+			return bytes.Compare(net.ParseIP(allocations[i].Ip), net.ParseIP(allocations[j].Ip)) < 0
+		})
+		if len(allocations) > size {
+			allocations, more = allocations[:size], true
+		}
+	} else {
+		pools := s.pools
+		if after != nil {
+			pools = nil
+			for i, pool := range s.pools {
+				if pool.network.Contains(after) {
+					pools = s.pools[i:]
+					break
+				}
+			}
+			if pools == nil {
+				return nil, errors.Errorf("invalid page token %q", in.PageToken)
+			}
+		}
+		for _, pool := range pools {
+			pool.each(func(ip net.IP) bool {
+				if after != nil && pool.network.Contains(after) && bytes.Compare(ip.To16(), after.To16()) <= 0 {
+					return true
+				}
+				if len(allocations) == size {
+					more = true
+					return false
+				}
+				allocation := &pb.Allocation{Ip: ip.String(), Ipv6: s.ipv6For(ip.String()), Pool: pool.String(), Pinned: pinned[ip.String()]}
+				if allocated, ok := s.allocations[allocation.Ip]; ok {
+					allocation.Client = allocated.Client
+				}
+				allocations = append(allocations, allocation)
+				return true
+			})
+			if more {
+				break
+			}
 		}
 	}
 
 	response := &pb.ListAllocationsResponse{
 		Allocations: allocations,
 	}
+	if more {
+		response.NextPageToken = allocations[len(allocations)-1].Ip
+	}
 	return response, nil
 }
 
-// scrubURLError will replace a password query-param's value with "******" (8 actual * characters) only if the error is of type *url.Error
-// non *url.Errors or url.Errors w/o "password" are returned with no change.
-func scrubURLError(u url.URL, err error) error {
-	const pass = "********"
-	if e, ok := err.(*url.Error); !ok {
-		return err
-	} else {
-		q := u.Query()
-		if q.Get("password") == "" {
-			return err
+func (s *VPNServer) PinAllocation(ctx context.Context, in *pb.PinAllocationRequest) (*pb.PinAllocationResponse, error) {
+	log := logger.With("client", in.Client, "ip", in.Ip)
+	log.Info("got pin allocation request")
+
+	fail := func(err error) (*pb.PinAllocationResponse, error) {
+		log.With("error", err).Info()
+		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+		return nil, err
+	}
+
+	if in.Client == "" {
+		return fail(errors.New("no client specified"))
+	}
+	ip := net.ParseIP(in.Ip).To4()
+	if ip == nil {
+		return fail(errors.New("invalid ip `" + in.Ip + "` specified"))
+	}
+	pool := poolOf(s.pools, ip.String())
+	if pool == nil || !pool.allocatable(ip) {
+		return fail(errors.Errorf("%s is not an allocatable address of the vpn pools", ip))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, pin := range s.pins {
+		if pin.Ip == ip.String() && pin.Client != in.Client {
+			return fail(errors.Errorf("%s is already pinned to %s", ip, pin.Client))
 		}
-		q.Set("password", pass)
-		u.RawQuery = q.Encode()
-		e.URL = u.String()
-		return e
 	}
+	if allocation, ok := s.allocations[ip.String()]; ok && allocation.Client != in.Client {
+		return fail(errors.Errorf("%s is allocated to %s, disconnect it first", ip, allocation.Client))
+	}
+
+	pin := &pb.Allocation{Client: in.Client, Ip: ip.String(), Pool: pool.String(), Pinned: true}
+	if err := s.store.putPin(pin); err != nil {
+		log.Error(err)
+		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+		return nil, err
+	}
+	s.pins[in.Client] = pin
+
+	if connection, ok := s.connections[in.Client]; ok && connection.Allocation.Ip != pin.Ip {
+		log.With("current", connection.Allocation.Ip).Info("client is connected with another ip, the pin applies to its next connection")
+	}
+	return &pb.PinAllocationResponse{}, nil
 }
 
-func fetchProjects(client *packngo.Client) ([]packngo.Project, error) {
-	projects, _, err := client.Projects.List(nil)
-	if err != nil {
-		return nil, errors.Wrap(err, "listing projects")
+func (s *VPNServer) UnpinAllocation(ctx context.Context, in *pb.UnpinAllocationRequest) (*pb.UnpinAllocationResponse, error) {
+	log := logger.With("client", in.Client)
+	log.Info("got unpin allocation request")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.pins[in.Client]; !ok {
+		err := errors.New("client `" + in.Client + "` has no pinned ip")
+		log.With("error", err).Info()
+		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+		return nil, err
 	}
-	if len(projects) == 0 {
-		return nil, errors.New("no projects found")
+	if err := s.store.deletePin(in.Client); err != nil {
+		log.Error(err)
+		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+		return nil, err
+	}
+	delete(s.pins, in.Client)
+	return &pb.UnpinAllocationResponse{}, nil
+}
+
+func (s *VPNServer) ListPins(ctx context.Context, in *pb.ListPinsRequest) (*pb.ListPinsResponse, error) {
+	logger.Info("got list pins request")
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pins := make([]*pb.Allocation, 0, len(s.pins))
+	for _, pin := range s.pins {
+		pins = append(pins, proto.Clone(pin).(*pb.Allocation))
 	}
-	return projects, nil
+	sort.Slice(pins, func(i, j int) bool {
+		return bytes.Compare(net.ParseIP(pins[i].Ip), net.ParseIP(pins[j].Ip)) < 0
+	})
+	return &pb.ListPinsResponse{Pins: pins}, nil
 }
 
-func fetchIPs(client *packngo.Client, facility string, project packngo.Project) ([]packngo.IPAddressReservation, error) {
-	pIPs, _, err := client.ProjectIPs.List(project.ID, &packngo.ListOptions{})
+func (s *VPNServer) FirewallDiff(ctx context.Context, in *pb.FirewallDiffRequest) (*pb.FirewallDiffResponse, error) {
+	logger.Info("got firewall diff request")
+	s.firewallMu.Lock()
+	defer s.firewallMu.Unlock()
+
+	drifts, err := s.firewallDrift()
 	if err != nil {
-		return nil, errors.Wrapf(err, "fetching ip addresses of project=%s", project.ID)
+		logger.Error(err)
+		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+		return nil, err
 	}
+	return &pb.FirewallDiffResponse{Drifts: drifts}, nil
+}
 
-	var ips []packngo.IPAddressReservation
-	for _, ip := range pIPs {
-		if ip.Public {
-			continue
-		}
-		ips = append(ips, ip)
+func (s *VPNServer) ListLockouts(ctx context.Context, in *pb.ListLockoutsRequest) (*pb.ListLockoutsResponse, error) {
+	logger.Info("got list lockouts request")
+	return &pb.ListLockoutsResponse{Lockouts: s.lockouts.list()}, nil
+}
+
+func (s *VPNServer) ClearLockout(ctx context.Context, in *pb.ClearLockoutRequest) (*pb.ClearLockoutResponse, error) {
+	log := logger.With("kind", in.Kind, "key", in.Key, "all", in.All)
+	log.Info("got clear lockout request")
+
+	kind, key := in.Kind, in.Key
+	if in.All {
+		kind, key = "", ""
+	} else if kind == "" || key == "" {
+		return nil, errors.New("no user or ip supplied")
 	}
 
-	return ips, nil
+	cleared, err := s.lockouts.clear(kind, key)
+	if err != nil {
+		log.With("error", err).Info()
+		return nil, err
+	}
+	return &pb.ClearLockoutResponse{Cleared: int32(cleared)}, nil
 }
 
-func getSubnets(client *packngo.Client, facility string) ([]packngo.IPAddressReservation, error) {
+func (s *VPNServer) EnrollServiceAccount(ctx context.Context, in *pb.EnrollServiceAccountRequest) (*pb.EnrollServiceAccountResponse, error) {
+	log := logger.With("name", in.Name, "client", in.Client, "projects", in.Projects, "issue_secret", in.IssueSecret, "force", in.Force)
+	log.Info("got enroll service account request")
 
-	var ips []packngo.IPAddressReservation
+	secret, err := s.serviceAccounts.enroll(in.Name, in.Client, in.Projects, in.IssueSecret, in.Force)
+	if err != nil {
+		log.With("error", err).Info("failed to enroll service account")
+		return nil, err
+	}
+	return &pb.EnrollServiceAccountResponse{Secret: secret}, nil
+}
 
-	if isTestingEnvironment() {
-		ip := packngo.IpAddressCommon{Address: "10.88.111.11", Gateway: "10.88.111.1", Network: "10.88.111.0", AddressFamily: 4, Netmask: "255.255.255.128", Public: false, CIDR: 25, Management: false, Manageable: true}
-		ipres := packngo.IPAddressReservation{IpAddressCommon: ip}
-		ips = append(ips, ipres)
-		return ips, nil
+func (s *VPNServer) DeleteServiceAccount(ctx context.Context, in *pb.DeleteServiceAccountRequest) (*pb.DeleteServiceAccountResponse, error) {
+	logger.With("client", in.Client).Info("got delete service account request")
+
+	if s.sessions != nil {
+		s.sessions.end(in.Client)
+	}
+	if err := s.serviceAccounts.delete(in.Client); err != nil {
+		return nil, err
 	}
+	return &pb.DeleteServiceAccountResponse{}, nil
+}
 
-	projects, err := fetchProjects(client)
+func (s *VPNServer) ListServiceAccounts(ctx context.Context, in *pb.ListServiceAccountsRequest) (*pb.ListServiceAccountsResponse, error) {
+	logger.Info("got list service accounts request")
+
+	accounts, err := s.serviceAccounts.accounts()
 	if err != nil {
+		logger.Error(err)
+		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
 		return nil, err
 	}
+	return &pb.ListServiceAccountsResponse{Accounts: accounts}, nil
+}
 
-	var wg sync.WaitGroup
-	wg.Add(len(projects))
-	errs := make(chan error)
+func (s *VPNServer) CreateBreakGlass(ctx context.Context, in *pb.CreateBreakGlassRequest) (*pb.CreateBreakGlassResponse, error) {
+	log := logger.With("client", in.Client, "routes", in.Routes, "lifetime", in.Lifetime, "uses", in.Uses, "reason", in.Reason)
+	log.Info("got create break glass request")
 
-	var mu sync.Mutex
+	bg, credential, err := s.breakGlass.create(in.Client, in.Routes, time.Duration(in.Lifetime)*time.Second, in.Uses, in.Reason)
+	if err != nil {
+		log.With("error", err).Info("failed to create break glass credential")
+		return nil, err
+	}
+	return &pb.CreateBreakGlassResponse{Id: bg.Id, Credential: credential, Expires: bg.Expires}, nil
+}
 
-	for _, project := range projects {
-		go func(project packngo.Project) {
-			defer wg.Done()
+func (s *VPNServer) ListBreakGlass(ctx context.Context, in *pb.ListBreakGlassRequest) (*pb.ListBreakGlassResponse, error) {
+	logger.Info("got list break glass request")
 
-			pIPs, err := fetchIPs(client, facility, project)
-			if err != nil {
-				errs <- err
-			}
-			if len(pIPs) == 0 {
-				return
-			}
+	credentials, err := s.breakGlass.credentials()
+	if err != nil {
+		logger.Error(err)
+		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+		return nil, err
+	}
+	return &pb.ListBreakGlassResponse{Credentials: credentials}, nil
+}
 
-			mu.Lock()
-			ips = append(ips, pIPs...)
-			mu.Unlock()
+func (s *VPNServer) RevokeBreakGlass(ctx context.Context, in *pb.RevokeBreakGlassRequest) (*pb.RevokeBreakGlassResponse, error) {
+	logger.With("id", in.Id).Info("got revoke break glass request")
 
-		}(project)
+	if err := s.breakGlass.revoke(in.Id); err != nil {
+		return nil, err
 	}
+	return &pb.RevokeBreakGlassResponse{}, nil
+}
 
-	wg.Wait()
-	close(errs)
-	err = <-errs
+func (s *VPNServer) InvalidateSubnets(ctx context.Context, in *pb.InvalidateSubnetsRequest) (*pb.InvalidateSubnetsResponse, error) {
+	log := logger.With("user", in.User, "all", in.All)
+	log.Info("got invalidate subnets request")
+	user := in.User
+	if in.All {
+		user = ""
+	} else if user == "" {
+		return nil, errors.New("no user supplied")
+	}
+	// without a cache, subnets are always fetched fresh and there is nothing to invalidate
+	if s.subnetCache == nil {
+		return &pb.InvalidateSubnetsResponse{}, nil
+	}
+	return &pb.InvalidateSubnetsResponse{Invalidated: int32(s.subnetCache.invalidate(user))}, nil
+}
+
+var errNoLocalUsers = errors.New("local users are not enabled, set " + doormanAuth + "=" + authLocal)
+
+func (s *VPNServer) EnrollLocalUser(ctx context.Context, in *pb.EnrollLocalUserRequest) (*pb.EnrollLocalUserResponse, error) {
+	log := logger.With("user", in.User, "routes", in.Routes, "force", in.Force)
+	log.Info("got enroll local user request")
+	if s.localUsers == nil {
+		return nil, errNoLocalUsers
+	}
+
+	secret, err := s.localUsers.enroll(in.User, in.Password, in.Routes, in.Force)
 	if err != nil {
+		log.With("error", err).Info("failed to enroll local user")
+		return nil, err
+	}
+	return &pb.EnrollLocalUserResponse{
+		TotpSecret: totpEncoding.EncodeToString(secret),
+		TotpUrl:    totpURL(localIssuer, in.User, secret),
+	}, nil
+}
+
+func (s *VPNServer) DeleteLocalUser(ctx context.Context, in *pb.DeleteLocalUserRequest) (*pb.DeleteLocalUserResponse, error) {
+	logger.With("user", in.User).Info("got delete local user request")
+	if s.localUsers == nil {
+		return nil, errNoLocalUsers
+	}
+
+	if err := s.localUsers.delete(in.User); err != nil {
 		return nil, err
 	}
+	return &pb.DeleteLocalUserResponse{}, nil
+}
 
-	if len(ips) == 0 {
-		return nil, errors.New("no backend routes to push")
+func (s *VPNServer) ListLocalUsers(ctx context.Context, in *pb.ListLocalUsersRequest) (*pb.ListLocalUsersResponse, error) {
+	logger.Info("got list local users request")
+	if s.localUsers == nil {
+		return nil, errNoLocalUsers
 	}
 
-	return ips, nil
+	users, err := s.localUsers.users()
+	if err != nil {
+		logger.Error(err)
+		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+		return nil, err
+	}
+	return &pb.ListLocalUsersResponse{Users: users}, nil
 }
 
-func (s *VPNServer) configureClient(log log.Logger, w io.StringWriter, client string, ips []packngo.IPAddressReservation) (alloc *pb.Allocation, routes []*pb.Route, err error) {
+func (s *VPNServer) configureClient(log log.Logger, w io.StringWriter, client string, subnets []*net.IPNet) (alloc *pb.Allocation, routes []*pb.Route, err error) {
 	allocation, err := s.reserveNextAvailableIP(client)
 	if err != nil {
 		err = errors.WithMessage(err, "reserve next available ip")
@@ -204,11 +485,8 @@ func (s *VPNServer) configureClient(log log.Logger, w io.StringWriter, client st
 		allocation = nil
 	}()
 
-	vpnIP := allocation.Ip
-
-	cmd := fmt.Sprintf(commandCreate, vpnIP)
-	if stdout, stderr, err := s.shellRun(cmd); err != nil {
-		log.With("stdout", stdout, "stderr", stderr).Error(err)
+	if err := s.firewall.CreateSet(allocation); err != nil {
+		log.Error(err)
 		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
 		return nil, nil, err
 	}
@@ -217,220 +495,365 @@ func (s *VPNServer) configureClient(log log.Logger, w io.StringWriter, client st
 			return
 		}
 
-		cmd := fmt.Sprintf(commandDelete, vpnIP)
-		if stdout, stderr, cleanupErr := s.shellRun(cmd); cleanupErr != nil {
-			log.With("stdout", stdout, "stderr", stderr, "cmd", cmd).Fatal(cleanupErr, "error while cleaning up an error")
+		// enabling ipv6 may fail after ipv4 was enabled, the sets can only be deleted once no rule refers to them
+		if cleanupErr := s.firewall.Disable(allocation); cleanupErr != nil {
+			log.Fatal(cleanupErr, "error while cleaning up an error")
+			metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+		}
+		if cleanupErr := s.firewall.DeleteSet(allocation); cleanupErr != nil {
+			log.Fatal(cleanupErr, "error while cleaning up an error")
 			metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
 		}
 
 		routes = nil
 	}()
 
-	for _, ip := range ips {
-		cmd = fmt.Sprintf(commandAdd, vpnIP, ip.Network, ip.CIDR)
-		if stdout, stderr, err := s.shellRun(cmd); err != nil {
-			log.With("stdout", stdout, "stderr", stderr).Error(err)
-			// clean up is already handled in commandCreate's defer
+	for _, network := range subnets {
+		if network.IP.To4() == nil && allocation.Ipv6 == "" {
+			log.With("route", network).Debug("skipping ipv6 route, ipv6 is not enabled")
+			continue
+		}
+
+		if err := s.firewall.AddRoute(allocation, network); err != nil {
+			log.Error(err)
+			// clean up is already handled in CreateSet's defer
 			metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
 			return nil, nil, err
 		}
-		log.Debug(cmd)
+		log.With("route", network).Debug("added route to firewall")
 
-		routes = append(routes, &pb.Route{Cidr: fmt.Sprintf("%s/%d", ip.Network, ip.CIDR)})
+		routes = append(routes, &pb.Route{Cidr: network.String()})
 
-		route := fmt.Sprintf(`push "route %s %s"`+"\n", ip.Network, ip.Netmask)
+		route := pushRoute(network)
 		log.Debug(route)
 		w.WriteString(route)
 	}
 
-	cmd = fmt.Sprintf(commandEnable, vpnIP, s.magicIP)
-	if stdout, stderr, err := s.shellRun(cmd); err != nil {
-		log.With("stdout", stdout, "stderr", stderr).Error(err)
+	if err := s.firewall.Enable(allocation); err != nil {
+		log.Error(err)
 		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
 		return nil, nil, err
 	}
 
-	w.WriteString(fmt.Sprintln("ifconfig-push", vpnIP, "255.255.255.0"))
+	s.writeClientAddress(w, allocation)
 
 	return allocation, routes, nil
 }
 
-// Private helper functions for the Authenticate method
-func (s *VPNServer) createEncodedURL(username string, password string) url.URL {
-	loginURL := *s.sessions
-	loginURL.RawQuery = url.Values(map[string][]string{
-		"login":    {username},
-		"password": {password},
-	}).Encode()
-
-	return loginURL
+// pushRoute returns the client-config-dir directive pushing network to the client.
+func pushRoute(network *net.IPNet) string {
+	if network.IP.To4() == nil {
+		return fmt.Sprintf(`push "route-ipv6 %s"`+"\n", network)
+	}
+	return fmt.Sprintf(`push "route %s %s"`+"\n", network.IP, net.IP(network.Mask))
 }
 
-func (s *VPNServer) createLoginRequest(loginURL url.URL) (*retryable.Request, error) {
-	req, err := retryable.NewRequest("POST", loginURL.String(), nil)
-	if err != nil {
-		err = errors.Wrap(err, "failed setting up http request")
-		logger.With("error", err).Info()
-		return nil, err
+func (s *VPNServer) Authenticate(ctx context.Context, in *pb.AuthenticateRequest) (*pb.AuthenticateResponse, error) {
+	if in.Client == "" {
+		return nil, errors.New("no OpenVPN client supplied")
 	}
-	return req, nil
-}
 
-func (s *VPNServer) validate2faEnabled(loginURL url.URL, request *retryable.Request) error {
-	// Set Token
-	request.Header.Set("X-Consumer-Token", s.consumerToken)
+	log := logger.With("client", in.Client, "address", in.ConnectingIp)
+	log.Info("received authenticate request")
 
-	client := retryable.NewClient()
+	var username, password string
+	if !isTestingEnvironment() {
+		var err error
+		username, password, err = ReadOpenVPNFile(in.File)
+		if err != nil {
+			err = errors.WithMessage(err, "parse openvpn file")
+			log.With("error", err).Info()
+			return nil, err
+		}
+	}
 
-	logger.Info("attempting to validate user token.")
-	response, err := client.Do(request)
-	if err != nil {
-		err = errors.Wrap(scrubURLError(loginURL, err), "failed to connect to service.")
-		logger.With("error", err).Info()
-		return err
+	// openvpn waits for a deferred script's decision without stalling other clients
+	if in.ControlFile != "" {
+		if err := s.checkControlFile(in.ControlFile); err != nil {
+			log.With("error", err).Info()
+			metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+			return nil, err
+		}
+		go func() {
+			result := "1"
+			if err := s.authenticateWithin(context.Background(), log, in.Client, in.ConnectingIp, username, password); err != nil {
+				result = "0"
+			}
+			if err := writeControlFile(in.ControlFile, result); err != nil {
+				log.Error(err)
+				metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+			}
+		}()
+		return &pb.AuthenticateResponse{Status: authDeferred}, nil
 	}
 
-	// User name and password are valid, but no 2fa has been enabled.
-	// Login was successful.
-	if response.StatusCode/100 == 2 {
-		err = errors.New("2-factor not enabled")
-		logger.With("error", err).Info()
-		metrics.AuthenticationFailureTotalCount.Inc()
-		return err
+	if err := s.authenticateWithin(ctx, log, in.Client, in.ConnectingIp, username, password); err != nil {
+		return nil, authStatus(err)
 	}
+	return &pb.AuthenticateResponse{Status: 0}, nil
+}
+
+// controlFileRe matches the auth control files openvpn creates in its tmp-dir.
+var controlFileRe = regexp.MustCompile(`^openvpn_acf_[0-9a-f]+\.tmp$`)
 
+// checkControlFile makes sure the rpc only writes to auth control files openvpn created in its tmp-dir.
+func (s *VPNServer) checkControlFile(path string) error {
+	if !filepath.IsAbs(path) || filepath.Dir(filepath.Clean(path)) != s.tmpDir || !controlFileRe.MatchString(filepath.Base(path)) {
+		return errors.Errorf("auth control file %s is not an openvpn auth control file in %s", path, s.tmpDir)
+	}
 	return nil
 }
 
-func (s *VPNServer) validateCredentials(loginURL url.URL, request *retryable.Request, twoFactorToken string) (*http.Response, error) {
-	// Set OTP Token
-	request.Header.Set("X-OTP-Token", twoFactorToken)
+// writeControlFile writes the decision to the auth control file, which openvpn created already.
+func writeControlFile(path, result string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return errors.Wrap(err, "write openvpn auth control file")
+	}
+	defer f.Close()
+	if _, err := f.WriteString(result); err != nil {
+		return errors.Wrap(err, "write openvpn auth control file")
+	}
+	return nil
+}
 
-	client := retryable.NewClient()
+// loginFailed records a failed login against the user and the address it came from, if the credentials were wrong.
+func (s *VPNServer) loginFailed(log log.Logger, login, connectingIP string, err error) {
+	if s.lockouts == nil || !isCredentialsFailure(err) {
+		return
+	}
+	if s.lockouts.failed(login, connectingIP) {
+		log.With("user", login).Info("locked out after too many failed logins")
+	}
+}
 
-	logger.Info("attempting to validate user token.")
-	response, err := client.Do(request)
+// checkClientOwner refuses users logging in with a certificate issued to someone else, e.g. with a leaked profile.
+// Certificates whose owner was not recorded belong to the user their client is named after.
+func (s *VPNServer) checkClientOwner(log log.Logger, client, connectingIP string, id *identity) error {
+	owner := client
+	recorded, err := s.store.clientOwner(client)
 	if err != nil {
-		err = errors.Wrap(scrubURLError(loginURL, err), "failed to validate customer token")
-		logger.With("error", err).Info()
-		return nil, err
+		log.Error(err)
+		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+		return err
 	}
-
-	if response.StatusCode/100 != 2 {
-		err := errors.New("invalid username, password, or 2-factor token`")
-		logger.With("error", err, "response", response.StatusCode).Info()
-		metrics.AuthenticationFailureTotalCount.Inc()
-		return nil, err
+	if recorded != nil {
+		owner = recorded.Owner
 	}
-	return response, nil
+	if id.owns(owner) {
+		return nil
+	}
+
+	audit(auditClientOwnerMismatch, "client", client, "owner", owner, "user", id.user, "user_id", id.id, "address", connectingIP)
+	metrics.AuthenticationFailureTotalCount.Inc()
+	return errClientOwnerMismatch
 }
 
-func (s *VPNServer) Authenticate(ctx context.Context, in *pb.AuthenticateRequest) (*pb.AuthenticateResponse, error) {
-	if in.Client == "" {
-		return nil, errors.New("no OpenVPN client supplied")
+// authenticateWithin authenticates the client, giving up on it once the auth timeout passed.
+// A client that is only set up after it was given up on is torn down again, openvpn has denied it by then.
+func (s *VPNServer) authenticateWithin(ctx context.Context, log log.Logger, client, connectingIP, login, password string) error {
+	s.mu.RLock()
+	before := s.connections[client]
+	s.mu.RUnlock()
+
+	authenticate := func(ctx context.Context) error {
+		return s.authenticate(ctx, log, client, connectingIP, login, password)
+	}
+	late := func() {
+		s.mu.RLock()
+		after := s.connections[client]
+		s.mu.RUnlock()
+		if after != nil && after != before {
+			log.Info("tearing down client authenticated after the timeout")
+			if err := s.disconnect(client); err != nil {
+				log.With("error", err).Info("failed to disconnect client")
+			}
+		}
 	}
 
-	log := logger.With("client", in.Client, "address", in.ConnectingIp)
-	log.Info("received authenticate request")
+	err := runWithin(ctx, s.authTimeout, authenticate, late)
+	if err == context.DeadlineExceeded {
+		err = errors.WithMessagef(err, "authentication did not complete within %s", s.authTimeout)
+		log.With("error", err).Info()
+		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+	}
+	return err
+}
 
-	var authToken *AuthToken = &AuthToken{}
-	var username string
+// authenticate validates the client's credentials, then sets up its ip allocation, routes and firewall rules.
+// It backs both the Authenticate rpc and the openvpn management interface.
+func (s *VPNServer) authenticate(ctx context.Context, log log.Logger, client, connectingIP, login, password string) error {
+	// the testing environment authenticates everyone as the same equinix user
+	id := &identity{user: "00000000-0000-0000-0000-000000000001", id: "00000000-0000-0000-0000-000000000001", backend: authEquinix}
 
-	if !isTestingEnvironment() {
-		username, password, twofactor, err := ParseOpenVPNFile(in.File)
-		if err != nil {
-			err = errors.WithMessage(err, "parse openvpn file")
-			log.With("error", err).Info()
-			return nil, err
+	if !isTestingEnvironment() && s.lockouts != nil {
+		if err := s.lockouts.check(login, connectingIP); err != nil {
+			log.With("user", login).Info("refusing login, locked out")
+			metrics.AuthenticationLockedOutTotal.Inc()
+			return err
 		}
+	}
 
-		encodedURL := s.createEncodedURL(username, password)
-		request, err := s.createLoginRequest(encodedURL)
+	// token is the session token pushed to the client, the one it came back with or a new one
+	var token string
+	// break glass credentials are checked on every login, their clients get no session token
+	var breakGlass bool
+	if !isTestingEnvironment() && s.sessions != nil && isSessionToken(password) {
+		session, err := s.sessions.verify(client, login, password)
 		if err != nil {
-			return nil, err
+			log.With("error", err).Info()
+			metrics.AuthenticationFailureTotalCount.Inc()
+			s.loginFailed(log, login, connectingIP, err)
+			return err
+		}
+		id = &identity{user: session.user, routes: session.routes}
+		token = password
+		log.Info("authenticated client with its session token")
+	} else if !isTestingEnvironment() {
+		var account *pb.ServiceAccount
+		var err error
+		if s.serviceAccounts != nil {
+			account, err = s.serviceAccounts.account(client)
+			if err != nil {
+				log.Error(err)
+				metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+				return err
+			}
 		}
 
 		start := time.Now()
-		err = s.validate2faEnabled(encodedURL, request)
-		if err != nil {
-			return nil, err
+		if account != nil {
+			// service accounts log in with a secret or api token, there is no twofactor token to split off
+			id, err = s.serviceAccounts.Authenticate(ctx, account, login, password)
+		} else {
+			// users of web authenticators like oidc log in out of band, not the credentials they connect with
+			var twofactor string
+			if _, ok := s.authenticator.(webAuthenticator); !ok {
+				login, password, twofactor, err = ParseOpenVPNCredentials(login, password)
+				if err != nil {
+					err = errors.WithMessage(err, "parse openvpn credentials")
+					log.With("error", err).Info()
+					return err
+				}
+			}
+			breakGlass = s.breakGlass != nil && isBreakGlass(password)
+			if breakGlass {
+				// checked by doorman alone, so staff get in while the authentication backend is unavailable
+				id, err = s.breakGlass.Authenticate(client, password)
+			} else {
+				id, err = s.authenticator.Authenticate(ctx, login, password, twofactor)
+			}
 		}
-		response, err := s.validateCredentials(encodedURL, request, twofactor)
 		if err != nil {
-			return nil, err
+			s.loginFailed(log, login, connectingIP, err)
+			return err
 		}
-		err = json.NewDecoder(response.Body).Decode(authToken)
-		if err != nil || authToken.Token == "" {
-			err = errors.Wrap(err, "fetching API authentication token")
-			logger.With("error", err).Info()
-			return nil, err
+		// service accounts and break glass credentials are bound to their client when they are created
+		if account == nil && !breakGlass {
+			if err := s.checkClientOwner(log, client, connectingIP, id); err != nil {
+				s.loginFailed(log, login, connectingIP, err)
+				return err
+			}
 		}
-		if err != nil {
-			return nil, err
+		// only once the credentials were used with the user's own certificate
+		if s.lockouts != nil {
+			s.lockouts.succeeded(login)
 		}
 
 		duration := time.Since(start)
 		metrics.AuthenticationDuration.Observe(duration.Seconds())
 		metrics.AuthenticationSuccessTotalCount.Inc()
 		log.Info("successfully authenticated client")
-	} else {
-		username = "00000000-0000-0000-0000-000000000001"
 	}
 
 	s.mu.RLock()
-	_, ok := s.connections[in.Client]
+	_, ok := s.connections[client]
 	s.mu.RUnlock()
 	if ok {
 		log.Info("client seems to already be connected, maybe we were slow last time around")
-		return &pb.AuthenticateResponse{Status: 0}, nil
+		return nil
 	}
 
-	packetClient := packngo.NewClientWithAuth(s.consumerToken, authToken.Token, nil)
-
-	ips, err := getSubnets(packetClient, s.facilityCode)
+	subnets, err := s.subnetProvider.Subnets(ctx, id)
 	if err != nil {
 		log.With("err", err).Info()
-		return nil, err
+		return err
 	}
 
-	ccdFile, err := os.Create(doormanOpenVPNCCD + "/" + in.Client)
+	if breakGlass && s.sessions != nil {
+		// so the client can't come back with the token of an earlier login either
+		s.sessions.end(client)
+	} else if token == "" && s.sessions != nil && !isTestingEnvironment() {
+		token, err = s.sessions.issue(client, login, id.user, subnets)
+		if err != nil {
+			log.Error(err)
+			metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+			return err
+		}
+	}
+
+	ccdFile, err := s.createClientConfig(client)
 	if err != nil {
-		err = errors.Wrap(err, "creating openvpn config file")
 		log.Error(err)
 		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
-		return nil, err
+		return err
 	}
 	defer ccdFile.Close()
 
-	allocation, routes, err := s.configureClient(log, ccdFile, in.Client, ips)
+	if token != "" {
+		ccdFile.WriteString(fmt.Sprintf(`push "auth-token %s"`+"\n", token))
+	}
+
+	s.firewallMu.RLock()
+	defer s.firewallMu.RUnlock()
+
+	allocation, routes, err := s.configureClient(log, ccdFile, client, subnets)
 	if err != nil {
 		log.With("error", err).Info("failed to configure client")
 		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
-		return nil, err
+		return err
 	}
 
 	connection := &pb.Connection{
-		Client:       in.Client,
+		Client:       client,
 		Allocation:   allocation,
 		Routes:       routes,
 		Since:        time.Now().Unix(),
-		ConnectingIp: in.ConnectingIp,
-		Username:     username,
+		ConnectingIp: connectingIP,
+		Username:     id.user,
 	}
 	s.mu.Lock()
-	s.connections[in.Client] = connection
+	s.connections[client] = connection
 	s.mu.Unlock()
 	metrics.ActiveClientTotal.Inc()
 
-	return &pb.AuthenticateResponse{Status: 0}, nil
+	if err := s.store.putConnection(connection); err != nil {
+		log.Error(err)
+		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+	}
+
+	return nil
 }
 
 func (s *VPNServer) ListConnections(ctx context.Context, in *pb.ListConnectionsRequest) (*pb.ListConnectionsResponse, error) {
 	logger.Info("incoming list connections request")
 
+	// traffic counters are best effort, connections are still listed if openvpn hasn't written its status yet
+	status, err := ParseOpenVPNStatusFile(doormanOpenVPNStatus)
+	if err != nil {
+		logger.With("error", err).Info("unable to read openvpn status")
+	}
+
 	s.mu.RLock()
 	connections := make([]*pb.Connection, 0, len(s.connections))
 	for _, conn := range s.connections {
+		conn = proto.Clone(conn).(*pb.Connection)
+		if live, ok := status[conn.Client]; ok {
+			conn.RealAddress = live.RealAddress
+			conn.BytesReceived = live.BytesReceived
+			conn.BytesSent = live.BytesSent
+			conn.LastRef = live.LastRef
+		}
 		connections = append(connections, conn)
 
 	}
@@ -462,6 +885,16 @@ func (s *VPNServer) CreateClient(ctx context.Context, in *pb.CreateClientRequest
 		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
 		return nil, err
 	}
+
+	owner := in.Owner
+	if owner == "" {
+		owner = in.Client
+	}
+	if err := s.store.putClientOwner(&pb.ClientOwner{Client: in.Client, Owner: owner, Created: time.Now().Unix()}); err != nil {
+		logger.With("client", in.Client).Error(err)
+		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+		return nil, err
+	}
 	response := &pb.CreateClientResponse{
 		Config: s.generateConfig(in.Client),
 	}
@@ -477,11 +910,21 @@ func (s *VPNServer) GetClient(ctx context.Context, in *pb.GetClientRequest) (*pb
 		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
 		return nil, err
 	}
+	owner, err := s.store.clientOwner(in.Client)
+	if err != nil {
+		logger.With("client", in.Client).Error(err)
+		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+		return nil, err
+	}
 	response := &pb.GetClientResponse{
 		Status:         client.Status,
 		ExpiresDate:    client.ExpiresDate,
 		RevocationDate: client.RevocationDate,
 		Config:         s.generateConfig(in.Client),
+		Owner:          in.Client,
+	}
+	if owner != nil {
+		response.Owner = owner.Owner
 	}
 	return response, nil
 }
@@ -495,21 +938,34 @@ func (s *VPNServer) RevokeClient(ctx context.Context, in *pb.RevokeClientRequest
 		return nil, err
 
 	}
+	if s.sessions != nil {
+		s.sessions.end(in.Client)
+	}
 	if err := s.revokeCertificate(in.Client, false); err != nil {
 		// error is logged in revokeCertificate
 		return nil, err
 	}
+	if err := s.store.deleteClientOwner(in.Client); err != nil {
+		logger.With("client", in.Client).Error(err)
+		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+	}
 
 	response := &pb.RevokeClientResponse{
 		Status: 0,
 	}
 
-	// If not connected do what?
-	if len(s.connections) == 0 {
+	s.mu.RLock()
+	_, connected := s.connections[in.Client]
+	s.mu.RUnlock()
+	if !connected {
 		logger.Info("no active connections, done with revocation")
 		return response, nil
 	}
 	logger.Info("active connections found, closing them")
+	// a session openvpn no longer knows about is only left in doorman's state
+	if err := s.killClient(ctx, in.Client, "HALT", "certificate revoked"); err != nil && status.Code(err) != codes.NotFound {
+		return nil, err
+	}
 	s.disconnect(in.Client)
 
 	return response, nil
@@ -526,91 +982,268 @@ func (s *VPNServer) ListClients(ctx context.Context, in *pb.ListClientsRequest)
 func (s *VPNServer) Disconnect(ctx context.Context, in *pb.DisconnectRequest) (*pb.DisconnectResponse, error) {
 	logger.With("client", in.Client).Info("got disconnect client request")
 
-	s.disconnect(in.Client)
+	// the client has to authenticate with its credentials again when it reconnects
+	if s.sessions != nil {
+		s.sessions.end(in.Client)
+	}
+	// doorman's state is released even if openvpn has no session or can't be reached, e.g. for a connection
+	// restored after a restart whose client never came back
+	killErr := s.killClient(ctx, in.Client, "RESTART", in.Message)
+	// usually already done when openvpn reported the disconnect
+	s.mu.RLock()
+	_, connected := s.connections[in.Client]
+	s.mu.RUnlock()
+	if connected {
+		if err := s.disconnect(in.Client); err != nil {
+			return nil, err
+		}
+	}
+	if killErr != nil && status.Code(killErr) != codes.NotFound {
+		if connected {
+			killErr = errors.WithMessage(killErr, "released the client's connection, but not its openvpn session")
+		}
+		return nil, killErr
+	}
+	if killErr != nil && !connected {
+		return nil, killErr
+	}
 
 	response := &pb.DisconnectResponse{
 		Status: 0,
 	}
-	metrics.ActiveClientTotal.Dec()
 	return response, nil
 }
 
 func (s *VPNServer) disconnect(client string) error {
+	s.firewallMu.RLock()
+	defer s.firewallMu.RUnlock()
+
 	if err := s.removeIptables(client); err != nil {
 		return err
 	}
+	s.forgetConnection(client)
+	metrics.ActiveClientTotal.Dec()
+
+	return nil
+}
+
+// forgetConnection releases the client's allocation and drops its connection from memory and the state store.
+func (s *VPNServer) forgetConnection(client string) {
 	s.freeIPAllocation(client)
 
 	s.mu.Lock()
 	delete(s.connections, client)
 	defer s.mu.Unlock()
 
-	return nil
+	if err := s.store.deleteConnection(client); err != nil {
+		logger.With("client", client).Error(err)
+		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+	}
 }
 
 func (s *VPNServer) removeIptables(client string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	connection := s.connections[client]
-	vpnIP := connection.Allocation.Ip
-	cmd := fmt.Sprintf(commandDisable, vpnIP, s.magicIP)
-	if stdout, stderr, err := s.shellRun(cmd); err != nil {
-		logger.With("stdout", stdout, "stderr", stderr).Error(err)
+	connection, ok := s.connections[client]
+	if !ok {
+		err := errors.New("client is not connected")
+		logger.With("client", client, "error", err).Info()
+		return err
+	}
+	if err := s.firewall.Disable(connection.Allocation); err != nil {
+		logger.With("client", client).Error(err)
 		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
 		return err
 	}
-
-	cmd = fmt.Sprintf(commandDelete, vpnIP)
-	if stdout, stderr, err := s.shellRun(cmd); err != nil {
-		logger.With("stdout", stdout, "stderr", stderr).Error(err)
+	if err := s.firewall.DeleteSet(connection.Allocation); err != nil {
+		logger.With("client", client).Error(err)
 		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
 		return err
 	}
 	return nil
 }
 
+// reserveNextAvailableIP allocates an address to client.
+// A pinned address is always used for the client it is pinned to and never handed out to anyone else.
+// In sticky mode clients get the address they last had if it is still available, and addresses other clients
+// last had are only handed out once nothing else is left.
 func (s *VPNServer) reserveNextAvailableIP(client string) (*pb.Allocation, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	var allocated *pb.Allocation
-	for i := range s.allocations {
-		alloc := &s.allocations[i]
-		// we only allow a single connection per client, so if they are already connected
-		// return the same IP address
+	// we only allow a single connection per client, so if they are already connected
+	// return the same IP address
+	for _, alloc := range s.allocations {
 		if alloc.Client == client {
-			allocated = alloc
-			break
+			return alloc, nil
+		}
+	}
+
+	log := logger.With("client", client)
+	if pin, ok := s.pins[client]; ok {
+		pool := poolOf(s.pools, pin.Ip)
+		holder, allocated := s.allocations[pin.Ip]
+		switch {
+		case pool == nil:
+			log.With("ip", pin.Ip).Info("pinned ip is outside of the configured pools, ignoring pin")
+		case allocated:
+			log.With("ip", pin.Ip, "holder", holder.Client).Info("pinned ip is allocated to another client, ignoring pin")
+		default:
+			return s.allocate(client, pin.Ip, pool), nil
 		}
-		// return the next available IP address and assign it to them
-		if alloc.Client == "" {
-			alloc.Client = client
-			allocated = alloc
+	}
+
+	pinned := s.pinnedIPs()
+	if s.stickyIPs {
+		for ip, previous := range s.sticky {
+			if previous != client {
+				continue
+			}
+			_, allocated := s.allocations[ip]
+			if pool := poolOf(s.pools, ip); pool != nil && !allocated && !pinned[ip] {
+				return s.allocate(client, ip, pool), nil
+			}
 			break
 		}
 	}
-	if allocated != nil {
-		return allocated, nil
+
+	// return the next available IP address and assign it to them,
+	// in sticky mode the first pass skips the addresses other clients are likely to come back for
+	passes := 1
+	if s.stickyIPs {
+		passes = 2
+	}
+	var allocated *pb.Allocation
+	for pass := 0; allocated == nil && pass < passes; pass++ {
+		for _, pool := range s.pools {
+			pool.each(func(ip net.IP) bool {
+				addr := ip.String()
+				if _, ok := s.allocations[addr]; ok || pinned[addr] {
+					return true
+				}
+				if previous, ok := s.sticky[addr]; ok && previous != client && pass < passes-1 {
+					return true
+				}
+				allocated = s.allocate(client, addr, pool)
+				return false
+			})
+			if allocated != nil {
+				break
+			}
+		}
+	}
+	if allocated == nil {
+		return nil, errors.New("no available ips in pool")
 	}
-	return nil, errors.New("no available ips in pool")
+	return allocated, nil
+}
+
+// allocate assigns ip to client, s.mu needs to be held.
+// The address is remembered for the client even when not in sticky mode, so that turning it on takes effect right away.
+func (s *VPNServer) allocate(client, ip string, pool *ipPool) *pb.Allocation {
+	log := logger.With("ip", ip, "client", client)
+
+	allocation := &pb.Allocation{Client: client, Ip: ip, Ipv6: s.ipv6For(ip), Pool: pool.String()}
+	s.allocations[ip] = allocation
+	if err := s.store.saveAllocation(allocation); err != nil {
+		log.Error(err)
+		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+	}
+
+	for previousIP, previous := range s.sticky {
+		if previous == client && previousIP != ip {
+			delete(s.sticky, previousIP)
+			if err := s.store.deleteSticky(previousIP); err != nil {
+				log.Error(err)
+				metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+			}
+		}
+	}
+	if s.sticky[ip] != client {
+		s.sticky[ip] = client
+		if err := s.store.putSticky(&pb.Allocation{Client: client, Ip: ip}); err != nil {
+			log.Error(err)
+			metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+		}
+	}
+	return allocation
+}
+
+// ipv6For returns the ipv6 address that goes with the allocated ipv4 address, if ipv6 is enabled.
+func (s *VPNServer) ipv6For(ip string) string {
+	if s.pool6 == nil {
+		return ""
+	}
+	return s.pool6.embed(net.ParseIP(ip)).String()
+}
+
+// pinnedIPs returns the set of pinned addresses, s.mu needs to be held.
+func (s *VPNServer) pinnedIPs() map[string]bool {
+	pinned := make(map[string]bool, len(s.pins))
+	for _, pin := range s.pins {
+		pinned[pin.Ip] = true
+	}
+	return pinned
 }
 
 func (s *VPNServer) freeIPAllocation(client string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for i := range s.allocations {
-		allocation := &s.allocations[i]
+	for ip, allocation := range s.allocations {
 		if allocation.Client == client {
 			logger.With("ip", allocation.Ip, "client", allocation.Client).Info("freed IP allocation")
-			allocation.Client = ""
+			delete(s.allocations, ip)
+			if err := s.store.saveAllocation(&pb.Allocation{Ip: ip}); err != nil {
+				logger.With("ip", ip, "client", client).Error(err)
+				metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+			}
 			break
 		}
 	}
 }
 
+// writeClientAddress writes the client-config-dir directives assigning the allocated address.
+// Clients in a pool other than the primary one are not on the server's subnet, so they also need to be told
+// which gateway to route through.
+func (s *VPNServer) writeClientAddress(w io.StringWriter, allocation *pb.Allocation) {
+	pool := poolOf(s.pools, allocation.Ip)
+	w.WriteString(fmt.Sprintln("ifconfig-push", allocation.Ip, pool.netmask()))
+	if pool != s.pools[0] {
+		w.WriteString(fmt.Sprintf(`push "route-gateway %s"`+"\n", pool.gateway()))
+	}
+	if allocation.Ipv6 != "" {
+		ones, _ := s.pool6.network.Mask.Size()
+		w.WriteString(fmt.Sprintf("ifconfig-ipv6-push %s/%d %s\n", allocation.Ipv6, ones, s.pool6.gateway()))
+	}
+}
+
+// writeOpenVPNPoolConfig writes the openvpn config file with the server directives for the configured pools,
+// it is included by server.conf.
+func (s *VPNServer) writeOpenVPNPoolConfig() {
+	f, err := os.Create(doormanOpenVPNPools)
+	if err != nil {
+		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+		logger.Fatal(errors.Wrap(err, "create openvpn pool config"))
+	}
+	defer f.Close()
+
+	if err := writePoolConfig(f, s.pools, s.pool6); err != nil {
+		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+		logger.Fatal(errors.Wrap(err, "write openvpn pool config"))
+	}
+}
+
+// generateConfig returns the client's openvpn profile.
+// Clients are asked for their twofactor token separately with a static-challenge, unless users log in out of band
+// with a webAuthenticator.
 func (s *VPNServer) generateConfig(client string) string {
+	challenge := `static-challenge "` + staticChallenge + `" 1` + "\n"
+	if _, ok := s.authenticator.(webAuthenticator); ok {
+		challenge = ""
+	}
+
 	const config = `client
 server-poll-timeout 4
 nobind
@@ -621,7 +1254,7 @@ reneg-sec 604800
 sndbuf 100000
 rcvbuf 100000
 auth-user-pass
-comp-lzo no
+%scomp-lzo no
 verb 3
 setenv PUSH_PEER_INFO
 
@@ -639,6 +1272,7 @@ setenv PUSH_PEER_INFO
 
 	return fmt.Sprintf(config,
 		s.facilityCode,
+		challenge,
 		ExtractOpenVPNCA(doormanEasyRSADir+"/pki/ca.crt"),
 		ExtractCertificate(doormanEasyRSADir+"/pki/issued/"+client+".crt"),
 		ExtractPrivateKey(doormanEasyRSADir+"/pki/private/"+client+".key"),
@@ -740,38 +1374,191 @@ func (s *VPNServer) startOpenVPN(ctx context.Context) chan error {
 }
 
 func (s *VPNServer) setupFirewall() {
-	if stdout, stderr, err := s.shellRun("/app/fw-init.sh"); err != nil {
+	if err := s.firewall.Init(); err != nil {
 		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
-		logger.With("stdout", stdout, "stderrr", stderr).Fatal(errors.Wrap(err, "initialize firewall setup"))
+		logger.Fatal(errors.WithMessage(err, "initialize firewall setup"))
 	}
 }
 
-// This populates our IPAllocations to fetch from when clients connect
-func (s *VPNServer) populateIPAllocationPool() {
+// restoreState reloads the allocations and connections that were active when doorman last stopped.
+// Allocations without a matching connection belong to an authentication that never finished and are released.
+func (s *VPNServer) restoreState() {
+	allocations, err := s.store.allocations()
+	if err != nil {
+		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+		logger.Fatal(errors.WithMessage(err, "restore state"))
+	}
+	connections, err := s.store.connections()
+	if err != nil {
+		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+		logger.Fatal(errors.WithMessage(err, "restore state"))
+	}
+	pins, err := s.store.pins()
+	if err != nil {
+		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+		logger.Fatal(errors.WithMessage(err, "restore state"))
+	}
+	sticky, err := s.store.sticky()
+	if err != nil {
+		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+		logger.Fatal(errors.WithMessage(err, "restore state"))
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	ip, network, _ := net.ParseCIDR("192.168.127.1/24")
-	var rawIPs []string
-	for rawIP := ip.Mask(network.Mask); network.Contains(rawIP); s.nextIP(rawIP) {
-		rawIPs = append(rawIPs, rawIP.String())
+	// pins outside of the pools are kept so they come back into effect if the pools are changed back
+	for _, pin := range pins {
+		if poolOf(s.pools, pin.Ip) == nil {
+			logger.With("ip", pin.Ip, "client", pin.Client).Info("pinned ip is outside of the configured pools")
+		}
+		s.pins[pin.Client] = pin
+	}
+	for _, previous := range sticky {
+		s.sticky[previous.Ip] = previous.Client
+	}
+
+	for _, stored := range allocations {
+		pool := poolOf(s.pools, stored.Ip)
+		if pool == nil {
+			// the pools were reconfigured, the client will get a new address when it reconnects
+			logger.With("ip", stored.Ip, "client", stored.Client).Info("dropping stored allocation outside of the configured pools")
+			if err := s.store.saveAllocation(&pb.Allocation{Ip: stored.Ip}); err != nil {
+				logger.With("ip", stored.Ip).Error(err)
+			}
+			continue
+		}
+		stored.Pool = pool.String()
+		stored.Ipv6 = s.ipv6For(stored.Ip)
+		s.allocations[stored.Ip] = stored
+	}
+
+	for _, connection := range connections {
+		log := logger.With("client", connection.Client)
+
+		alloc, ok := s.allocations[connection.GetAllocation().GetIp()]
+		if !ok || alloc.Client != connection.Client {
+			log.With("ip", connection.GetAllocation().GetIp()).Info("dropping stored connection without a matching allocation")
+			if err := s.store.deleteConnection(connection.Client); err != nil {
+				log.Error(err)
+			}
+			continue
+		}
+
+		connection.Allocation = alloc
+		if alloc.Ipv6 == "" {
+			connection.Routes = ipv4Routes(connection.Routes)
+		}
+		s.connections[connection.Client] = connection
+		log.With("ip", alloc.Ip).Info("restored connection")
 	}
 
-	// remove 192.168.127.0, 192.168.127.1, and 192.168.127.255
-	rawIPs = rawIPs[2 : len(rawIPs)-1]
-	for _, allocationIP := range rawIPs {
-		s.allocations = append(s.allocations, pb.Allocation{Ip: allocationIP})
+	for ip, alloc := range s.allocations {
+		if _, ok := s.connections[alloc.Client]; ok {
+			continue
+		}
+		logger.With("ip", ip, "client", alloc.Client).Info("releasing stale allocation")
+		delete(s.allocations, ip)
+		if err := s.store.saveAllocation(&pb.Allocation{Ip: ip}); err != nil {
+			logger.With("ip", ip).Error(err)
+		}
 	}
+
+	metrics.ActiveClientTotal.Set(float64(len(s.connections)))
 }
 
-//  http://play.golang.org/p/m8TNTtygK0
-func (s *VPNServer) nextIP(ip net.IP) {
-	for j := len(ip) - 1; j >= 0; j-- {
-		ip[j]++
-		if ip[j] > 0 {
-			break
+// ipv4Routes drops the ipv6 routes of a connection restored after ipv6 was disabled.
+func ipv4Routes(routes []*pb.Route) []*pb.Route {
+	var ipv4 []*pb.Route
+	for _, route := range routes {
+		if ip, _, err := net.ParseCIDR(route.Cidr); err != nil || ip.To4() != nil {
+			ipv4 = append(ipv4, route)
 		}
 	}
+	return ipv4
+}
+
+// createClientConfig creates or truncates the client's client-config-dir file. It may hold the client's session
+// token, so only its owner may read it, also if an older version created it readable by everyone.
+func (s *VPNServer) createClientConfig(client string) (*os.File, error) {
+	f, err := os.OpenFile(s.ccd+"/"+client, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating openvpn config file")
+	}
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		return nil, errors.Wrap(err, "restricting openvpn config file")
+	}
+	return f, nil
+}
+
+// restoreClientConfig rewrites the client-config-dir file of a restored connection so that a reconnecting
+// client is handed the same address and routes it had before.
+func (s *VPNServer) restoreClientConfig(connection *pb.Connection) error {
+	ccdFile, err := s.createClientConfig(connection.Client)
+	if err != nil {
+		return err
+	}
+	defer ccdFile.Close()
+
+	for _, route := range connection.Routes {
+		_, network, err := net.ParseCIDR(route.Cidr)
+		if err != nil {
+			return errors.Wrapf(err, "parsing route %s", route.Cidr)
+		}
+		ccdFile.WriteString(pushRoute(network))
+	}
+	s.writeClientAddress(ccdFile, connection.Allocation)
+
+	return nil
+}
+
+// restoreConnections recreates the client config files, ipsets and iptables rules of restored connections.
+// This needs to happen after setupFirewall since that wipes all doorman rules.
+func (s *VPNServer) restoreConnections() {
+	s.mu.RLock()
+	connections := make([]*pb.Connection, 0, len(s.connections))
+	for _, connection := range s.connections {
+		connections = append(connections, connection)
+	}
+	s.mu.RUnlock()
+
+	for _, connection := range connections {
+		log := logger.With("client", connection.Client, "ip", connection.Allocation.Ip)
+
+		if err := s.restoreClientConfig(connection); err != nil {
+			log.Error(errors.WithMessage(err, "restore client config"))
+			metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+			s.forgetConnection(connection.Client)
+			metrics.ActiveClientTotal.Dec()
+			continue
+		}
+
+		if err := s.restoreFirewall(connection); err != nil {
+			log.Error(errors.WithMessage(err, "restore firewall"))
+			metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+
+			// tear down whatever was set up, the client will get a fresh allocation when it reconnects
+			s.removeIptables(connection.Client)
+			s.forgetConnection(connection.Client)
+			metrics.ActiveClientTotal.Dec()
+		}
+	}
+}
+
+// restoreFirewall recreates the firewall sets and rules of a restored connection.
+func (s *VPNServer) restoreFirewall(connection *pb.Connection) error {
+	if err := s.firewall.CreateSet(connection.Allocation); err != nil {
+		return err
+	}
+	for _, route := range connection.Routes {
+		// restoreClientConfig already made sure the routes parse
+		_, network, _ := net.ParseCIDR(route.Cidr)
+		if err := s.firewall.AddRoute(connection.Allocation, network); err != nil {
+			return err
+		}
+	}
+	return s.firewall.Enable(connection.Allocation)
 }
 
 func (s *VPNServer) Serve() {
@@ -779,9 +1566,15 @@ func (s *VPNServer) Serve() {
 	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGHUP)
 	ctx, cancel := context.WithCancel(context.Background())
 
-	s.populateIPAllocationPool()
+	s.restoreState()
 	s.setupFirewall()
+	s.restoreConnections()
+	s.writeOpenVPNPoolConfig()
 	ovpn := s.startOpenVPN(ctx)
+	go s.manageOpenVPN(ctx, doormanOpenVPNMgmt)
+	if s.reconcile > 0 {
+		go s.reconcileFirewallLoop(ctx, s.reconcile)
+	}
 
 	req := func(server *grpc.Server) {
 		pb.RegisterVPNServiceServer(server.Server(), s)
@@ -829,19 +1622,32 @@ func ServeVPN(l log.Logger) {
 		prometheusPort = ":9090"
 	}
 
-	apiHost := os.Getenv(doormanApiHost)
-	if apiHost == "" {
-		logger.Fatal(errors.New(doormanApiHost + " is empty"))
+	auth := os.Getenv(doormanAuth)
+	if auth == "" {
+		auth = authEquinix
 	}
-
-	sessions, err := url.Parse(apiHost + "/sessions")
-	if err != nil {
-		logger.Fatal(errors.Wrap(err, "parsing api sessions url"))
+	if !isAuthBackend(auth) {
+		logger.Fatal(errors.Errorf("unknown %s %q, expecting %s, %s, %s or %s", doormanAuth, auth, authEquinix, authLocal, authLDAP, authOIDC))
 	}
 
+	// the api is only needed to log users in to it
+	var api *url.URL
+	var err error
 	consumerToken := os.Getenv(doormanConsumerToken)
-	if consumerToken == "" {
-		logger.Fatal(errors.New(doormanConsumerToken + " is empty"))
+	if auth == authEquinix {
+		apiHost := os.Getenv(doormanApiHost)
+		if apiHost == "" {
+			logger.Fatal(errors.New(doormanApiHost + " is empty"))
+		}
+
+		api, err = url.Parse(strings.TrimSuffix(apiHost, "/") + "/")
+		if err != nil {
+			logger.Fatal(errors.Wrap(err, "parsing api url"))
+		}
+
+		if consumerToken == "" {
+			logger.Fatal(errors.New(doormanConsumerToken + " is empty"))
+		}
 	}
 
 	magicIP := os.Getenv(doormanMagicIP)
@@ -851,13 +1657,206 @@ func ServeVPN(l log.Logger) {
 		logger.Fatal(errors.New(doormanFacilityCode + " is empty"))
 	}
 
+	vpnPools := os.Getenv(doormanVPNPools)
+	if vpnPools == "" {
+		vpnPools = defaultVPNPools
+	}
+	pools, err := parseIPPools(vpnPools)
+	if err != nil {
+		logger.Fatal(errors.WithMessage(err, doormanVPNPools))
+	}
+
+	var pool6 *ipPool
+	if vpnIPv6Pool := os.Getenv(doormanVPNIPv6Pool); vpnIPv6Pool != "" {
+		pool6, err = parseIPv6Pool(vpnIPv6Pool)
+		if err != nil {
+			logger.Fatal(errors.WithMessage(err, doormanVPNIPv6Pool))
+		}
+	}
+
+	var stickyIPs bool
+	if sticky := os.Getenv(doormanStickyIPs); sticky != "" {
+		stickyIPs, err = strconv.ParseBool(sticky)
+		if err != nil {
+			logger.Fatal(errors.Wrap(err, doormanStickyIPs))
+		}
+	}
+
+	reconcile := defaultReconcileInterval
+	if interval := os.Getenv(doormanReconcile); interval != "" {
+		reconcile, err = time.ParseDuration(interval)
+		if err != nil {
+			logger.Fatal(errors.Wrap(err, doormanReconcile))
+		}
+	}
+
+	authTimeout := defaultAuthTimeout
+	if auth == authOIDC {
+		authTimeout = defaultOIDCAuthTimeout
+	}
+	if timeout := os.Getenv(doormanAuthTimeout); timeout != "" {
+		authTimeout, err = time.ParseDuration(timeout)
+		if err != nil {
+			logger.Fatal(errors.Wrap(err, doormanAuthTimeout))
+		}
+		if authTimeout <= 0 {
+			logger.Fatal(errors.New(doormanAuthTimeout + " needs to be positive"))
+		}
+	}
+
+	lockoutUserThreshold := defaultLockoutUserThreshold
+	if threshold := os.Getenv(doormanLockoutUser); threshold != "" {
+		lockoutUserThreshold, err = strconv.Atoi(threshold)
+		if err != nil {
+			logger.Fatal(errors.Wrap(err, doormanLockoutUser))
+		}
+	}
+	lockoutIPThreshold := defaultLockoutIPThreshold
+	if threshold := os.Getenv(doormanLockoutIP); threshold != "" {
+		lockoutIPThreshold, err = strconv.Atoi(threshold)
+		if err != nil {
+			logger.Fatal(errors.Wrap(err, doormanLockoutIP))
+		}
+	}
+	lockoutDuration := defaultLockoutDuration
+	if duration := os.Getenv(doormanLockoutDuration); duration != "" {
+		lockoutDuration, err = time.ParseDuration(duration)
+		if err != nil {
+			logger.Fatal(errors.Wrap(err, doormanLockoutDuration))
+		}
+	}
+	lockoutMax := defaultLockoutMax
+	if max := os.Getenv(doormanLockoutMax); max != "" {
+		lockoutMax, err = time.ParseDuration(max)
+		if err != nil {
+			logger.Fatal(errors.Wrap(err, doormanLockoutMax))
+		}
+	}
+	if lockoutDuration <= 0 || lockoutMax < lockoutDuration {
+		logger.Fatal(errors.Errorf("%s needs to be positive and no longer than %s", doormanLockoutDuration, doormanLockoutMax))
+	}
+
+	openvpnTmpDir := doormanOpenVPNTmpDir
+	if dir := os.Getenv(doormanOpenVPNTmpDirEnv); dir != "" {
+		if !filepath.IsAbs(dir) {
+			logger.Fatal(errors.Errorf("%s needs to be an absolute path", doormanOpenVPNTmpDirEnv))
+		}
+		openvpnTmpDir = filepath.Clean(dir)
+	}
+
+	subnetCacheTTL := defaultSubnetCacheTTL
+	if ttl := os.Getenv(doormanSubnetCacheTTL); ttl != "" {
+		subnetCacheTTL, err = time.ParseDuration(ttl)
+		if err != nil {
+			logger.Fatal(errors.Wrap(err, doormanSubnetCacheTTL))
+		}
+	}
+	// stale subnets are only pushed as long again as they are fresh, so a revoked project's subnets don't outlive 2 ttls
+	subnetCacheStale := subnetCacheTTL
+	if stale := os.Getenv(doormanSubnetCacheStale); stale != "" {
+		subnetCacheStale, err = time.ParseDuration(stale)
+		if err != nil {
+			logger.Fatal(errors.Wrap(err, doormanSubnetCacheStale))
+		}
+	}
+	if subnetCacheStale < 0 {
+		logger.Fatal(errors.Errorf("%s can't be negative", doormanSubnetCacheStale))
+	}
+
+	sessionLifetime := defaultSessionLifetime
+	if lifetime := os.Getenv(doormanSessionLifetime); lifetime != "" {
+		sessionLifetime, err = time.ParseDuration(lifetime)
+		if err != nil {
+			logger.Fatal(errors.Wrap(err, doormanSessionLifetime))
+		}
+	}
+
+	stateFile := os.Getenv(doormanStateFile)
+	if stateFile == "" {
+		stateFile = doormanStateDB
+	}
+
+	store, err := openStateStore(stateFile)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	defer store.Close()
+
 	server := &VPNServer{
-		magicIP:       magicIP,
-		facilityCode:  facilityCode,
-		apiHost:       apiHost,
-		sessions:      sessions,
-		consumerToken: consumerToken,
-		connections:   map[string]*pb.Connection{},
+		magicIP:         magicIP,
+		facilityCode:    facilityCode,
+		ccd:             doormanOpenVPNCCD,
+		tmpDir:          openvpnTmpDir,
+		consumerToken:   consumerToken,
+		store:           store,
+		pools:           pools,
+		pool6:           pool6,
+		stickyIPs:       stickyIPs,
+		reconcile:       reconcile,
+		authTimeout:     authTimeout,
+		lockouts:        newLockouts(lockoutUserThreshold, lockoutIPThreshold, lockoutDuration, lockoutMax),
+		serviceAccounts: newServiceAccounts(store, consumerToken, os.Getenv(doormanServiceAccountToken)),
+		breakGlass:      newBreakGlass(store),
+		allocations:     map[string]*pb.Allocation{},
+		pins:            map[string]*pb.Allocation{},
+		sticky:          map[string]string{},
+		clientIDs:       map[string]string{},
+		killed:          map[string]chan struct{}{},
+		connections:     map[string]*pb.Connection{},
+	}
+
+	switch auth {
+	case authEquinix:
+		server.authenticator = &equinixAuthenticator{api: newEquinixAPI(api, consumerToken)}
+	case authLocal:
+		server.localUsers = newLocalAuthenticator(store)
+		server.authenticator = server.localUsers
+	case authLDAP:
+		server.authenticator, err = ldapAuthenticatorFromEnv()
+		if err != nil {
+			logger.Fatal(err)
+		}
+	case authOIDC:
+		server.authenticator, err = oidcAuthenticatorFromEnv()
+		if err != nil {
+			logger.Fatal(err)
+		}
+	}
+
+	location := subnetLocation{facility: facilityCode, metro: strings.ToLower(os.Getenv(doormanMetro))}
+	var equinix SubnetProvider = &equinixSubnets{consumerToken: consumerToken, location: location}
+	if subnetCacheTTL > 0 {
+		server.subnetCache = newSubnetCache(equinix, subnetCacheTTL, subnetCacheStale)
+		equinix = server.subnetCache
+	}
+	subnets := compositeSubnets{identitySubnets{}, equinix}
+	// the testing environment has no api to fetch subnets from, it pushes those of DOORMAN_SUBNETS_FILE
+	if file := os.Getenv(doormanSubnetsFile); file != "" {
+		static, err := readStaticSubnets(file)
+		if err != nil {
+			logger.Fatal(errors.WithMessage(err, doormanSubnetsFile))
+		}
+		subnets = append(subnets, static)
+	}
+	server.subnetProvider = subnets
+
+	if sessionLifetime > 0 {
+		server.sessions, err = newSessionTokens(sessionLifetime)
+		if err != nil {
+			logger.Fatal(err)
+		}
+	}
+
+	var networks []*net.IPNet
+	for _, pool := range pools {
+		networks = append(networks, pool.network)
+	}
+	if pool6 != nil {
+		networks = append(networks, pool6.network)
+	}
+	server.firewall, err = newFirewall(os.Getenv(doormanFirewall), magicIP, networks, server.shellRun)
+	if err != nil {
+		logger.Fatal(errors.WithMessage(err, doormanFirewall))
 	}
 
 	go http.ListenAndServe(prometheusPort, nil)
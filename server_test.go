@@ -0,0 +1,291 @@
+package doorman
+
+import (
+	"bufio"
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/equinix/doorman/protobuf"
+	"github.com/packethost/pkg/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newTestServer returns a server with an in-memory firewall, pushing the routes users authenticated with and
+// writing client configs to a temporary directory.
+func newTestServer(t *testing.T) *VPNServer {
+	t.Helper()
+	pools, err := parseIPPools(defaultVPNPools)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := newSessionTokens(defaultSessionLifetime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := newTestStateStore(t)
+	return &VPNServer{
+		ccd:            t.TempDir(),
+		tmpDir:         t.TempDir(),
+		store:          store,
+		sessions:       sessions,
+		breakGlass:     newBreakGlass(store),
+		subnetProvider: compositeSubnets{identitySubnets{}},
+		firewall:       newFakeFirewall(),
+		pools:          pools,
+		authTimeout:    time.Minute,
+		allocations:    map[string]*pb.Allocation{},
+		pins:           map[string]*pb.Allocation{},
+		sticky:         map[string]string{},
+		connections:    map[string]*pb.Connection{},
+	}
+}
+
+func TestCheckClientOwner(t *testing.T) {
+	logger = log.Test(t, "doorman")
+
+	store := newTestStateStore(t)
+	s := &VPNServer{store: store}
+	if err := store.putClientOwner(&pb.ClientOwner{Client: "laptop", Owner: "Alice@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		client string
+		id     *identity
+		err    error
+	}{
+		{"laptop", &identity{user: "alice@example.com"}, nil},
+		{"laptop", &identity{user: "mallory@example.com"}, errClientOwnerMismatch},
+		// certificates without a recorded owner belong to the user their client is named after
+		{"1a2b3c", &identity{user: "alice@example.com", id: "1a2b3c"}, nil},
+		{"1a2b3c", &identity{user: "mallory@example.com", id: "4d5e6f"}, errClientOwnerMismatch},
+	} {
+		if err := s.checkClientOwner(logger, test.client, "192.0.2.1", test.id); err != test.err {
+			t.Fatalf("%s %+v: expected %v, got %v", test.client, test.id, test.err, err)
+		}
+	}
+}
+
+func TestCreateClientConfig(t *testing.T) {
+	s := &VPNServer{ccd: t.TempDir()}
+
+	// files of older versions were readable by everyone
+	path := filepath.Join(s.ccd, "client1")
+	if err := ioutil.WriteFile(path, []byte(`push "auth-token dst1.old"`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, client := range []string{"client1", "client2"} {
+		f, err := s.createClientConfig(client)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.WriteString(`push "auth-token dst1.new"` + "\n")
+		f.Close()
+
+		info, err := os.Stat(filepath.Join(s.ccd, client))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Fatalf("%s: expected mode 0600, got %v", client, info.Mode().Perm())
+		}
+		if b, _ := ioutil.ReadFile(filepath.Join(s.ccd, client)); string(b) != `push "auth-token dst1.new"`+"\n" {
+			t.Fatalf("%s: unexpected config %q", client, b)
+		}
+	}
+}
+
+func TestBreakGlassGetsNoSessionToken(t *testing.T) {
+	logger = log.Test(t, "doorman")
+
+	s := newTestServer(t)
+	ctx := context.Background()
+	_, once, err := s.breakGlass.create("laptop", []string{"10.88.111.0/25"}, time.Hour, 1, "api outage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bg, unlimited, err := s.breakGlass.create("laptop", []string{"10.88.111.0/25"}, time.Hour, 0, "api outage")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a token of an earlier login is ended by logging in with a break glass credential
+	earlier, err := s.sessions.issue("laptop", "alice", "alice", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, credential := range []string{once, unlimited} {
+		if err := s.authenticate(ctx, logger, "laptop", "192.0.2.1", "oncall", "000000"+credential); err != nil {
+			t.Fatal(err)
+		}
+		config, err := ioutil.ReadFile(filepath.Join(s.ccd, "laptop"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(config), "auth-token") {
+			t.Fatalf("expected no session token to be pushed, got:\n%s", config)
+		}
+		if err := s.disconnect("laptop"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.authenticate(ctx, logger, "laptop", "192.0.2.1", "alice", earlier); err != errInvalidSessionToken {
+		t.Fatalf("expected the earlier session token to be refused, got %v", err)
+	}
+
+	// used up and revoked credentials can't reconnect
+	if err := s.breakGlass.revoke(bg.Id); err != nil {
+		t.Fatal(err)
+	}
+	for _, credential := range []string{once, unlimited} {
+		if err := s.authenticate(ctx, logger, "laptop", "192.0.2.1", "oncall", "000000"+credential); err != errInvalidCredentials {
+			t.Fatalf("expected the credential to be refused, got %v", err)
+		}
+	}
+}
+
+func TestAuthenticateControlFile(t *testing.T) {
+	logger = log.Test(t, "doorman")
+
+	s := newTestServer(t)
+	_, credential, err := s.breakGlass.create("laptop", []string{"10.88.111.0/25"}, time.Hour, 0, "api outage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds := func(password string) string {
+		path := filepath.Join(t.TempDir(), "creds")
+		if err := ioutil.WriteFile(path, []byte("oncall\n"+password+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	controlFile := func(name string) string {
+		path := filepath.Join(s.tmpDir, name)
+		if err := ioutil.WriteFile(path, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	// decision waits for the deferred decision written to the control file
+	decision := func(path string) string {
+		t.Helper()
+		for i := 0; i < 100; i++ {
+			if b, _ := ioutil.ReadFile(path); len(b) != 0 {
+				return string(b)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("no decision written to %s", path)
+		return ""
+	}
+
+	for _, test := range []struct {
+		password string
+		want     string
+	}{
+		{"000000" + credential, "1"},
+		{"000000dbg1.0000000000000000.wrong", "0"},
+	} {
+		path := controlFile("openvpn_acf_0123456789abcdef.tmp")
+		resp, err := s.Authenticate(context.Background(), &pb.AuthenticateRequest{Client: "laptop", File: creds(test.password), ControlFile: path})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Status != authDeferred {
+			t.Fatalf("expected the decision to be deferred, got status %d", resp.Status)
+		}
+		if got := decision(path); got != test.want {
+			t.Fatalf("expected %q written, got %q", test.want, got)
+		}
+	}
+
+	outside := filepath.Join(t.TempDir(), "openvpn_acf_0123456789abcdef.tmp")
+	for _, path := range []string{outside, controlFile("passwd"), "openvpn_acf_0123456789abcdef.tmp", filepath.Join(s.tmpDir, "..", "openvpn_acf_0123456789abcdef.tmp")} {
+		if _, err := s.Authenticate(context.Background(), &pb.AuthenticateRequest{Client: "laptop", File: creds("000000" + credential), ControlFile: path}); err == nil {
+			t.Fatalf("expected control file %s to be refused", path)
+		}
+	}
+}
+
+// fixedAuthenticator authenticates anyone with its password as its identity.
+type fixedAuthenticator struct {
+	password string
+	id       *identity
+}
+
+func (a *fixedAuthenticator) Authenticate(ctx context.Context, login, password, otp string) (*identity, error) {
+	if password != a.password {
+		return nil, errInvalidCredentials
+	}
+	id := *a.id
+	return &id, nil
+}
+
+func TestClientOwnerMismatchCountsAsFailure(t *testing.T) {
+	logger = log.Test(t, "doorman")
+
+	s := newTestServer(t)
+	s.authenticator = &fixedAuthenticator{password: "secret", id: &identity{user: "mallory", backend: authLocal}}
+	s.lockouts = newLockouts(2, 0, time.Minute, time.Hour)
+	if err := s.store.putClientOwner(&pb.ClientOwner{Client: "laptop", Owner: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if err := s.authenticate(ctx, logger, "laptop", "192.0.2.1", "mallory", "123456wrong"); err != errInvalidCredentials {
+		t.Fatalf("expected invalid credentials, got %v", err)
+	}
+	// valid credentials with someone else's certificate don't reset the failures, they add to them
+	if err := s.authenticate(ctx, logger, "laptop", "192.0.2.1", "Mallory", "123456secret"); err != errClientOwnerMismatch {
+		t.Fatalf("expected an owner mismatch, got %v", err)
+	}
+	if err := s.lockouts.check("mallory", ""); err != errLockedOut {
+		t.Fatalf("expected the user to be locked out, got %v", err)
+	}
+}
+
+func TestDisconnectStaleConnection(t *testing.T) {
+	logger = log.Test(t, "doorman")
+
+	s := newTestServer(t)
+	s.authenticator = &fixedAuthenticator{password: "secret", id: &identity{user: "laptop", backend: authLocal, routes: []string{"10.88.111.0/25"}}}
+	s.clientIDs = map[string]string{}
+	s.killed = map[string]chan struct{}{}
+	if err := s.authenticate(context.Background(), logger, "laptop", "192.0.2.1", "laptop", "123456secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	// openvpn doesn't know the client anymore, e.g. as it never came back after a restart
+	server, conn := net.Pipe()
+	defer server.Close()
+	s.management = &managementClient{conn: conn, handler: s.handleClientEvent, replies: make(chan string, 1)}
+	defer s.management.Close()
+	go s.management.run()
+	go func() {
+		scanner := bufio.NewScanner(server)
+		for scanner.Scan() {
+			server.Write([]byte("ERROR: common name 'laptop' not found\n"))
+		}
+	}()
+
+	if _, err := s.Disconnect(context.Background(), &pb.DisconnectRequest{Client: "laptop"}); err != nil {
+		t.Fatal(err)
+	}
+	s.mu.RLock()
+	_, connected := s.connections["laptop"]
+	s.mu.RUnlock()
+	if connected {
+		t.Fatal("expected the stale connection to be released")
+	}
+
+	if _, err := s.Disconnect(context.Background(), &pb.DisconnectRequest{Client: "laptop"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected an unknown client not to be found, got %v", err)
+	}
+}
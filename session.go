@@ -0,0 +1,144 @@
+package doorman
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultSessionLifetime = 12 * time.Hour
+	sessionTokenPrefix     = "dst1."
+	sessionKeyLength       = 32
+	sessionIDLength        = 16
+)
+
+var errInvalidSessionToken = errors.New("invalid or expired session token")
+
+// session is what a client last authenticated as, so it can come back with its session token instead of a new
+// twofactor token when the tunnel is renegotiated or reconnects.
+type session struct {
+	id      string
+	login   string
+	user    string
+	routes  []string // routes the client was set up with, so they need not be looked up again
+	expires time.Time
+}
+
+// sessionClaims are signed into a session token.
+type sessionClaims struct {
+	Client  string `json:"c"`
+	ID      string `json:"s"`
+	Expires int64  `json:"e"`
+}
+
+// sessionTokens issues the tokens pushed to clients with auth-token, and checks the ones they come back with.
+// A token is only valid for the client's current session, so ending the session invalidates it.
+// The signing key is generated at startup, restarting doorman ends all sessions.
+type sessionTokens struct {
+	key      []byte
+	lifetime time.Duration
+	now      func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]*session // client -> its current session
+}
+
+func newSessionTokens(lifetime time.Duration) (*sessionTokens, error) {
+	key := make([]byte, sessionKeyLength)
+	if _, err := rand.Read(key); err != nil {
+		return nil, errors.Wrap(err, "generate session token key")
+	}
+	return &sessionTokens{
+		key:      key,
+		lifetime: lifetime,
+		now:      time.Now,
+		sessions: map[string]*session{},
+	}, nil
+}
+
+// isSessionToken reports whether the password a client sent is a session token.
+func isSessionToken(password string) bool {
+	return strings.HasPrefix(password, sessionTokenPrefix)
+}
+
+// issue starts a new session for the client, replacing its previous one, and returns the session's token.
+func (t *sessionTokens) issue(client, login, user string, subnets []*net.IPNet) (string, error) {
+	id := make([]byte, sessionIDLength)
+	if _, err := rand.Read(id); err != nil {
+		return "", errors.Wrap(err, "generate session id")
+	}
+
+	s := &session{
+		id:      base64.RawURLEncoding.EncodeToString(id),
+		login:   login,
+		user:    user,
+		expires: t.now().Add(t.lifetime),
+	}
+	for _, subnet := range subnets {
+		s.routes = append(s.routes, subnet.String())
+	}
+
+	payload, err := json.Marshal(&sessionClaims{Client: client, ID: s.id, Expires: s.expires.Unix()})
+	if err != nil {
+		return "", errors.Wrap(err, "encode session token")
+	}
+	token := sessionTokenPrefix + base64.RawURLEncoding.EncodeToString(payload)
+	token += "." + base64.RawURLEncoding.EncodeToString(t.sign(token))
+
+	t.mu.Lock()
+	t.sessions[client] = s
+	t.mu.Unlock()
+	return token, nil
+}
+
+// verify returns the session the token belongs to, if it is the client's current session, the login matches and
+// it has not expired.
+func (t *sessionTokens) verify(client, login, token string) (*session, error) {
+	i := strings.LastIndex(token, ".")
+	if !isSessionToken(token) || i < len(sessionTokenPrefix) {
+		return nil, errInvalidSessionToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(token[i+1:])
+	if err != nil || !hmac.Equal(signature, t.sign(token[:i])) {
+		return nil, errInvalidSessionToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(token[len(sessionTokenPrefix):i])
+	if err != nil {
+		return nil, errInvalidSessionToken
+	}
+	claims := &sessionClaims{}
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil, errInvalidSessionToken
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.sessions[client]
+	if claims.Client != client || !ok || s.id != claims.ID || s.login != login || !t.now().Before(s.expires) {
+		return nil, errInvalidSessionToken
+	}
+	return s, nil
+}
+
+// end invalidates the client's session token.
+func (t *sessionTokens) end(client string) {
+	t.mu.Lock()
+	delete(t.sessions, client)
+	t.mu.Unlock()
+}
+
+func (t *sessionTokens) sign(s string) []byte {
+	mac := hmac.New(sha256.New, t.key)
+	mac.Write([]byte(s))
+	return mac.Sum(nil)
+}
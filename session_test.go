@@ -0,0 +1,81 @@
+package doorman
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSessionTokens(t *testing.T) {
+	tokens, err := newSessionTokens(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1600000000, 0)
+	tokens.now = func() time.Time { return now }
+
+	subnets, err := parseRoutes([]string{"10.88.111.0/25", "fd00:8a0:1::/56"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := tokens.issue("client1", "alice@example.com", "alice", subnets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isSessionToken(token) || isSessionToken("123456password") {
+		t.Fatalf("expected %s to be told apart from passwords", token)
+	}
+
+	session, err := tokens.verify("client1", "alice@example.com", token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.user != "alice" || !reflect.DeepEqual(session.routes, []string{"10.88.111.0/25", "fd00:8a0:1::/56"}) {
+		t.Fatalf("unexpected session %+v", session)
+	}
+
+	other, err := newSessionTokens(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.sessions = tokens.sessions
+	tampered := strings.Replace(token, ".", ".x", 1)
+	for _, test := range []struct {
+		tokens               *sessionTokens
+		client, login, token string
+	}{
+		{tokens, "client2", "alice@example.com", token},
+		{tokens, "client1", "mallory@example.com", token},
+		{tokens, "client1", "alice@example.com", tampered},
+		{tokens, "client1", "alice@example.com", token[:len(token)-1]},
+		{tokens, "client1", "alice@example.com", sessionTokenPrefix},
+		{other, "client1", "alice@example.com", token},
+	} {
+		if _, err := test.tokens.verify(test.client, test.login, test.token); err != errInvalidSessionToken {
+			t.Fatalf("%s %s %s: expected an invalid token, got %v", test.client, test.login, test.token, err)
+		}
+	}
+
+	// logging in again replaces the session
+	newer, err := tokens.issue("client1", "alice@example.com", "alice", subnets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tokens.verify("client1", "alice@example.com", token); err != errInvalidSessionToken {
+		t.Fatalf("expected the replaced token to be invalid, got %v", err)
+	}
+	if _, err := tokens.verify("client1", "alice@example.com", newer); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(time.Hour)
+	if _, err := tokens.verify("client1", "alice@example.com", newer); err != errInvalidSessionToken {
+		t.Fatalf("expected the expired token to be invalid, got %v", err)
+	}
+	now = now.Add(-time.Minute)
+	tokens.end("client1")
+	if _, err := tokens.verify("client1", "alice@example.com", newer); err != errInvalidSessionToken {
+		t.Fatalf("expected the ended session's token to be invalid, got %v", err)
+	}
+}
@@ -0,0 +1,277 @@
+package doorman
+
+import (
+	"time"
+
+	pb "github.com/equinix/doorman/protobuf"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	connectionsBucket     = []byte("connections")
+	allocationsBucket     = []byte("allocations")
+	pinsBucket            = []byte("pins")
+	stickyBucket          = []byte("sticky")
+	localUsersBucket      = []byte("local-users")
+	clientOwnersBucket    = []byte("client-owners")
+	serviceAccountsBucket = []byte("service-accounts")
+	breakGlassBucket      = []byte("break-glass")
+)
+
+// stateStore keeps doorman's view of connected clients on disk so that it survives a restart.
+// Connections, pins, client owners and service accounts are keyed by client, allocations and sticky addresses by ip address,
+// local users by name and break glass credentials by id.
+type stateStore struct {
+	db *bolt.DB
+}
+
+func openStateStore(path string) (*stateStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, errors.Wrap(err, "open state store")
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{connectionsBucket, allocationsBucket, pinsBucket, stickyBucket, localUsersBucket, clientOwnersBucket, serviceAccountsBucket, breakGlassBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return errors.Wrapf(err, "create bucket %s", bucket)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &stateStore{db: db}, nil
+}
+
+func (st *stateStore) Close() error {
+	return st.db.Close()
+}
+
+func (st *stateStore) put(bucket []byte, key string, msg proto.Message) error {
+	value, err := proto.Marshal(msg)
+	if err != nil {
+		return errors.Wrapf(err, "marshal %s", key)
+	}
+	return st.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Put([]byte(key), value)
+	})
+}
+
+func (st *stateStore) delete(bucket []byte, key string) error {
+	return st.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Delete([]byte(key))
+	})
+}
+
+func (st *stateStore) forEach(bucket []byte, fn func(key, value []byte) error) error {
+	return st.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).ForEach(fn)
+	})
+}
+
+func (st *stateStore) putConnection(connection *pb.Connection) error {
+	return errors.WithMessage(st.put(connectionsBucket, connection.Client, connection), "store connection")
+}
+
+func (st *stateStore) deleteConnection(client string) error {
+	return errors.WithMessage(st.delete(connectionsBucket, client), "delete connection")
+}
+
+func (st *stateStore) connections() ([]*pb.Connection, error) {
+	var connections []*pb.Connection
+	err := st.forEach(connectionsBucket, func(key, value []byte) error {
+		connection := &pb.Connection{}
+		if err := proto.Unmarshal(value, connection); err != nil {
+			return errors.Wrapf(err, "unmarshal connection %s", key)
+		}
+		connections = append(connections, connection)
+		return nil
+	})
+	return connections, errors.WithMessage(err, "load connections")
+}
+
+// saveAllocation records the allocation if it is assigned to a client and forgets it otherwise.
+func (st *stateStore) saveAllocation(allocation *pb.Allocation) error {
+	if allocation.Client == "" {
+		return errors.WithMessage(st.delete(allocationsBucket, allocation.Ip), "delete allocation")
+	}
+	return errors.WithMessage(st.put(allocationsBucket, allocation.Ip, allocation), "store allocation")
+}
+
+func (st *stateStore) allocations() ([]*pb.Allocation, error) {
+	allocations, err := st.loadAllocations(allocationsBucket)
+	return allocations, errors.WithMessage(err, "load allocations")
+}
+
+func (st *stateStore) putPin(pin *pb.Allocation) error {
+	return errors.WithMessage(st.put(pinsBucket, pin.Client, pin), "store pin")
+}
+
+func (st *stateStore) deletePin(client string) error {
+	return errors.WithMessage(st.delete(pinsBucket, client), "delete pin")
+}
+
+func (st *stateStore) pins() ([]*pb.Allocation, error) {
+	pins, err := st.loadAllocations(pinsBucket)
+	return pins, errors.WithMessage(err, "load pins")
+}
+
+// putSticky remembers the client an address was last allocated to.
+func (st *stateStore) putSticky(allocation *pb.Allocation) error {
+	return errors.WithMessage(st.put(stickyBucket, allocation.Ip, allocation), "store sticky address")
+}
+
+func (st *stateStore) deleteSticky(ip string) error {
+	return errors.WithMessage(st.delete(stickyBucket, ip), "delete sticky address")
+}
+
+func (st *stateStore) sticky() ([]*pb.Allocation, error) {
+	sticky, err := st.loadAllocations(stickyBucket)
+	return sticky, errors.WithMessage(err, "load sticky addresses")
+}
+
+func (st *stateStore) loadAllocations(bucket []byte) ([]*pb.Allocation, error) {
+	var allocations []*pb.Allocation
+	err := st.forEach(bucket, func(key, value []byte) error {
+		allocation := &pb.Allocation{}
+		if err := proto.Unmarshal(value, allocation); err != nil {
+			return errors.Wrapf(err, "unmarshal allocation %s", key)
+		}
+		allocations = append(allocations, allocation)
+		return nil
+	})
+	return allocations, err
+}
+
+func (st *stateStore) putLocalUser(user *pb.LocalUser) error {
+	return errors.WithMessage(st.put(localUsersBucket, user.User, user), "store local user")
+}
+
+func (st *stateStore) deleteLocalUser(name string) error {
+	return errors.WithMessage(st.delete(localUsersBucket, name), "delete local user")
+}
+
+// localUser returns the local user called name, or nil if there is none.
+func (st *stateStore) localUser(name string) (*pb.LocalUser, error) {
+	var user *pb.LocalUser
+	err := st.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(localUsersBucket).Get([]byte(name))
+		if value == nil {
+			return nil
+		}
+		user = &pb.LocalUser{}
+		return errors.Wrapf(proto.Unmarshal(value, user), "unmarshal local user %s", name)
+	})
+	return user, errors.WithMessage(err, "load local user")
+}
+
+func (st *stateStore) localUsers() ([]*pb.LocalUser, error) {
+	var users []*pb.LocalUser
+	err := st.forEach(localUsersBucket, func(key, value []byte) error {
+		user := &pb.LocalUser{}
+		if err := proto.Unmarshal(value, user); err != nil {
+			return errors.Wrapf(err, "unmarshal local user %s", key)
+		}
+		users = append(users, user)
+		return nil
+	})
+	return users, errors.WithMessage(err, "load local users")
+}
+
+func (st *stateStore) putClientOwner(owner *pb.ClientOwner) error {
+	return errors.WithMessage(st.put(clientOwnersBucket, owner.Client, owner), "store client owner")
+}
+
+func (st *stateStore) deleteClientOwner(client string) error {
+	return errors.WithMessage(st.delete(clientOwnersBucket, client), "delete client owner")
+}
+
+// clientOwner returns who the client's certificate was issued to, or nil if that was not recorded.
+func (st *stateStore) clientOwner(client string) (*pb.ClientOwner, error) {
+	var owner *pb.ClientOwner
+	err := st.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(clientOwnersBucket).Get([]byte(client))
+		if value == nil {
+			return nil
+		}
+		owner = &pb.ClientOwner{}
+		return errors.Wrapf(proto.Unmarshal(value, owner), "unmarshal client owner %s", client)
+	})
+	return owner, errors.WithMessage(err, "load client owner")
+}
+
+func (st *stateStore) putServiceAccount(account *pb.ServiceAccount) error {
+	return errors.WithMessage(st.put(serviceAccountsBucket, account.Client, account), "store service account")
+}
+
+func (st *stateStore) deleteServiceAccount(client string) error {
+	return errors.WithMessage(st.delete(serviceAccountsBucket, client), "delete service account")
+}
+
+// serviceAccount returns the service account bound to the client, or nil if there is none.
+func (st *stateStore) serviceAccount(client string) (*pb.ServiceAccount, error) {
+	var account *pb.ServiceAccount
+	err := st.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(serviceAccountsBucket).Get([]byte(client))
+		if value == nil {
+			return nil
+		}
+		account = &pb.ServiceAccount{}
+		return errors.Wrapf(proto.Unmarshal(value, account), "unmarshal service account %s", client)
+	})
+	return account, errors.WithMessage(err, "load service account")
+}
+
+func (st *stateStore) serviceAccounts() ([]*pb.ServiceAccount, error) {
+	var accounts []*pb.ServiceAccount
+	err := st.forEach(serviceAccountsBucket, func(key, value []byte) error {
+		account := &pb.ServiceAccount{}
+		if err := proto.Unmarshal(value, account); err != nil {
+			return errors.Wrapf(err, "unmarshal service account %s", key)
+		}
+		accounts = append(accounts, account)
+		return nil
+	})
+	return accounts, errors.WithMessage(err, "load service accounts")
+}
+
+func (st *stateStore) putBreakGlass(credential *pb.BreakGlass) error {
+	return errors.WithMessage(st.put(breakGlassBucket, credential.Id, credential), "store break glass credential")
+}
+
+func (st *stateStore) deleteBreakGlass(id string) error {
+	return errors.WithMessage(st.delete(breakGlassBucket, id), "delete break glass credential")
+}
+
+// breakGlass returns the break glass credential with the id, or nil if there is none.
+func (st *stateStore) breakGlass(id string) (*pb.BreakGlass, error) {
+	var credential *pb.BreakGlass
+	err := st.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(breakGlassBucket).Get([]byte(id))
+		if value == nil {
+			return nil
+		}
+		credential = &pb.BreakGlass{}
+		return errors.Wrapf(proto.Unmarshal(value, credential), "unmarshal break glass credential %s", id)
+	})
+	return credential, errors.WithMessage(err, "load break glass credential")
+}
+
+func (st *stateStore) breakGlassCredentials() ([]*pb.BreakGlass, error) {
+	var credentials []*pb.BreakGlass
+	err := st.forEach(breakGlassBucket, func(key, value []byte) error {
+		credential := &pb.BreakGlass{}
+		if err := proto.Unmarshal(value, credential); err != nil {
+			return errors.Wrapf(err, "unmarshal break glass credential %s", key)
+		}
+		credentials = append(credentials, credential)
+		return nil
+	})
+	return credentials, errors.WithMessage(err, "load break glass credentials")
+}
@@ -0,0 +1,74 @@
+package doorman
+
+import (
+	"path/filepath"
+	"testing"
+
+	pb "github.com/equinix/doorman/protobuf"
+)
+
+func TestStateStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	store, err := openStateStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	allocation := &pb.Allocation{Client: "client1", Ip: "192.168.127.2"}
+	if err := store.saveAllocation(allocation); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.saveAllocation(&pb.Allocation{Client: "client2", Ip: "192.168.127.3"}); err != nil {
+		t.Fatal(err)
+	}
+	// freeing an allocation removes it from the store
+	if err := store.saveAllocation(&pb.Allocation{Ip: "192.168.127.3"}); err != nil {
+		t.Fatal(err)
+	}
+
+	connection := &pb.Connection{
+		Client:       "client1",
+		Username:     "user@example.com",
+		Allocation:   allocation,
+		Routes:       []*pb.Route{{Cidr: "10.88.111.0/25"}},
+		Since:        1467215404,
+		ConnectingIp: "24.255.233.90",
+	}
+	if err := store.putConnection(connection); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.putConnection(&pb.Connection{Client: "client2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.deleteConnection("client2"); err != nil {
+		t.Fatal(err)
+	}
+
+	// make sure everything actually made it to disk
+	store.Close()
+	store, err = openStateStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	allocations, err := store.allocations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(allocations) != 1 || allocations[0].Ip != "192.168.127.2" || allocations[0].Client != "client1" {
+		t.Fatalf("unexpected allocations: %v", allocations)
+	}
+
+	connections, err := store.connections()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(connections) != 1 {
+		t.Fatalf("expecting 1 connection, got: %d", len(connections))
+	}
+	got := connections[0]
+	if got.Client != "client1" || got.Allocation.Ip != "192.168.127.2" || len(got.Routes) != 1 || got.Routes[0].Cidr != "10.88.111.0/25" {
+		t.Fatalf("unexpected connection: %v", got)
+	}
+}
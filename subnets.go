@@ -0,0 +1,63 @@
+package doorman
+
+import (
+	"context"
+	"net"
+
+	"github.com/pkg/errors"
+)
+
+var errNoSubnets = errors.New("no backend routes to push")
+
+// SubnetProvider returns the private subnets an authenticated user may reach, which are pushed to the user's client.
+type SubnetProvider interface {
+	Subnets(ctx context.Context, id *identity) ([]*net.IPNet, error)
+}
+
+// identitySubnets returns the routes the user was authenticated with, e.g. a local user's, the routes mapped to
+// an ldap or oidc user's groups, or a session's.
+type identitySubnets struct{}
+
+func (identitySubnets) Subnets(ctx context.Context, id *identity) ([]*net.IPNet, error) {
+	return parseRoutes(id.routes)
+}
+
+// compositeSubnets merges the subnets of its providers, dropping duplicates. It fails if any of them fails, or if
+// there are no subnets at all.
+type compositeSubnets []SubnetProvider
+
+func (c compositeSubnets) Subnets(ctx context.Context, id *identity) ([]*net.IPNet, error) {
+	var subnets []*net.IPNet
+	seen := map[string]bool{}
+	for _, provider := range c {
+		provided, err := provider.Subnets(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		for _, subnet := range provided {
+			if seen[subnet.String()] {
+				continue
+			}
+			seen[subnet.String()] = true
+			subnets = append(subnets, subnet)
+		}
+	}
+
+	if len(subnets) == 0 {
+		return nil, errNoSubnets
+	}
+	return subnets, nil
+}
+
+// parseRoutes parses CIDRs into the networks they contain.
+func parseRoutes(routes []string) ([]*net.IPNet, error) {
+	subnets := make([]*net.IPNet, 0, len(routes))
+	for _, route := range routes {
+		_, network, err := net.ParseCIDR(route)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing route %s", route)
+		}
+		subnets = append(subnets, network)
+	}
+	return subnets, nil
+}
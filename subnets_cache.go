@@ -0,0 +1,184 @@
+package doorman
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/equinix/doorman/metrics"
+)
+
+const (
+	defaultSubnetCacheTTL = 5 * time.Minute
+
+	subnetCacheHit   = "hit"
+	subnetCacheStale = "stale"
+	subnetCacheMiss  = "miss"
+
+	subnetFetchTimeout = time.Minute // fetches outlive the login that started them
+)
+
+// cachedSubnet is the subnets of one user, and who they were fetched for.
+type cachedSubnet struct {
+	id      identity // of the latest login, refreshes are fetched with its token
+	subnets []*net.IPNet
+	fetched time.Time
+}
+
+// subnetFetch is a fetch in progress, logins of the same user wait for it instead of fetching again.
+type subnetFetch struct {
+	id      identity
+	done    chan struct{}
+	subnets []*net.IPNet
+	err     error
+}
+
+// subnetCache caches the subnets of a provider per user, so reconnecting users don't list all of their projects
+// again. Subnets are fresh for ttl, for stale longer they are still returned while being refetched in the background,
+// after that the user waits for them to be fetched again. Failed fetches are not cached.
+type subnetCache struct {
+	provider SubnetProvider
+	ttl      time.Duration
+	stale    time.Duration
+	now      func() time.Time
+
+	mu       sync.Mutex
+	entries  map[string]*cachedSubnet
+	fetching map[string]*subnetFetch
+	pruned   time.Time
+}
+
+func newSubnetCache(provider SubnetProvider, ttl, stale time.Duration) *subnetCache {
+	return &subnetCache{
+		provider: provider,
+		ttl:      ttl,
+		stale:    stale,
+		now:      time.Now,
+		entries:  map[string]*cachedSubnet{},
+		fetching: map[string]*subnetFetch{},
+	}
+}
+
+// subnetCacheKey is the authenticated user's id, its name if it has none, and the projects it is restricted to.
+func subnetCacheKey(id *identity) string {
+	key := id.id
+	if key == "" {
+		key = strings.ToLower(id.user)
+	}
+	if len(id.projects) != 0 {
+		key += "/" + strings.Join(id.projects, ",")
+	}
+	return key
+}
+
+func (c *subnetCache) Subnets(ctx context.Context, id *identity) ([]*net.IPNet, error) {
+	key := subnetCacheKey(id)
+
+	c.mu.Lock()
+	if entry, ok := c.entries[key]; ok {
+		age := c.now().Sub(entry.fetched)
+		if age < c.ttl {
+			c.mu.Unlock()
+			metrics.SubnetCacheTotal.WithLabelValues(subnetCacheHit).Inc()
+			return entry.subnets, nil
+		}
+		if age < c.ttl+c.stale {
+			entry.id = *id
+			if _, ok := c.fetching[key]; !ok {
+				c.refresh(key, id)
+			}
+			c.mu.Unlock()
+			metrics.SubnetCacheTotal.WithLabelValues(subnetCacheStale).Inc()
+			return entry.subnets, nil
+		}
+		delete(c.entries, key)
+	}
+	fetch, ok := c.fetching[key]
+	if !ok {
+		fetch = c.start(key, id)
+	}
+	c.mu.Unlock()
+	metrics.SubnetCacheTotal.WithLabelValues(subnetCacheMiss).Inc()
+
+	select {
+	case <-fetch.done:
+		return fetch.subnets, fetch.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// refresh refetches the user's stale subnets in the background. The caller needs to hold mu.
+func (c *subnetCache) refresh(key string, id *identity) {
+	fetch := c.start(key, id)
+	go func() {
+		<-fetch.done
+		if fetch.err != nil {
+			logger.With("user", id.user, "error", fetch.err).Info("failed refreshing cached subnets, keeping the stale ones")
+		}
+	}()
+}
+
+// start fetches the user's subnets and caches them, unless the user was invalidated meanwhile. The caller needs to
+// hold mu.
+func (c *subnetCache) start(key string, id *identity) *subnetFetch {
+	fetch := &subnetFetch{id: *id, done: make(chan struct{})}
+	c.fetching[key] = fetch
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), subnetFetchTimeout)
+		defer cancel()
+		start := time.Now()
+		fetch.subnets, fetch.err = c.provider.Subnets(ctx, &fetch.id)
+		metrics.SubnetFetchDuration.Observe(time.Since(start).Seconds())
+
+		c.mu.Lock()
+		if c.fetching[key] == fetch {
+			delete(c.fetching, key)
+			if fetch.err == nil {
+				c.entries[key] = &cachedSubnet{id: fetch.id, subnets: fetch.subnets, fetched: c.now()}
+				c.prune()
+			}
+		}
+		c.mu.Unlock()
+		close(fetch.done)
+	}()
+	return fetch
+}
+
+// prune drops entries too old to be returned, at most once per ttl. The caller needs to hold mu.
+func (c *subnetCache) prune() {
+	now := c.now()
+	if now.Sub(c.pruned) < c.ttl {
+		return
+	}
+	c.pruned = now
+	for key, entry := range c.entries {
+		if now.Sub(entry.fetched) >= c.ttl+c.stale {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// invalidate drops the cached subnets of the user, matched by name or id, or of everyone if user is empty, and
+// returns how many entries were dropped. Fetches in progress for them are not cached.
+func (c *subnetCache) invalidate(user string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	invalidated := 0
+	for key, entry := range c.entries {
+		if user == "" || entry.id.owns(user) {
+			delete(c.entries, key)
+			invalidated++
+		}
+	}
+	for key, fetch := range c.fetching {
+		if user == "" || fetch.id.owns(user) {
+			delete(c.fetching, key)
+		}
+	}
+	return invalidated
+}
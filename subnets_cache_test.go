@@ -0,0 +1,145 @@
+package doorman
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/equinix/doorman/protobuf"
+	"github.com/packethost/pkg/log"
+)
+
+// countingSubnets provides one subnet per fetch, 10.0.<fetch>.0/24, and fails while fail is set.
+type countingSubnets struct {
+	mu      sync.Mutex
+	fetches int
+	fail    bool
+}
+
+func (c *countingSubnets) Subnets(ctx context.Context, id *identity) ([]*net.IPNet, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fetches++
+	if c.fail {
+		return nil, errors.New("api down")
+	}
+	return []*net.IPNet{{IP: net.IPv4(10, 0, byte(c.fetches), 0).To4(), Mask: net.CIDRMask(24, 32)}}, nil
+}
+
+func (c *countingSubnets) setFail(fail bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fail = fail
+}
+
+func (c *countingSubnets) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.fetches
+}
+
+func TestSubnetCache(t *testing.T) {
+	logger = log.Test(t, "doorman")
+
+	provider := &countingSubnets{}
+	cache := newSubnetCache(provider, time.Minute, time.Hour)
+	now := time.Unix(1600000000, 0)
+	cache.now = func() time.Time { return now }
+	advance := func(d time.Duration) {
+		cache.mu.Lock()
+		defer cache.mu.Unlock()
+		now = now.Add(d)
+	}
+	alice := &identity{user: "alice@example.com", id: "5f2a", token: "t1"}
+
+	expect := func(id *identity, want string) {
+		t.Helper()
+		subnets, err := cache.Subnets(context.Background(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := subnetStrings(subnets); len(got) != 1 || got[0] != want {
+			t.Fatalf("expected %s, got %v", want, got)
+		}
+	}
+	// wait lets a background refresh finish
+	wait := func() {
+		t.Helper()
+		for i := 0; i < 100; i++ {
+			cache.mu.Lock()
+			n := len(cache.fetching)
+			cache.mu.Unlock()
+			if n == 0 {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatal("refresh did not finish")
+	}
+
+	expect(alice, "10.0.1.0/24")
+	expect(&identity{user: "Alice@example.com", id: "5f2a", token: "t2"}, "10.0.1.0/24")
+	if provider.count() != 1 {
+		t.Fatalf("expected 1 fetch, got %d", provider.count())
+	}
+
+	// stale subnets are returned while they are refreshed
+	advance(2 * time.Minute)
+	expect(alice, "10.0.1.0/24")
+	wait()
+	expect(alice, "10.0.2.0/24")
+
+	// failed refreshes keep the stale subnets, and are retried
+	provider.setFail(true)
+	advance(2 * time.Minute)
+	expect(alice, "10.0.2.0/24")
+	wait()
+	expect(alice, "10.0.2.0/24")
+	wait()
+	provider.setFail(false)
+
+	// expired subnets are fetched before returning
+	advance(2 * time.Hour)
+	expect(alice, "10.0.5.0/24")
+
+	if n := cache.invalidate("bob"); n != 0 {
+		t.Fatalf("expected nothing invalidated, got %d", n)
+	}
+	if n := cache.invalidate("ALICE@example.com"); n != 1 {
+		t.Fatalf("expected 1 invalidated, got %d", n)
+	}
+	expect(alice, "10.0.6.0/24")
+	if n := cache.invalidate("5f2a"); n != 1 {
+		t.Fatalf("expected 1 invalidated by id, got %d", n)
+	}
+
+	// failures are not cached
+	provider.setFail(true)
+	if _, err := cache.Subnets(context.Background(), alice); err == nil {
+		t.Fatal("expected the fetch to fail")
+	}
+	provider.setFail(false)
+	expect(alice, "10.0.8.0/24")
+	if n := cache.invalidate(""); n != 1 {
+		t.Fatalf("expected everything invalidated, got %d", n)
+	}
+}
+
+func TestInvalidateSubnetsWithoutCache(t *testing.T) {
+	logger = log.Test(t, "doorman")
+
+	s := &VPNServer{}
+	resp, err := s.InvalidateSubnets(context.Background(), &pb.InvalidateSubnetsRequest{All: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Invalidated != 0 {
+		t.Fatalf("expected nothing invalidated, got %d", resp.Invalidated)
+	}
+	if _, err := s.InvalidateSubnets(context.Background(), &pb.InvalidateSubnetsRequest{}); err == nil {
+		t.Fatal("expected a request without user to fail")
+	}
+}
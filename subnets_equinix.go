@@ -0,0 +1,409 @@
+package doorman
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/equinix/doorman/metrics"
+	"github.com/packethost/packngo"
+	"github.com/pkg/errors"
+)
+
+const (
+	skippedRemoteMetro     = "remote_metro"
+	skippedUnknownLocation = "unknown_location"
+
+	apiPageSize    = 100
+	apiMaxPages    = 100 // guards against endpoints whose meta always has a next page
+	apiConcurrency = 8   // projects whose ip addresses are fetched at the same time
+
+	locationRetryInterval = time.Minute
+)
+
+// apiMetro is a metro as returned by the api, only its href is set unless it was included.
+type apiMetro struct {
+	ID   string `json:"id"`
+	Code string `json:"code"`
+	Href string `json:"href"`
+}
+
+// id returns the metro's id, taken from its href if it was not included.
+func (m *apiMetro) id() string {
+	if m.ID != "" || m.Href == "" {
+		return m.ID
+	}
+	return m.Href[strings.LastIndex(m.Href, "/")+1:]
+}
+
+type apiFacility struct {
+	Code  string    `json:"code"`
+	Metro *apiMetro `json:"metro"`
+}
+
+// ipReservation is an ip reservation as returned by the api. packngo doesn't know about metros, reservations made
+// in a metro rather than a facility have no facility at all.
+type ipReservation struct {
+	ID       string       `json:"id"`
+	Network  string       `json:"network"`
+	CIDR     int          `json:"cidr"`
+	Public   bool         `json:"public"`
+	Facility *apiFacility `json:"facility"`
+	Metro    *apiMetro    `json:"metro"`
+}
+
+// metro returns the metro of the reservation or of its facility, nil if neither is known.
+func (ip ipReservation) metro() *apiMetro {
+	if ip.Metro != nil {
+		return ip.Metro
+	}
+	if ip.Facility != nil {
+		return ip.Facility.Metro
+	}
+	return nil
+}
+
+// where returns the facility or metro of the reservation for logging, empty if it is unknown.
+func (ip ipReservation) where() string {
+	if ip.Facility != nil && ip.Facility.Code != "" {
+		return ip.Facility.Code
+	}
+	if metro := ip.metro(); metro != nil {
+		if metro.Code != "" {
+			return metro.Code
+		}
+		return metro.id()
+	}
+	return ""
+}
+
+// subnetLocation is where doorman runs, deciding which private subnets it can reach: those in its facility and
+// metro, and those elsewhere of projects with backend transfer enabled.
+type subnetLocation struct {
+	facility string
+	metro    string
+	metroID  string // matches reservations the api only returned the metro href of, empty if unknown
+}
+
+// facilityLocation looks up the metro of the facility with the api.
+func facilityLocation(client *packngo.Client, facility string) (subnetLocation, error) {
+	var response struct {
+		Facilities []apiFacility `json:"facilities"`
+	}
+	if _, err := client.DoRequest("GET", "facilities?include=metro", nil, &response); err != nil {
+		return subnetLocation{}, errors.Wrap(err, "listing facilities")
+	}
+	for _, f := range response.Facilities {
+		if strings.EqualFold(f.Code, facility) && f.Metro != nil && f.Metro.Code != "" {
+			return subnetLocation{facility: facility, metro: strings.ToLower(f.Metro.Code), metroID: f.Metro.id()}, nil
+		}
+	}
+	return subnetLocation{}, errors.Errorf("no metro found for facility %s", facility)
+}
+
+// skippedSubnet is a private subnet that is not pushed, as doorman can't reach it or doesn't know where it is.
+type skippedSubnet struct {
+	cidr     string
+	location string
+	reason   string
+}
+
+func (s skippedSubnet) String() string {
+	if s.location == "" {
+		return fmt.Sprintf("%s: %s", s.cidr, s.reason)
+	}
+	return fmt.Sprintf("%s in %s: %s", s.cidr, s.location, s.reason)
+}
+
+// reachable reports whether the project's private subnet can be reached from the location, and if not why.
+// Subnets of projects with backend transfer are reachable from everywhere, others only if they are known to be
+// in doorman's facility or metro.
+func (l subnetLocation) reachable(project packngo.Project, ip ipReservation) (bool, string) {
+	if project.BackendTransfer {
+		return true, ""
+	}
+	if ip.Facility != nil && ip.Facility.Code != "" && strings.EqualFold(ip.Facility.Code, l.facility) {
+		return true, ""
+	}
+	metro := ip.metro()
+	switch {
+	case metro != nil && metro.Code != "":
+		if l.metro != "" && strings.EqualFold(metro.Code, l.metro) {
+			return true, ""
+		}
+	case metro != nil && metro.id() != "":
+		if l.metroID != "" && metro.id() == l.metroID {
+			return true, ""
+		}
+	default:
+		return false, skippedUnknownLocation
+	}
+	return false, skippedRemoteMetro
+}
+
+// equinixSubnets returns the private ip reservations of the Equinix Metal projects the user's api token can see,
+// or of the identity's projects if it lists them, that can be reached from doorman's location.
+// Users without an api token have none.
+// The metro of doorman's facility is looked up with the first user's token, unless DOORMAN_METRO sets it.
+type equinixSubnets struct {
+	consumerToken string
+
+	mu       sync.Mutex
+	location subnetLocation
+	located  bool      // the facility's metro was looked up
+	tried    time.Time // when looking it up last failed
+}
+
+// lookupLocation returns doorman's location, looking up the metro of its facility if it wasn't yet. Until that
+// succeeds, only subnets in the facility or of DOORMAN_METRO are known to be reachable.
+func (p *equinixSubnets) lookupLocation(client *packngo.Client) subnetLocation {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.located || time.Since(p.tried) < locationRetryInterval {
+		return p.location
+	}
+	found, err := facilityLocation(client, p.location.facility)
+	if err != nil {
+		p.tried = time.Now()
+		logger.With("facility", p.location.facility, "metro", p.location.metro).Error(errors.WithMessage(err, "looking up metro"))
+		metrics.ErrorTotal.WithLabelValues("doorman", "errors").Inc()
+		return p.location
+	}
+	if p.location.metro == "" {
+		p.location.metro = found.metro
+	}
+	if p.location.metro == found.metro {
+		p.location.metroID = found.metroID
+	}
+	p.located = true
+	logger.With("facility", p.location.facility, "metro", p.location.metro).Info("looked up metro")
+	return p.location
+}
+
+func (p *equinixSubnets) Subnets(ctx context.Context, id *identity) ([]*net.IPNet, error) {
+	if id.token == "" {
+		return nil, nil
+	}
+	newClient := func() *packngo.Client {
+		return packngo.NewClientWithAuth(p.consumerToken, id.token, nil)
+	}
+	location := p.lookupLocation(newClient())
+	ips, skipped, failed, err := getSubnets(ctx, newClient, location, id.projects)
+	if err != nil {
+		return nil, err
+	}
+	if len(failed) != 0 {
+		metrics.SubnetProjectFailuresTotal.Add(float64(len(failed)))
+		logger.With("user", id.user, "projects", failed).Info("failed fetching subnets of projects, leaving them out of the routes")
+	}
+	if len(skipped) != 0 {
+		reasons := make([]string, 0, len(skipped))
+		for _, subnet := range skipped {
+			reasons = append(reasons, subnet.String())
+			metrics.SubnetsSkippedTotal.WithLabelValues(subnet.reason).Inc()
+		}
+		logger.With("user", id.user, "facility", location.facility, "metro", location.metro, "skipped", reasons).
+			Info("skipped subnets outside the metro or of unknown location of projects without backend transfer")
+	}
+
+	subnets := make([]*net.IPNet, 0, len(ips))
+	for _, ip := range ips {
+		cidr := fmt.Sprintf("%s/%d", ip.Network, ip.CIDR)
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing route %s", cidr)
+		}
+		subnets = append(subnets, network)
+	}
+	return subnets, nil
+}
+
+// apiMeta is the pagination of a list the api returned, it is missing if the endpoint doesn't paginate.
+type apiMeta struct {
+	Next     *packngo.Href `json:"next"`
+	LastPage int           `json:"last_page"`
+}
+
+// more reports whether there are pages after page.
+func (m *apiMeta) more(page int) bool {
+	if m == nil {
+		return false
+	}
+	return (m.Next != nil && m.Next.Href != "") || m.LastPage > page
+}
+
+// fetchPages calls fetch with successive pages for as long as the meta it returns has more, checking ctx before
+// every page.
+func fetchPages(ctx context.Context, fetch func(opts *packngo.ListOptions) (*apiMeta, error)) error {
+	for page := 1; page <= apiMaxPages; page++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		meta, err := fetch(&packngo.ListOptions{Page: page, PerPage: apiPageSize})
+		if err != nil {
+			return err
+		}
+		if !meta.more(page) {
+			return nil
+		}
+	}
+	return errors.Errorf("more than %d pages", apiMaxPages)
+}
+
+// fetchProjects returns the projects with the ids, or all projects the client can see if there are none, and the
+// ids of those that could not be fetched.
+func fetchProjects(ctx context.Context, client *packngo.Client, ids []string) ([]packngo.Project, []string, error) {
+	if len(ids) != 0 {
+		projects := make([]packngo.Project, 0, len(ids))
+		var failed []string
+		for _, id := range ids {
+			if err := ctx.Err(); err != nil {
+				return nil, nil, err
+			}
+			project, _, err := client.Projects.Get(id, nil)
+			if err != nil {
+				logger.With("error", errors.Wrapf(err, "fetching project=%s", id)).Info()
+				failed = append(failed, id)
+				continue
+			}
+			projects = append(projects, *project)
+		}
+		if len(projects) == 0 {
+			return nil, nil, errors.Errorf("fetching all %d projects failed", len(ids))
+		}
+		return projects, failed, nil
+	}
+
+	var projects []packngo.Project
+	seen := map[string]bool{}
+	err := fetchPages(ctx, func(opts *packngo.ListOptions) (*apiMeta, error) {
+		var page struct {
+			Projects []packngo.Project `json:"projects"`
+			Meta     *apiMeta          `json:"meta"`
+		}
+		path := fmt.Sprintf("projects?page=%d&per_page=%d", opts.Page, opts.PerPage)
+		if _, err := client.DoRequest("GET", path, nil, &page); err != nil {
+			return nil, err
+		}
+		for _, project := range page.Projects {
+			if !seen[project.ID] {
+				seen[project.ID] = true
+				projects = append(projects, project)
+			}
+		}
+		return page.Meta, nil
+	})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "listing projects")
+	}
+	if len(projects) == 0 {
+		return nil, nil, errors.New("no projects found")
+	}
+	return projects, nil, nil
+}
+
+// fetchIPs returns the project's private subnets reachable from the location, and those that are not.
+func fetchIPs(ctx context.Context, client *packngo.Client, location subnetLocation, project packngo.Project) ([]ipReservation, []skippedSubnet, error) {
+	var ips []ipReservation
+	var skipped []skippedSubnet
+	seen := map[string]bool{}
+	err := fetchPages(ctx, func(opts *packngo.ListOptions) (*apiMeta, error) {
+		var page struct {
+			IPs  []ipReservation `json:"ip_addresses"`
+			Meta *apiMeta        `json:"meta"`
+		}
+		path := fmt.Sprintf("projects/%s/ips?include=metro,facility.metro&page=%d&per_page=%d", project.ID, opts.Page, opts.PerPage)
+		if _, err := client.DoRequest("GET", path, nil, &page); err != nil {
+			return nil, err
+		}
+		for _, ip := range page.IPs {
+			cidr := fmt.Sprintf("%s/%d", ip.Network, ip.CIDR)
+			key := ip.ID
+			if key == "" {
+				key = cidr
+			}
+			if seen[key] || ip.Public {
+				continue
+			}
+			seen[key] = true
+			if ok, reason := location.reachable(project, ip); !ok {
+				skipped = append(skipped, skippedSubnet{cidr: cidr, location: ip.where(), reason: reason})
+				continue
+			}
+			ips = append(ips, ip)
+		}
+		return page.Meta, nil
+	})
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "fetching ip addresses of project=%s", project.ID)
+	}
+	return ips, skipped, nil
+}
+
+// getSubnets fetches the ip reservations of the projects, at most apiConcurrency at a time. Projects that fail are
+// left out and their ids returned, it only fails if listing the projects does, no project could be fetched or ctx
+// is done. Every worker gets its own client from newClient, as packngo clients record the rate limit of each
+// response and can't be shared.
+func getSubnets(ctx context.Context, newClient func() *packngo.Client, location subnetLocation, projectIDs []string) ([]ipReservation, []skippedSubnet, []string, error) {
+	projects, failed, err := fetchProjects(ctx, newClient(), projectIDs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var ips []ipReservation
+	var skipped []skippedSubnet
+	fetched := 0
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	// a worker takes a client before it starts and returns it when done
+	clients := make(chan *packngo.Client, apiConcurrency)
+	for i := 0; i < apiConcurrency; i++ {
+		clients <- newClient()
+	}
+
+fetch:
+	for _, project := range projects {
+		var client *packngo.Client
+		select {
+		case client = <-clients:
+		case <-ctx.Done():
+			break fetch
+		}
+		wg.Add(1)
+		go func(project packngo.Project, client *packngo.Client) {
+			defer func() {
+				clients <- client
+				wg.Done()
+			}()
+
+			pIPs, pSkipped, err := fetchIPs(ctx, client, location, project)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				logger.With("error", err).Info()
+				failed = append(failed, project.ID)
+				return
+			}
+			fetched++
+			ips = append(ips, pIPs...)
+			skipped = append(skipped, pSkipped...)
+		}(project, client)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, nil, err
+	}
+	if fetched == 0 {
+		return nil, nil, nil, errors.Errorf("fetching ip addresses of all %d projects failed", len(projects))
+	}
+	sort.Strings(failed)
+	return ips, skipped, failed, nil
+}
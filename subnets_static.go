@@ -0,0 +1,66 @@
+package doorman
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// staticSubnetsAll lists the subnets every user may reach in a static subnets file.
+const staticSubnetsAll = "*"
+
+// staticSubnets maps users to the subnets they may reach, read from a YAML or JSON file, for labs and on-prem
+// environments, or to add subnets the Equinix Metal API doesn't know about. Users are matched by their id qualified
+// with the backend they logged in with, e.g. "equinix:<uuid>" or "ldap:alice", case insensitively. Only users of a
+// backend get static subnets, not service accounts or break glass credentials, which are limited to what they were
+// created with.
+type staticSubnets struct {
+	users map[string][]*net.IPNet
+}
+
+// readStaticSubnets reads a file mapping users to CIDRs, e.g. `{"alice@example.com": ["10.0.0.0/8"], "*": []}`.
+func readStaticSubnets(file string) (*staticSubnets, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "read static subnets")
+	}
+
+	// json is yaml too
+	var users map[string][]string
+	if err := yaml.Unmarshal(data, &users); err != nil {
+		return nil, errors.Wrapf(err, "parsing %s", file)
+	}
+	return parseStaticSubnets(users)
+}
+
+func parseStaticSubnets(users map[string][]string) (*staticSubnets, error) {
+	if len(users) == 0 {
+		return nil, errors.New("no users mapped to subnets")
+	}
+	s := &staticSubnets{users: make(map[string][]*net.IPNet, len(users))}
+	for user, routes := range users {
+		if i := strings.Index(user, ":"); user != staticSubnetsAll && (i < 0 || !isAuthBackend(user[:i]) || i == len(user)-1) {
+			return nil, errors.Errorf("user %s needs to be %q or qualified with its backend, e.g. %s:<id>", user, staticSubnetsAll, authEquinix)
+		}
+		subnets, err := parseRoutes(routes)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "user %s", user)
+		}
+		key := strings.ToLower(user)
+		s.users[key] = append(s.users[key], subnets...)
+	}
+	return s, nil
+}
+
+func (s *staticSubnets) Subnets(ctx context.Context, id *identity) ([]*net.IPNet, error) {
+	user := id.qualifiedID()
+	if user == "" {
+		return nil, nil
+	}
+	subnets := append([]*net.IPNet{}, s.users[staticSubnetsAll]...)
+	return append(subnets, s.users[user]...), nil
+}
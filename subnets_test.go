@@ -0,0 +1,272 @@
+package doorman
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/packethost/packngo"
+	"github.com/packethost/pkg/log"
+	"github.com/pkg/errors"
+)
+
+// fixedSubnets provides the same subnets to everyone.
+type fixedSubnets []string
+
+func (f fixedSubnets) Subnets(ctx context.Context, id *identity) ([]*net.IPNet, error) {
+	return parseRoutes(f)
+}
+
+func subnetStrings(subnets []*net.IPNet) []string {
+	routes := make([]string, 0, len(subnets))
+	for _, subnet := range subnets {
+		routes = append(routes, subnet.String())
+	}
+	return routes
+}
+
+func TestParseRoutes(t *testing.T) {
+	subnets, err := parseRoutes([]string{"10.88.111.1/25", "fd00:8a0:1::/56"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if routes := subnetStrings(subnets); !reflect.DeepEqual(routes, []string{"10.88.111.0/25", "fd00:8a0:1::/56"}) {
+		t.Fatalf("unexpected subnets: %v", routes)
+	}
+	if _, err := parseRoutes([]string{"10.88.111.0"}); err == nil {
+		t.Fatal("expected an error for a route without a prefix length")
+	}
+}
+
+func TestStaticSubnets(t *testing.T) {
+	dir := t.TempDir()
+
+	yaml := filepath.Join(dir, "subnets.yaml")
+	err := ioutil.WriteFile(yaml, []byte(`"*":
+  - 10.0.0.0/24
+ldap:Alice:
+  - 10.88.111.0/25
+equinix:1A2B3C:
+  - fd00:8a0:1::/56
+`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	json := filepath.Join(dir, "subnets.json")
+	if err := ioutil.WriteFile(json, []byte(`{"local:bob": ["10.0.1.0/24"]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	static, err := readStaticSubnets(yaml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromJSON, err := readStaticSubnets(json)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider := compositeSubnets{identitySubnets{}, static, fromJSON, fixedSubnets{"10.0.0.0/24"}}
+	for _, test := range []struct {
+		id     *identity
+		routes []string
+	}{
+		{&identity{user: "alice@example.com", id: "1a2b3c", backend: authEquinix}, []string{"10.0.0.0/24", "fd00:8a0:1::/56"}},
+		{&identity{user: "alice", backend: authLDAP}, []string{"10.0.0.0/24", "10.88.111.0/25"}},
+		{&identity{user: "bob", backend: authLocal, routes: []string{"192.168.0.0/16"}}, []string{"192.168.0.0/16", "10.0.0.0/24", "10.0.1.0/24"}},
+		// users of other backends with the same name don't get the user's subnets
+		{&identity{user: "alice", backend: authLocal}, []string{"10.0.0.0/24"}},
+		{&identity{user: "1a2b3c", backend: authOIDC}, []string{"10.0.0.0/24"}},
+	} {
+		subnets, err := provider.Subnets(context.Background(), test.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if routes := subnetStrings(subnets); !reflect.DeepEqual(routes, test.routes) {
+			t.Fatalf("%s: expected %v, got %v", test.id.qualifiedID(), test.routes, routes)
+		}
+	}
+
+	// service accounts, break glass credentials and sessions don't get static subnets, not even those of everyone
+	for _, id := range []*identity{
+		{user: "ldap:alice", projects: []string{"p1"}},
+		{user: "break-glass:0123456789abcdef", routes: []string{"10.88.112.0/25"}},
+		{user: "alice", routes: []string{"10.88.111.0/25"}},
+	} {
+		if subnets, err := static.Subnets(context.Background(), id); err != nil || len(subnets) != 0 {
+			t.Fatalf("%s: expected no static subnets, got %v, %v", id.user, subnetStrings(subnets), err)
+		}
+	}
+
+	if _, err := (compositeSubnets{identitySubnets{}, fromJSON}).Subnets(context.Background(), &identity{user: "carol", backend: authLocal}); err != errNoSubnets {
+		t.Fatalf("expected no subnets, got %v", err)
+	}
+	for _, user := range []string{"alice", "nope:alice", "ldap:"} {
+		if _, err := parseStaticSubnets(map[string][]string{user: {"10.88.111.0/25"}}); err == nil {
+			t.Fatalf("expected unqualified user %q to fail", user)
+		}
+	}
+	if _, err := parseStaticSubnets(map[string][]string{"ldap:alice": {"10.88.111.0/33"}}); err == nil {
+		t.Fatal("expected an invalid route to fail")
+	}
+}
+
+func TestEquinixSubnets(t *testing.T) {
+	logger = log.Test(t, "doorman")
+
+	// p1 has no backend transfer, p2 has. sv15 and sjc1 are in sv, which the api only returns the href of for 10.1.4.0
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/facilities":
+			w.Write([]byte(`{"facilities":[
+				{"code":"da11","metro":{"id":"m-da","code":"da"}},
+				{"code":"sv15","metro":{"id":"m-sv","code":"sv"}}
+			]}`))
+		case "/projects":
+			w.Write([]byte(`{"projects":[{"id":"p1"},{"id":"p2","backend_transfer_enabled":true}]}`))
+		case "/projects/p1/ips":
+			w.Write([]byte(`{"ip_addresses":[
+				{"network":"10.1.0.0","cidr":25,"public":false,"facility":{"code":"sv15"}},
+				{"network":"10.1.1.0","cidr":25,"public":false,"facility":{"code":"sjc1","metro":{"code":"sv"}}},
+				{"network":"10.1.2.0","cidr":25,"public":false,"facility":{"code":"da11","metro":{"code":"da"}}},
+				{"network":"10.1.3.0","cidr":25,"public":false,"metro":{"code":"sv"}},
+				{"network":"10.1.4.0","cidr":25,"public":false,"metro":{"href":"/metal/v1/locations/metros/m-sv"}},
+				{"network":"10.1.5.0","cidr":25,"public":false,"metro":{"href":"/metal/v1/locations/metros/m-da"}},
+				{"network":"10.1.6.0","cidr":25,"public":false},
+				{"network":"10.1.7.0","cidr":25,"public":false,"facility":{"code":"sjc1"}},
+				{"network":"147.75.0.0","cidr":31,"public":true,"facility":{"code":"sv15"}}
+			]}`))
+		case "/projects/p2/ips":
+			w.Write([]byte(`{"ip_addresses":[
+				{"network":"10.2.0.0","cidr":25,"public":false,"facility":{"code":"ny5"}},
+				{"network":"10.2.1.0","cidr":25,"public":false}
+			]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":["Not found"]}`))
+		}
+	}))
+	defer api.Close()
+
+	newClient := func() *packngo.Client {
+		client, err := packngo.NewClientWithBaseURL("", "token", nil, api.URL+"/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client
+	}
+	location, err := facilityLocation(newClient(), "SV15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (subnetLocation{facility: "SV15", metro: "sv", metroID: "m-sv"}); location != want {
+		t.Fatalf("expected location %+v, got %+v", want, location)
+	}
+	if _, err := facilityLocation(newClient(), "ny5"); err == nil {
+		t.Fatal("expected a facility without metro to fail")
+	}
+
+	ips, skipped, failed, err := getSubnets(context.Background(), newClient, subnetLocation{facility: "sv15", metro: "sv", metroID: "m-sv"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failed) != 0 {
+		t.Fatalf("expected no failed projects, got %v", failed)
+	}
+	var routes []string
+	for _, ip := range ips {
+		routes = append(routes, fmt.Sprintf("%s/%d", ip.Network, ip.CIDR))
+	}
+	sort.Strings(routes)
+	if want := []string{"10.1.0.0/25", "10.1.1.0/25", "10.1.3.0/25", "10.1.4.0/25", "10.2.0.0/25", "10.2.1.0/25"}; !reflect.DeepEqual(routes, want) {
+		t.Fatalf("expected %v, got %v", want, routes)
+	}
+	want := []skippedSubnet{
+		{cidr: "10.1.2.0/25", location: "da11", reason: skippedRemoteMetro},
+		{cidr: "10.1.5.0/25", location: "m-da", reason: skippedRemoteMetro},
+		{cidr: "10.1.6.0/25", reason: skippedUnknownLocation},
+		{cidr: "10.1.7.0/25", location: "sjc1", reason: skippedUnknownLocation},
+	}
+	if !reflect.DeepEqual(skipped, want) {
+		t.Fatalf("expected %v skipped, got %v", want, skipped)
+	}
+}
+
+func TestEquinixSubnetsPagination(t *testing.T) {
+	logger = log.Test(t, "doorman")
+
+	// projects span two pages, p3's ips fail and the ips endpoint doesn't paginate
+	var ipRequests int32
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/projects" && r.URL.Query().Get("page") == "1":
+			var projects []string
+			for i := 0; i < apiPageSize; i++ {
+				projects = append(projects, fmt.Sprintf(`{"id":"p%d"}`, i))
+			}
+			fmt.Fprintf(w, `{"projects":[%s],"meta":{"next":{"href":"/projects?page=2"},"current_page":1,"last_page":2}}`, strings.Join(projects, ","))
+		case r.URL.Path == "/projects" && r.URL.Query().Get("page") == "2":
+			// a short page doesn't end the list, the meta does
+			fmt.Fprintf(w, `{"projects":[{"id":"p%d"}],"meta":{"next":{"href":"/projects?page=3"},"current_page":2,"last_page":3}}`, apiPageSize)
+		case r.URL.Path == "/projects" && r.URL.Query().Get("page") == "3":
+			fmt.Fprintf(w, `{"projects":[{"id":"p%d"}],"meta":{"current_page":3,"last_page":3}}`, apiPageSize+1)
+		case r.URL.Path == "/projects/p3/ips":
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"errors":["boom"]}`))
+		case strings.HasPrefix(r.URL.Path, "/projects/") && strings.HasSuffix(r.URL.Path, "/ips"):
+			atomic.AddInt32(&ipRequests, 1)
+			var ips []string
+			for i := 0; i < apiPageSize; i++ {
+				ips = append(ips, fmt.Sprintf(`{"id":"%s-%d","network":"10.%d.%d.0","cidr":24,"public":false,"facility":{"code":"sv15"}}`, r.URL.Path, i, len(r.URL.Path), i))
+			}
+			fmt.Fprintf(w, `{"ip_addresses":[%s]}`, strings.Join(ips, ","))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":["Not found"]}`))
+		}
+	}))
+	defer api.Close()
+
+	newClient := func() *packngo.Client {
+		client, err := packngo.NewClientWithBaseURL("", "token", nil, api.URL+"/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client
+	}
+	ips, _, failed, err := getSubnets(context.Background(), newClient, subnetLocation{facility: "sv15", metro: "sv"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"p3"}; !reflect.DeepEqual(failed, want) {
+		t.Fatalf("expected %v to fail, got %v", want, failed)
+	}
+	if want := (apiPageSize + 1) * apiPageSize; len(ips) != want {
+		t.Fatalf("expected %d ips, got %d", want, len(ips))
+	}
+	// without meta the first page of ips is the only one
+	if want := int32(apiPageSize + 1); atomic.LoadInt32(&ipRequests) != want {
+		t.Fatalf("expected %d ip requests, got %d", want, ipRequests)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	atomic.StoreInt32(&ipRequests, 0)
+	if _, _, _, err := getSubnets(ctx, newClient, subnetLocation{facility: "sv15", metro: "sv"}, nil); errors.Cause(err) != context.Canceled {
+		t.Fatalf("expected the fetch to be canceled, got %v", err)
+	}
+	if n := atomic.LoadInt32(&ipRequests); n != 0 {
+		t.Fatalf("expected no ip requests once canceled, got %d", n)
+	}
+}
@@ -0,0 +1,62 @@
+package doorman
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 appendix B, SHA1, truncated to 6 digits
+	secret := []byte("12345678901234567890")
+	tests := []struct {
+		time int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, test := range tests {
+		if code := totpCode(secret, test.time/totpPeriod); code != test.code {
+			t.Fatalf("%d: expected %s, got %s", test.time, test.code, code)
+		}
+	}
+}
+
+func TestTOTPVerify(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	now := time.Unix(1111111111, 0)
+	step := now.Unix() / totpPeriod
+
+	for _, s := range []int64{step - 1, step, step + 1} {
+		got, ok := totpVerify(secret, totpCode(secret, s), now)
+		if !ok || got != s {
+			t.Fatalf("expected code of step %d to be valid, got %d %v", s, got, ok)
+		}
+	}
+	for _, s := range []int64{step - 2, step + 2} {
+		if _, ok := totpVerify(secret, totpCode(secret, s), now); ok {
+			t.Fatalf("expected code of step %d to be invalid", s)
+		}
+	}
+	if _, ok := totpVerify(secret, "", now); ok {
+		t.Fatal("expected an empty code to be invalid")
+	}
+}
+
+func TestTOTPURL(t *testing.T) {
+	u, err := url.Parse(totpURL("doorman", "alice", []byte("12345678901234567890")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/doorman:alice" {
+		t.Fatalf("unexpected url %s", u)
+	}
+	if secret := u.Query().Get("secret"); secret != "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ" {
+		t.Fatalf("unexpected secret %s", secret)
+	}
+}
@@ -2,9 +2,14 @@ package doorman
 
 import (
 	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"io"
 	"io/ioutil"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -15,24 +20,44 @@ import (
 var (
 	extractCertRe = regexp.MustCompile(`(?ms)^(.*)(-----BEGIN CERTIFICATE-----)(.*)$`)
 	twofactorRe   = regexp.MustCompile(`^[0-9]{6}$`)
+	challengeRe   = regexp.MustCompile(`^[0-9]{6,8}$`)
+
+	// errInvalidTwoFactor doesn't echo the token, it may be part of the password or carry anything the client sent
+	errInvalidTwoFactor = errors.New("invalid twofactor token")
+)
+
+const (
+	// staticChallenge is the prompt of the static-challenge directive in generated client configs
+	staticChallenge = "Enter your authenticator code"
+	// staticChallengePrefix marks a password that carries the response to the static challenge
+	staticChallengePrefix = "SCRV1:"
 )
 
 func ParseOpenVPNFile(filename string) (string, string, string, error) {
+	username, password, err := ReadOpenVPNFile(filename)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	return ParseOpenVPNCredentials(username, password)
+}
+
+// ReadOpenVPNFile reads the username and password from an auth-user-pass-verify via-file credentials file.
+func ReadOpenVPNFile(filename string) (string, string, error) {
 	var username string
 	var password string
-	var twofactor string
 
 	if filename == "" {
-		return "", "", "", errors.New("no filename supplied")
+		return "", "", errors.New("no filename supplied")
 	}
 
 	if _, err := os.Stat(filename); os.IsNotExist(err) {
-		return "", "", "", errors.New("file does not exist")
+		return "", "", errors.New("file does not exist")
 	}
 
 	inFile, err := os.Open(filename)
 	if err != nil {
-		return "", "", "", errors.Wrap(err, "open openvpn file")
+		return "", "", errors.Wrap(err, "open openvpn file")
 	}
 	defer inFile.Close()
 
@@ -45,13 +70,47 @@ func ParseOpenVPNFile(filename string) (string, string, string, error) {
 		} else if password == "" {
 			password = scanner.Text()
 		} else {
-			return "", "", "", errors.New("invalid formatted file, expecting only 2 lines")
+			return "", "", errors.New("invalid formatted file, expecting only 2 lines")
 		}
 	}
 
+	return username, password, nil
+}
+
+// ParseOpenVPNCredentials splits the twofactor token off the password.
+// Clients answering a static-challenge send the password and token as SCRV1:<base64 password>:<base64 token>,
+// older profiles prepend the 6 digit token to the password.
+func ParseOpenVPNCredentials(username, password string) (string, string, string, error) {
+	var twofactor string
+
 	if username == "" {
 		return "", "", "", errors.New("empty username")
 	}
+
+	if strings.HasPrefix(password, staticChallengePrefix) {
+		parts := strings.Split(strings.TrimPrefix(password, staticChallengePrefix), ":")
+		if len(parts) != 2 {
+			return "", "", "", errors.New("invalid static challenge response, expecting SCRV1:<password>:<response>")
+		}
+		decoded, err := base64.StdEncoding.DecodeString(parts[0])
+		if err != nil {
+			return "", "", "", errors.Wrap(err, "decode static challenge password")
+		}
+		response, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			return "", "", "", errors.Wrap(err, "decode static challenge response")
+		}
+
+		password, twofactor = string(decoded), strings.TrimSpace(string(response))
+		if password == "" {
+			return "", "", "", errors.New("invalid password")
+		}
+		if !challengeRe.MatchString(twofactor) {
+			return "", "", "", errInvalidTwoFactor
+		}
+		return username, password, twofactor, nil
+	}
+
 	if password == "" || len(password) <= 6 {
 		return "", "", "", errors.New("invalid password")
 	}
@@ -60,12 +119,94 @@ func ParseOpenVPNFile(filename string) (string, string, string, error) {
 	password = password[6:]
 
 	if matched := twofactorRe.Match([]byte(twofactor)); !matched {
-		return "", "", "", errors.Errorf("invalid twofactor token: %s", twofactor)
+		return "", "", "", errInvalidTwoFactor
 	}
 
 	return username, password, twofactor, nil
 }
 
+// ParseOpenVPNStatus parses an OpenVPN status-version 2 file into connections keyed by common name.
+// Columns are looked up by the names given in the HEADER lines since they differ between OpenVPN versions.
+func ParseOpenVPNStatus(r io.Reader) (map[string]*pb.Connection, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	headers := map[string]map[string]int{}
+	connections := map[string]*pb.Connection{}
+	byRealAddress := map[string]*pb.Connection{}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "read openvpn status")
+		}
+
+		field := func(name string) string {
+			idx, ok := headers[record[0]][name]
+			if !ok || idx >= len(record) {
+				return ""
+			}
+			return record[idx]
+		}
+		number := func(name string) int64 {
+			n, _ := strconv.ParseInt(field(name), 10, 64)
+			return n
+		}
+
+		switch record[0] {
+		case "HEADER":
+			if len(record) < 2 {
+				continue
+			}
+			columns := map[string]int{}
+			// the columns are shifted by one since data lines start with the type instead of HEADER,TYPE
+			for i, name := range record[2:] {
+				columns[name] = i + 1
+			}
+			headers[record[1]] = columns
+		case "CLIENT_LIST":
+			connection := &pb.Connection{
+				Client:        field("Common Name"),
+				Username:      field("Username"),
+				Allocation:    &pb.Allocation{Client: field("Common Name"), Ip: field("Virtual Address")},
+				Since:         number("Connected Since (time_t)"),
+				RealAddress:   field("Real Address"),
+				BytesReceived: number("Bytes Received"),
+				BytesSent:     number("Bytes Sent"),
+			}
+			connections[connection.Client] = connection
+			byRealAddress[connection.RealAddress] = connection
+		case "ROUTING_TABLE":
+			// routing entries are matched by real address, the same client can have several routes (iroutes)
+			connection, ok := byRealAddress[field("Real Address")]
+			if !ok {
+				continue
+			}
+			if lastRef := number("Last Ref (time_t)"); lastRef > connection.LastRef {
+				connection.LastRef = lastRef
+			}
+		case "END":
+			return connections, nil
+		}
+	}
+
+	return connections, nil
+}
+
+func ParseOpenVPNStatusFile(filename string) (map[string]*pb.Connection, error) {
+	statusFile, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "open openvpn status file")
+	}
+	defer statusFile.Close()
+
+	return ParseOpenVPNStatus(statusFile)
+}
+
 func ExtractCertificate(file string) string {
 	// logs in here because caller doesn't check return
 	cert, err := ioutil.ReadFile(file)
@@ -158,6 +299,31 @@ func ClientsFromOpenSSLIndexFile(file string) []*pb.Client {
 	return clients
 }
 
+// runWithin runs run, returning ctx's error if it does not return within timeout.
+// late is called if run succeeds after that.
+func runWithin(ctx context.Context, timeout time.Duration, run func(context.Context) error, late func()) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- run(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		cancel()
+		return err
+	case <-ctx.Done():
+		go func() {
+			defer cancel()
+			if err := <-done; err == nil {
+				late()
+			}
+		}()
+		return ctx.Err()
+	}
+}
+
 // This can likely be modified at a later time to just check to see is an environment variable has any value.
 func isTestingEnvironment() bool {
 	return os.Getenv(doormanEnvironment) == "testing"
@@ -1,14 +1,31 @@
 package doorman
 
 import (
+	"context"
+	"encoding/base64"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"testing"
+	"time"
 
 	pb "github.com/equinix/doorman/protobuf"
+	"github.com/pkg/errors"
 )
 
+// newTestStateStore opens a state store in a temporary directory, closed when the test ends.
+func newTestStateStore(t *testing.T) *stateStore {
+	t.Helper()
+	store, err := openStateStore(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
 var openvpnStatusFile = `TITLE,OpenVPN 2.3.11 x86_64-redhat-linux-gnu [SSL (OpenSSL)] [LZO] [EPOLL] [PKCS11] [MH] [IPv6] built on May 10 2016
 TIME,Wed Jun 29 11:52:04 2016,1467215524
 HEADER,CLIENT_LIST,Common Name,Real Address,Virtual Address,Bytes Received,Bytes Sent,Connected Since,Connected Since (time_t),Username
@@ -78,6 +95,51 @@ func TestParseOpenSSLIndexFile(t *testing.T) {
 	}
 }
 
+func TestParseOpenVPNStatus(t *testing.T) {
+	connections, err := ParseOpenVPNStatus(strings.NewReader(openvpnStatusFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(connections) != 2 {
+		t.Fatalf("expecting connections to be 2, got: %d", len(connections))
+	}
+
+	client1 := connections["client1"]
+	if client1 == nil {
+		t.Fatal("expecting client1 to be connected")
+	}
+	if client1.RealAddress != "24.255.233.90:60710" {
+		t.Fatalf("unexpected real address: %s", client1.RealAddress)
+	}
+	if client1.Allocation.Ip != "192.168.127.2" {
+		t.Fatalf("unexpected virtual address: %s", client1.Allocation.Ip)
+	}
+	if client1.BytesReceived != 4497 || client1.BytesSent != 4990 {
+		t.Fatalf("unexpected traffic counters, received: %d, sent: %d", client1.BytesReceived, client1.BytesSent)
+	}
+	if client1.Since != 1467215404 {
+		t.Fatalf("unexpected connected since: %d", client1.Since)
+	}
+	if client1.LastRef != 1467215432 {
+		t.Fatalf("unexpected last ref: %d", client1.LastRef)
+	}
+	if client1.Username != "nathan.goulding@gmail.com" {
+		t.Fatalf("unexpected username: %s", client1.Username)
+	}
+
+	client2 := connections["client2"]
+	if client2 == nil {
+		t.Fatal("expecting client2 to be connected")
+	}
+	if client2.BytesReceived != 9744 || client2.BytesSent != 9049 {
+		t.Fatalf("unexpected traffic counters, received: %d, sent: %d", client2.BytesReceived, client2.BytesSent)
+	}
+	if client2.LastRef != 0 {
+		t.Fatalf("expecting no last ref without a routing table entry, got: %d", client2.LastRef)
+	}
+}
+
 func TestIsTestingEnvironment(t *testing.T) {
 	// Store environment variable value
 	existingEnvironmentValue := os.Getenv(doormanEnvironment)
@@ -107,3 +169,90 @@ func TestIsTestingEnvironment(t *testing.T) {
 	// Clean up, set the environment variable back to its previous value.
 	os.Setenv(doormanEnvironment, existingEnvironmentValue)
 }
+
+func TestParseOpenVPNCredentials(t *testing.T) {
+	scrv1 := func(password, response string) string {
+		return "SCRV1:" + base64.StdEncoding.EncodeToString([]byte(password)) + ":" + base64.StdEncoding.EncodeToString([]byte(response))
+	}
+
+	tests := []struct {
+		password             string
+		wantPassword, wantTF string
+		wantErr              bool
+	}{
+		{password: "123456pass=word", wantPassword: "pass=word", wantTF: "123456"},
+		{password: "123456", wantErr: true},
+		{password: "12345xpassword", wantErr: true},
+		{password: scrv1("pass:word", "123456"), wantPassword: "pass:word", wantTF: "123456"},
+		{password: scrv1("short", "12345678"), wantPassword: "short", wantTF: "12345678"},
+		{password: scrv1("password", "1234"), wantErr: true},
+		{password: scrv1("", "123456"), wantErr: true},
+		{password: "SCRV1:cGFzc3dvcmQ=", wantErr: true},
+		{password: "SCRV1:!!!:MTIzNDU2", wantErr: true},
+		{password: scrv1("password", "123456\nkill other"), wantErr: true},
+	}
+	for _, tc := range tests {
+		_, password, twofactor, err := ParseOpenVPNCredentials("user", tc.password)
+		if tc.wantErr {
+			if err == nil {
+				t.Fatalf("%s: expected an error", tc.password)
+			}
+			if strings.Contains(err.Error(), "12345x") || strings.Contains(err.Error(), "kill") {
+				t.Fatalf("%s: expected the error not to echo the credentials, got %v", tc.password, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tc.password, err)
+		}
+		if password != tc.wantPassword || twofactor != tc.wantTF {
+			t.Fatalf("%s: expected %q and %q, got %q and %q", tc.password, tc.wantPassword, tc.wantTF, password, twofactor)
+		}
+	}
+}
+
+func TestRunWithin(t *testing.T) {
+	late := make(chan struct{}, 1)
+	onLate := func() { late <- struct{}{} }
+
+	err := runWithin(context.Background(), time.Second, func(ctx context.Context) error { return nil }, onLate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failed := errors.New("failed")
+	err = runWithin(context.Background(), time.Second, func(ctx context.Context) error { return failed }, onLate)
+	if err != failed {
+		t.Fatalf("expected %v, got %v", failed, err)
+	}
+
+	// a run that ignores the timeout and fails afterwards is just left behind
+	release := make(chan struct{})
+	err = runWithin(context.Background(), 10*time.Millisecond, func(ctx context.Context) error {
+		<-release
+		return failed
+	}, onLate)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("expected the deadline to be exceeded, got %v", err)
+	}
+	close(release)
+
+	// a run that succeeds after the timeout needs to be undone
+	err = runWithin(context.Background(), 10*time.Millisecond, func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}, onLate)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("expected the deadline to be exceeded, got %v", err)
+	}
+	select {
+	case <-late:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected late to be called")
+	}
+	select {
+	case <-late:
+		t.Fatal("expected late to be called once")
+	case <-time.After(50 * time.Millisecond):
+	}
+}